@@ -1,10 +1,14 @@
 package db
 
 import (
+	"errors"
 	"log"
 	"os"
+	"time"
 
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	rankUtils "github.com/EmilyOng/tusk-manager/backend/utils/rank"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -19,18 +23,160 @@ func Setup() (err error) {
 		return
 	}
 
+	// Accounts that predate email verification are checked once the column is added
+	verifiesEmails := DB.Migrator().HasTable(&models.User{}) && !DB.Migrator().HasColumn(&models.User{}, "EmailVerified")
+
 	err = DB.AutoMigrate(
 		&models.User{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
 		&models.Board{},
 		&models.Task{},
+		&models.TaskAssignee{},
 		&models.Tag{},
 		&models.State{},
+		&models.CustomRole{},
 		&models.Member{},
+		&models.Team{},
+		&models.TeamMember{},
+		&models.BoardTeam{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.UserToken{},
+		&models.LoginAttempt{},
+		&models.PersonalAccessToken{},
+		&models.UserIdentity{},
+		&models.OIDCAuthRequest{},
+		&models.RecoveryCode{},
+		&models.AuditEvent{},
+		&models.Invitation{},
+		&models.InviteLink{},
+		&models.BoardPublication{},
 	)
 	if err != nil {
 		log.Fatalln("Unable to migrate database")
 		return
 	}
 
+	err = migrateAuditChains()
+	if err != nil {
+		log.Fatalln("Unable to migrate the audit log into chains")
+		return
+	}
+
+	if verifiesEmails {
+		err = migrateEmailVerification()
+		if err != nil {
+			log.Fatalln("Unable to migrate the email verification of users")
+			return
+		}
+	}
+
+	err = migrateBoardWorkspaces()
+	if err != nil {
+		log.Fatalln("Unable to migrate boards into workspaces")
+		return
+	}
+
+	err = migrateTaskPositions()
+	if err != nil {
+		log.Fatalln("Unable to migrate the positions of tasks")
+		return
+	}
+
 	return
 }
+
+// Marks the accounts that predate email verification as verified, so that they are not locked out
+// when verification is required to log in
+func migrateEmailVerification() error {
+	return DB.Model(&models.User{}).
+		Where("email_verified = ?", false).
+		Updates(map[string]interface{}{"email_verified": true, "email_verified_at": time.Now()}).
+		Error
+}
+
+// Drops the index of the single audit chain, as every chain now has its own sequence. The entries that
+// predate chains remain in the chain with no name.
+func migrateAuditChains() error {
+	if !DB.Migrator().HasIndex(&models.AuditEvent{}, "idx_audit_events_sequence") {
+		return nil
+	}
+	return DB.Migrator().DropIndex(&models.AuditEvent{}, "idx_audit_events_sequence")
+}
+
+// Positions the tasks that predate manual ordering by their name, which is how they were sorted before
+func migrateTaskPositions() error {
+	var stateIDs []string
+	err := DB.Unscoped().Model(&models.Task{}).Distinct("state_id").Where("position = ''").Pluck("state_id", &stateIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, stateID := range stateIDs {
+		err = DB.Transaction(func(tx *gorm.DB) error {
+			var tasks []models.Task
+			err := tx.Unscoped().Select("id").Where("state_id = ?", stateID).Order("name, id").Find(&tasks).Error
+			if err != nil {
+				return err
+			}
+			for i, position := range rankUtils.Spread(len(tasks)) {
+				err = tx.Unscoped().Model(&models.Task{ID: tasks[i].ID}).UpdateColumn("position", position).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Moves the boards that predate workspaces into the personal workspace of their owner. Boards without an owner
+// are left without a workspace, rather than trusting another member with it.
+func migrateBoardWorkspaces() error {
+	var boards []models.Board
+	err := DB.Where("workspace_id IS NULL").
+		Preload("Members", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("id")
+		}).
+		Find(&boards).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for _, board := range boards {
+		var userID string
+		for _, member := range board.Members {
+			if member.Role == roleTypes.Owner {
+				userID = member.UserID
+				break
+			}
+		}
+		if len(userID) == 0 {
+			log.Println("Board without an owner is left without a workspace", board.ID)
+			continue
+		}
+
+		err = DB.Transaction(func(tx *gorm.DB) error {
+			var workspace models.Workspace
+			err := tx.Where("user_id = ?", userID).First(&workspace).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				workspace = models.NewPersonalWorkspace(userID)
+				err = tx.Create(&workspace).Error
+			}
+			if err != nil {
+				return err
+			}
+			return tx.Model(&models.Board{ID: board.ID}).Update("workspace_id", workspace.ID).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	accountService "github.com/EmilyOng/tusk-manager/backend/services/account"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	throttleUtils "github.com/EmilyOng/tusk-manager/backend/utils/throttle"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Counts an incorrect current password against the user like a failed login, and clears the count on success
+func recordReauthentication(accountKey string, code int) {
+	var err error
+	if code == http.StatusUnauthorized {
+		err = throttleUtils.Default.Fail(accountKey)
+	} else if code == http.StatusOK {
+		err = throttleUtils.Default.Reset(accountKey)
+	}
+	if err != nil {
+		log.Println("Unable to record reauthentication attempt", err)
+	}
+}
+
+func ExportAccount(ctx *gin.Context) {
+	exportAccountResponse := accountService.ExportAccount(getAuditOrigin(ctx))
+	if exportAccountResponse.Code == http.StatusOK {
+		ctx.Header("Content-Disposition", `attachment; filename="tusk-export.json"`)
+	}
+	ctx.JSON(exportAccountResponse.Code, exportAccountResponse)
+}
+
+func DeleteAccount(ctx *gin.Context) {
+	var payload views.DeleteAccountPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	accountKey := "reauthenticate:user:" + authUserView.ID
+	if !checkAttempts(ctx, accountKey) {
+		return
+	}
+
+	sessionID, _ := ctx.Get(authUtils.SessionKey)
+	deleteAccountResponse := accountService.DeleteAccount(getAuditOrigin(ctx), sessionID.(string), payload)
+	recordReauthentication(accountKey, deleteAccountResponse.Code)
+	ctx.JSON(deleteAccountResponse.Code, deleteAccountResponse)
+}
+
+func UpdateProfile(ctx *gin.Context) {
+	var payload views.UpdateProfilePayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	sessionID, _ := ctx.Get(authUtils.SessionKey)
+	updateProfileResponse := accountService.UpdateProfile(authUserView, sessionID.(string), payload)
+	ctx.JSON(updateProfileResponse.Code, updateProfileResponse)
+}
+
+func ChangePassword(ctx *gin.Context) {
+	var payload views.ChangePasswordPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	accountKey := "reauthenticate:user:" + authUserView.ID
+	if !checkAttempts(ctx, accountKey) {
+		return
+	}
+
+	sessionID, _ := ctx.Get(authUtils.SessionKey)
+	changePasswordResponse := accountService.ChangePassword(getAuditOrigin(ctx), sessionID.(string), payload)
+	recordReauthentication(accountKey, changePasswordResponse.Code)
+	ctx.JSON(changePasswordResponse.Code, changePasswordResponse)
+}
+
+func ChangeEmail(ctx *gin.Context) {
+	var payload views.ChangeEmailPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	accountKey := "reauthenticate:user:" + authUserView.ID
+	if !checkAttempts(ctx, accountKey) {
+		return
+	}
+
+	sessionID, _ := ctx.Get(authUtils.SessionKey)
+	changeEmailResponse := accountService.ChangeEmail(getAuditOrigin(ctx), sessionID.(string), payload)
+	recordReauthentication(accountKey, changeEmailResponse.Code)
+	ctx.JSON(changeEmailResponse.Code, changeEmailResponse)
+}
+
+func ConfirmEmailChange(ctx *gin.Context) {
+	var payload views.ConfirmEmailChangePayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	confirmEmailChangeResponse := accountService.ConfirmEmailChange(getAuditOrigin(ctx), payload)
+	ctx.JSON(confirmEmailChangeResponse.Code, confirmEmailChangeResponse)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Describes who made the request and where it came from, for the audit log
+func getAuditOrigin(ctx *gin.Context) auditService.Origin {
+	authUserView, _ := getAuthUser(ctx)
+	return auditService.Origin{
+		ActorID:   authUserView.ID,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+// Records a login attempt against the user (if known), attributing successful logins to the user
+func recordLogin(ctx *gin.Context, action auditTypes.Action, userID string, details map[string]interface{}) {
+	origin := getAuditOrigin(ctx)
+	if action == auditTypes.LoginSucceeded {
+		origin.ActorID = userID
+	}
+	auditService.Record(origin, auditService.Event{
+		Action:     action,
+		TargetType: "user",
+		TargetID:   userID,
+		Details:    details,
+	})
+}
+
+func GetAuditEvents(ctx *gin.Context) {
+	var payload views.GetAuditEventsPayload
+
+	err := ctx.ShouldBindQuery(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	getAuditEventsResponse := auditService.GetAuditEvents(payload)
+	ctx.JSON(getAuditEventsResponse.Code, getAuditEventsResponse)
+}
+
+func GetBoardAuditEvents(ctx *gin.Context) {
+	var payload views.GetAuditEventsPayload
+
+	err := ctx.ShouldBindQuery(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	// Owners only see the events of their board
+	payload.BoardID = ctx.Param("board_id")
+	getAuditEventsResponse := auditService.GetAuditEvents(payload)
+	ctx.JSON(getAuditEventsResponse.Code, getAuditEventsResponse)
+}
+
+func VerifyAuditLog(ctx *gin.Context) {
+	verifyAuditLogResponse := auditService.VerifyAuditLog()
+	ctx.JSON(verifyAuditLogResponse.Code, verifyAuditLogResponse)
+}
@@ -3,13 +3,24 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	accountService "github.com/EmilyOng/tusk-manager/backend/services/account"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	oidcService "github.com/EmilyOng/tusk-manager/backend/services/oidc"
+	sessionService "github.com/EmilyOng/tusk-manager/backend/services/session"
+	twoFactorService "github.com/EmilyOng/tusk-manager/backend/services/twofactor"
 	userService "github.com/EmilyOng/tusk-manager/backend/services/user"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
 	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
 	seedUtils "github.com/EmilyOng/tusk-manager/backend/utils/seed"
+	throttleUtils "github.com/EmilyOng/tusk-manager/backend/utils/throttle"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 
 	"github.com/gin-gonic/gin"
@@ -17,19 +28,63 @@ import (
 )
 
 const (
-	userNotFoundMessage                        = "The email '%s' does not exist. Have you created an account?"
+	invalidCredentialsMessage                  = "Invalid email or password, please try again."
+	tooManyAttemptsMessage                     = "Too many attempts, please try again in %d seconds."
+	unableToCheckAttemptsMessage               = "Unable to verify login attempts."
 	userAlreadyExistsMessage                   = "The user with the email '%s' already exists."
 	unableToCreateUserMessage                  = "Unable to create user '%s'."
-	passwordsMismatchErrorMessage              = "Passwords do not match, please try again."
 	unableToHashPasswordMessage                = "Unable to hash the password."
 	unableToGenerateAuthenticationTokenMessage = "Unable to generate authentication token."
 	unableToGenerateSeedDataMessage            = "Unable to generate seed data."
+	unableToLogoutMessage                      = "Unable to log out."
+	emailNotVerifiedMessage                    = "Please verify your email '%s' before logging in."
+	invalidChallengeTokenMessage               = "The login has expired, please log in again."
 
-	successfullyLoginMessage  = "Welcome back %s!"
-	successfullySignUpMessage = "Welcome %s! Setting things up..."
-	successfullyLogoutMessage = "Goodbye!"
+	successfullyLoginMessage     = "Welcome back %s!"
+	twoFactorRequiredMessage     = "Please enter the code from your authenticator app."
+	successfullySignUpMessage    = "Welcome %s! Setting things up..."
+	verifyEmailToContinueMessage = "Welcome %s! Please verify your email '%s' to continue."
+	successfullyLogoutMessage    = "Goodbye!"
 )
 
+// Keeps the state of the single sign-on request in the browser that started it
+const oidcStateCookie = "oidc_state"
+
+// Compared against when the user does not exist, so that the response time does not reveal it
+var dummyPasswordHash, _ = authUtils.HashPassword("tusk-manager-dummy-password")
+
+func abortTooManyAttempts(ctx *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.AbortWithStatusJSON(
+		http.StatusTooManyRequests,
+		views.Response{
+			Message: fmt.Sprintf(tooManyAttemptsMessage, seconds),
+			Code:    http.StatusTooManyRequests,
+		},
+	)
+}
+
+// Checks whether any of the keys are locked out, aborting the request if so
+func checkAttempts(ctx *gin.Context, keys ...string) bool {
+	retryAfter, err := throttleUtils.Default.RetryAfter(keys...)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			views.Response{
+				Message: unableToCheckAttemptsMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		)
+		return false
+	}
+	if retryAfter > 0 {
+		abortTooManyAttempts(ctx, retryAfter)
+		return false
+	}
+	return true
+}
+
 func GetAuthToken(ctx *gin.Context) (token string) {
 	token_ := strings.Split(ctx.Request.Header.Get("Authorization"), "Bearer ")
 	if len(token_) < 2 {
@@ -82,33 +137,77 @@ func Login(ctx *gin.Context) {
 		return
 	}
 
+	// Attempts are tracked per account and per client
+	accountKey := "login:email:" + strings.ToLower(payload.Email)
+	clientKey := "login:ip:" + ctx.ClientIP()
+	if !checkAttempts(ctx, accountKey, clientKey) {
+		return
+	}
+
 	user, err := userService.FindUser(payload.Email)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// User record does not exist
+	if err == nil {
+		err = authUtils.ComparePassword(user.Password, payload.Password)
+	} else {
+		_ = authUtils.ComparePassword(dummyPasswordHash, payload.Password)
+	}
+	if err != nil {
+		// The user does not exist, or the password does not match
+		recordLogin(ctx, auditTypes.LoginFailed, user.ID, map[string]interface{}{"method": "password", "email": payload.Email})
+		err = throttleUtils.Default.Fail(accountKey, clientKey)
+		if err != nil {
+			log.Println("Unable to record login attempt", err)
+		}
 		ctx.AbortWithStatusJSON(
-			http.StatusUnprocessableEntity,
+			http.StatusUnauthorized,
 			views.Response{
-				Message: fmt.Sprintf(userNotFoundMessage, payload.Email),
-				Code:    http.StatusUnprocessableEntity,
+				Message: invalidCredentialsMessage,
+				Code:    http.StatusUnauthorized,
 			},
 		)
 		return
 	}
 
-	err = authUtils.ComparePassword(user.Password, payload.Password)
+	err = throttleUtils.Default.Reset(accountKey)
 	if err != nil {
-		// User input password does not match
+		log.Println("Unable to reset login attempts", err)
+	}
+
+	if !user.EmailVerified && accountService.GetVerificationPolicy().RequiresForLogin() {
 		ctx.AbortWithStatusJSON(
-			http.StatusUnauthorized,
+			http.StatusForbidden,
 			views.Response{
-				Message: passwordsMismatchErrorMessage,
-				Code:    http.StatusUnauthorized,
+				Message: fmt.Sprintf(emailNotVerifiedMessage, user.Email),
+				Code:    http.StatusForbidden,
 			},
 		)
 		return
 	}
 
-	signedToken, err := authUtils.GenerateToken(user)
+	if user.TOTPEnabled {
+		// The token is only issued after the second step of the login
+		challengeToken, err := authUtils.GenerateChallengeToken(user)
+		if err != nil {
+			ctx.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				views.Response{
+					Message: unableToGenerateAuthenticationTokenMessage,
+					Code:    http.StatusInternalServerError,
+				},
+			)
+			return
+		}
+		ctx.JSON(http.StatusOK, views.LoginResponse{
+			Response: views.Response{
+				Message: twoFactorRequiredMessage,
+				Code:    http.StatusOK,
+			},
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		})
+		return
+	}
+
+	signedToken, refreshToken, err := sessionService.CreateSession(user)
 	if err != nil {
 		ctx.AbortWithStatusJSON(
 			http.StatusInternalServerError,
@@ -119,20 +218,68 @@ func Login(ctx *gin.Context) {
 		)
 		return
 	}
+	recordLogin(ctx, auditTypes.LoginSucceeded, user.ID, map[string]interface{}{"method": "password"})
 	ctx.JSON(http.StatusOK, views.LoginResponse{
 		Response: views.Response{
 			Message: fmt.Sprintf(successfullyLoginMessage, user.Name),
 			Code:    http.StatusOK,
 		},
 		User: views.AuthUserView{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
-			Token: signedToken,
+			ID:           user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Token:        signedToken,
+			RefreshToken: refreshToken,
 		},
 	})
 }
 
+func LoginTwoFactor(ctx *gin.Context) {
+	var payload views.LoginTwoFactorPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	userID, err := authUtils.ValidateChallengeToken(payload.ChallengeToken)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusUnauthorized,
+			views.Response{
+				Message: invalidChallengeTokenMessage,
+				Code:    http.StatusUnauthorized,
+			},
+		)
+		return
+	}
+
+	accountKey := "two-factor:user:" + userID
+	if !checkAttempts(ctx, accountKey) {
+		return
+	}
+
+	loginResponse := twoFactorService.CompleteLogin(userID, payload)
+	if loginResponse.Code == http.StatusUnauthorized {
+		recordLogin(ctx, auditTypes.LoginFailed, userID, map[string]interface{}{"method": "two-factor"})
+		err = throttleUtils.Default.Fail(accountKey)
+	} else if loginResponse.Code == http.StatusOK {
+		recordLogin(ctx, auditTypes.LoginSucceeded, userID, map[string]interface{}{"method": "two-factor"})
+		err = throttleUtils.Default.Reset(accountKey)
+	}
+	if err != nil {
+		log.Println("Unable to record two-factor attempt", err)
+	}
+	ctx.JSON(loginResponse.Code, loginResponse)
+}
+
 func SignUp(ctx *gin.Context) {
 	var payload views.SignUpPayload
 	err := ctx.ShouldBindJSON(&payload)
@@ -147,9 +294,18 @@ func SignUp(ctx *gin.Context) {
 		return
 	}
 
+	clientKey := "signup:ip:" + ctx.ClientIP()
+	if !checkAttempts(ctx, clientKey) {
+		return
+	}
+
 	_, err = userService.FindUser(payload.Email)
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// User record already exists
+		// User record already exists, which counts as a failed attempt to limit enumeration
+		err = throttleUtils.Default.Fail(clientKey)
+		if err != nil {
+			log.Println("Unable to record sign up attempt", err)
+		}
 		ctx.AbortWithStatusJSON(
 			http.StatusUnprocessableEntity,
 			views.Response{
@@ -188,47 +344,228 @@ func SignUp(ctx *gin.Context) {
 		return
 	}
 
-	signedToken, err := authUtils.GenerateToken(user)
+	// Generate seed data
+	err = seedUtils.SeedData(&user)
 	if err != nil {
 		ctx.AbortWithStatusJSON(
 			http.StatusInternalServerError,
 			views.Response{
-				Message: unableToGenerateAuthenticationTokenMessage,
+				Message: unableToGenerateSeedDataMessage,
 				Code:    http.StatusInternalServerError,
 			},
 		)
 		return
 	}
 
-	// Generate seed data
-	err = seedUtils.SeedData(&user)
+	origin := getAuditOrigin(ctx)
+	origin.ActorID = user.ID
+	auditService.Record(origin, auditService.Event{
+		Action:     auditTypes.UserSignedUp,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    map[string]interface{}{"method": "password"},
+	})
+
+	// Pending invitations of the email are only accepted once the user has verified owning it
+	err = accountService.SendVerificationEmail(user)
+	if err != nil {
+		log.Println("Unable to send verification email", err)
+	}
+
+	if accountService.GetVerificationPolicy().RequiresForLogin() {
+		// The user can only log in after verifying the email
+		ctx.JSON(http.StatusOK, views.SignUpResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(verifyEmailToContinueMessage, user.Name, user.Email),
+				Code:    http.StatusOK,
+			},
+			User: views.AuthUserView{
+				ID:    user.ID,
+				Name:  user.Name,
+				Email: user.Email,
+			},
+		})
+		return
+	}
+
+	signedToken, refreshToken, err := sessionService.CreateSession(user)
 	if err != nil {
 		ctx.AbortWithStatusJSON(
 			http.StatusInternalServerError,
 			views.Response{
-				Message: unableToGenerateSeedDataMessage,
+				Message: unableToGenerateAuthenticationTokenMessage,
 				Code:    http.StatusInternalServerError,
 			},
 		)
 		return
 	}
+
 	ctx.JSON(http.StatusOK, views.SignUpResponse{
 		Response: views.Response{
 			Message: fmt.Sprintf(successfullySignUpMessage, user.Name),
 			Code:    http.StatusOK,
 		},
 		User: views.AuthUserView{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
-			Token: signedToken,
+			ID:           user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Token:        signedToken,
+			RefreshToken: refreshToken,
 		},
 	})
 }
 
-func Logout(c *gin.Context) {
-	c.Set(authUtils.UserKey, nil)
-	c.JSON(http.StatusOK, views.Response{
+func Refresh(ctx *gin.Context) {
+	var payload views.RefreshPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	refreshResponse := sessionService.RefreshSession(getAuditOrigin(ctx), payload)
+	ctx.JSON(refreshResponse.Code, refreshResponse)
+}
+
+func ForgotPassword(ctx *gin.Context) {
+	var payload views.ForgotPasswordPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	forgotPasswordResponse := accountService.ForgotPassword(payload)
+	ctx.JSON(forgotPasswordResponse.Code, forgotPasswordResponse)
+}
+
+func ResetPassword(ctx *gin.Context) {
+	var payload views.ResetPasswordPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	resetPasswordResponse := accountService.ResetPassword(payload)
+	ctx.JSON(resetPasswordResponse.Code, resetPasswordResponse)
+}
+
+func VerifyEmail(ctx *gin.Context) {
+	var payload views.VerifyEmailPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	verifyEmailResponse := accountService.VerifyEmail(getAuditOrigin(ctx), payload)
+	ctx.JSON(verifyEmailResponse.Code, verifyEmailResponse)
+}
+
+func ResendVerificationEmail(ctx *gin.Context) {
+	var payload views.ResendVerificationPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	resendVerificationResponse := accountService.ResendVerificationEmail(payload)
+	ctx.JSON(resendVerificationResponse.Code, resendVerificationResponse)
+}
+
+// Sets the cookie that binds the single sign-on request to the browser, or clears it when the age is negative
+func setOIDCStateCookie(ctx *gin.Context, state string, maxAge int) {
+	// The frontend is served from another site
+	ctx.SetSameSite(http.SameSiteNoneMode)
+	ctx.SetCookie(oidcStateCookie, state, maxAge, "/api/auth/oidc", "", true, true)
+}
+
+func OIDCLogin(ctx *gin.Context) {
+	oidcLoginResponse := oidcService.StartLogin()
+	if oidcLoginResponse.Code == http.StatusOK {
+		setOIDCStateCookie(ctx, oidcLoginResponse.State, int(oidcService.AuthRequestDuration.Seconds()))
+	}
+	ctx.JSON(oidcLoginResponse.Code, oidcLoginResponse)
+}
+
+func OIDCCallback(ctx *gin.Context) {
+	var payload views.OIDCCallbackPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.CookieState, _ = ctx.Cookie(oidcStateCookie)
+	setOIDCStateCookie(ctx, "", -1)
+	oidcCallbackResponse := oidcService.HandleCallback(getAuditOrigin(ctx), payload)
+	ctx.JSON(oidcCallbackResponse.Code, oidcCallbackResponse)
+}
+
+func Logout(ctx *gin.Context) {
+	sessionInterface, _ := ctx.Get(authUtils.SessionKey)
+	if sessionInterface != nil {
+		// Invalidates the access and refresh tokens of the session
+		err := sessionService.RevokeSession(sessionInterface.(string))
+		if err != nil {
+			ctx.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				views.Response{
+					Message: unableToLogoutMessage,
+					Code:    http.StatusInternalServerError,
+				},
+			)
+			return
+		}
+	}
+
+	ctx.Set(authUtils.UserKey, nil)
+	ctx.Set(authUtils.SessionKey, nil)
+	ctx.JSON(http.StatusOK, views.Response{
 		Message: successfullyLogoutMessage,
 		Code:    http.StatusOK,
 	})
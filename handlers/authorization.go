@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	tokenService "github.com/EmilyOng/tusk-manager/backend/services/token"
+	userService "github.com/EmilyOng/tusk-manager/backend/services/user"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	scopeTypes "github.com/EmilyOng/tusk-manager/backend/types/scope"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	forbiddenMessage                = "You do not have permission to perform this action."
+	unableToVerifyPermissionMessage = "Unable to verify permissions."
+)
+
+var errMalformedPayload = errors.New("malformed payload")
+
+// Resolves the board that the request acts on
+type BoardResolver func(ctx *gin.Context) (boardID string, err error)
+
+type boardScopedPayload struct {
+	ID      string `json:"id"`
+	BoardID string `json:"boardId"`
+}
+
+// Reads the identifiers in the JSON payload, while leaving the body intact for the handler
+func peekPayload(ctx *gin.Context) (payload boardScopedPayload, err error) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if json.Unmarshal(body, &payload) != nil {
+		err = errMalformedPayload
+	}
+	return
+}
+
+// Resolves the board from the path parameter
+func FromBoardParam(param string) BoardResolver {
+	return func(ctx *gin.Context) (string, error) {
+		return ctx.Param(param), nil
+	}
+}
+
+// Resolves the board through the resource identified by the path parameter
+func FromResourceParam(model interface{}, param string) BoardResolver {
+	return func(ctx *gin.Context) (string, error) {
+		return authorizationService.GetResourceBoardID(model, ctx.Param(param))
+	}
+}
+
+// Resolves the board from the 'id' of a board payload
+func FromBoardPayload(ctx *gin.Context) (string, error) {
+	payload, err := peekPayload(ctx)
+	return payload.ID, err
+}
+
+// Resolves the board from the 'boardId' of a payload creating a resource
+func FromBoardIDPayload(ctx *gin.Context) (string, error) {
+	payload, err := peekPayload(ctx)
+	return payload.BoardID, err
+}
+
+// Resolves the board through the stored resource identified by the 'id' of the payload.
+// A payload that attempts to move the resource to another board is rejected.
+func FromResourcePayload(model interface{}) BoardResolver {
+	return func(ctx *gin.Context) (string, error) {
+		payload, err := peekPayload(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		boardID, err := authorizationService.GetResourceBoardID(model, payload.ID)
+		if err != nil {
+			return "", err
+		}
+		if len(payload.BoardID) > 0 && payload.BoardID != boardID {
+			return "", gorm.ErrRecordNotFound
+		}
+		return boardID, nil
+	}
+}
+
+var (
+	FromTaskParam   = FromResourceParam(&models.Task{}, "task_id")
+	FromTagParam    = FromResourceParam(&models.Tag{}, "tag_id")
+	FromStateParam  = FromResourceParam(&models.State{}, "state_id")
+	FromMemberParam = FromResourceParam(&models.Member{}, "member_id")
+
+	FromTaskPayload   = FromResourcePayload(&models.Task{})
+	FromTagPayload    = FromResourcePayload(&models.Tag{})
+	FromStatePayload  = FromResourcePayload(&models.State{})
+	FromMemberPayload = FromResourcePayload(&models.Member{})
+)
+
+func abortForbidden(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(
+		http.StatusForbidden,
+		views.Response{
+			Message: forbiddenMessage,
+			Code:    http.StatusForbidden,
+		},
+	)
+}
+
+// Retrieves the personal access token that authenticated the request, if any
+func getPersonalAccessToken(ctx *gin.Context) (token models.PersonalAccessToken, ok bool) {
+	tokenInterface, _ := ctx.Get(authUtils.PersonalAccessTokenKey)
+	if tokenInterface == nil {
+		return
+	}
+	token, ok = tokenInterface.(models.PersonalAccessToken)
+	return
+}
+
+// Returns whether the personal access token (if any) grants the scope on the board.
+// Requests that are not scoped to a board have an empty board, which restricted tokens cannot access.
+func tokenPermits(ctx *gin.Context, scope scopeTypes.Scope, boardID string) bool {
+	token, ok := getPersonalAccessToken(ctx)
+	if !ok {
+		// Sessions are not restricted by scopes
+		return true
+	}
+
+	if token.BoardID != nil && *token.BoardID != boardID {
+		return false
+	}
+	for _, grantedScope := range tokenService.GetScopes(token) {
+		if grantedScope.Includes(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Requires personal access tokens to hold the scope, for requests that are not scoped to a board
+func RequireScope(scope scopeTypes.Scope) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !tokenPermits(ctx, scope, "") {
+			abortForbidden(ctx)
+		}
+	}
+}
+
+// Rejects requests authenticated by personal access tokens, such as those managing credentials
+func RequireSession(ctx *gin.Context) {
+	if _, ok := getPersonalAccessToken(ctx); ok {
+		abortForbidden(ctx)
+	}
+}
+
+// Requires the authenticated user to be an administrator
+func RequireAdmin(ctx *gin.Context) {
+	authUserView, _ := getAuthUser(ctx)
+	user, err := userService.FindUserByID(authUserView.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.AbortWithStatusJSON(
+			http.StatusInternalServerError,
+			views.Response{
+				Message: unableToVerifyPermissionMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		)
+		return
+	}
+	if err != nil || !user.IsAdmin {
+		abortForbidden(ctx)
+	}
+}
+
+// Requires the authenticated user to hold the permission on the resolved board,
+// and personal access tokens to hold the scope
+func Authorize(permission permissionTypes.Permission, scope scopeTypes.Scope, resolve BoardResolver) gin.HandlerFunc {
+	return AuthorizeAny([]permissionTypes.Permission{permission}, scope, resolve)
+}
+
+// Requires the authenticated user to hold any of the permissions on the resolved board,
+// and personal access tokens to hold the scope. The permissions of the user are kept for the handler.
+func AuthorizeAny(permissions []permissionTypes.Permission, scope scopeTypes.Scope, resolve BoardResolver) gin.HandlerFunc {
+	return authorize(permissions, scope, resolve, authorizationService.GetBoardPermissions)
+}
+
+// Requires the authenticated user to have held the permission on the resolved board before it was moved
+// to the trash, and personal access tokens to hold the scope
+func AuthorizeTrashed(permission permissionTypes.Permission, scope scopeTypes.Scope, resolve BoardResolver) gin.HandlerFunc {
+	return authorize([]permissionTypes.Permission{permission}, scope, resolve, authorizationService.GetTrashedBoardPermissions)
+}
+
+func authorize(
+	permissions []permissionTypes.Permission,
+	scope scopeTypes.Scope,
+	resolve BoardResolver,
+	getPermissions func(userID string, boardID string) (permissionTypes.Set, error),
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInterface, _ := ctx.Get(authUtils.UserKey)
+		if userInterface == nil {
+			ctx.AbortWithStatusJSON(
+				http.StatusUnauthorized,
+				views.Response{
+					Message: unauthorizedMessage,
+					Code:    http.StatusUnauthorized,
+				},
+			)
+			return
+		}
+		authUserView := userInterface.(views.AuthUserView)
+
+		boardID, err := resolve(ctx)
+		if errors.Is(err, errMalformedPayload) {
+			ctx.AbortWithStatusJSON(
+				http.StatusBadRequest,
+				views.Response{
+					Message: typeMismatchErrorMessage,
+					Code:    http.StatusBadRequest,
+				},
+			)
+			return
+		}
+		if err == nil && !tokenPermits(ctx, scope, boardID) {
+			abortForbidden(ctx)
+			return
+		}
+		if err == nil {
+			var boardPermissions permissionTypes.Set
+			boardPermissions, err = getPermissions(authUserView.ID, boardID)
+			for _, permission := range permissions {
+				if err == nil && boardPermissions.Has(permission) {
+					ctx.Set(authUtils.BoardPermissionsKey, boardPermissions)
+					return
+				}
+			}
+		}
+
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				views.Response{
+					Message: unableToVerifyPermissionMessage,
+					Code:    http.StatusInternalServerError,
+				},
+			)
+			return
+		}
+		// The board does not exist, or the user does not hold the permissions
+		abortForbidden(ctx)
+	}
+}
+
+// Retrieves the permissions on the board that the request was authorized for
+func getBoardPermissions(ctx *gin.Context) permissionTypes.Set {
+	permissionsInterface, _ := ctx.Get(authUtils.BoardPermissionsKey)
+	permissions, _ := permissionsInterface.(permissionTypes.Set)
+	return permissions
+}
+
+// Requires the authenticated user to hold at least the given role in the workspace of the path parameter,
+// and personal access tokens to hold the scope. Tokens restricted to a board cannot access workspaces.
+func AuthorizeWorkspace(role workspaceTypes.Role, scope scopeTypes.Scope, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authUserView, _ := getAuthUser(ctx)
+		if !tokenPermits(ctx, scope, "") {
+			abortForbidden(ctx)
+			return
+		}
+
+		workspaceRole, err := authorizationService.GetWorkspaceRole(authUserView.ID, ctx.Param(param))
+		if err == nil && workspaceRole.Includes(role) {
+			return
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(
+				http.StatusInternalServerError,
+				views.Response{
+					Message: unableToVerifyPermissionMessage,
+					Code:    http.StatusInternalServerError,
+				},
+			)
+			return
+		}
+		// The workspace does not exist, or the user is not a member with sufficient privileges
+		abortForbidden(ctx)
+	}
+}
@@ -5,15 +5,14 @@ import (
 
 	boardService "github.com/EmilyOng/tusk-manager/backend/services/board"
 	userService "github.com/EmilyOng/tusk-manager/backend/services/user"
-	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 
 	"github.com/gin-gonic/gin"
 )
 
 func GetUserBoards(ctx *gin.Context) {
-	userInterface, _ := ctx.Get(authUtils.UserKey)
-	if userInterface == nil {
+	authUserView, ok := getAuthUser(ctx)
+	if !ok {
 		ctx.AbortWithStatusJSON(
 			http.StatusUnauthorized,
 			views.Response{
@@ -23,9 +22,22 @@ func GetUserBoards(ctx *gin.Context) {
 		)
 		return
 	}
-	authUserView := userInterface.(views.AuthUserView)
 
-	getUserBoardsResponse := userService.GetUserBoards(views.GetUserBoardsPayload{UserID: authUserView.ID})
+	var payload views.GetUserBoardsPayload
+	err := ctx.ShouldBindQuery(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.UserID = authUserView.ID
+	getUserBoardsResponse := userService.GetUserBoards(payload)
 	ctx.JSON(getUserBoardsResponse.Code, getUserBoardsResponse)
 }
 
@@ -49,7 +61,8 @@ func CreateBoard(ctx *gin.Context) {
 		return
 	}
 
-	createBoardResponse := boardService.CreateBoard(payload)
+	authUserView, _ := getAuthUser(ctx)
+	createBoardResponse := boardService.CreateBoard(authUserView, payload)
 	ctx.JSON(createBoardResponse.Code, createBoardResponse)
 }
 
@@ -85,12 +98,13 @@ func UpdateBoard(ctx *gin.Context) {
 		return
 	}
 
-	updateBoardResponse := boardService.UpdateBoard(payload)
+	authUserView, _ := getAuthUser(ctx)
+	updateBoardResponse := boardService.UpdateBoard(authUserView, payload)
 	ctx.JSON(updateBoardResponse.Code, updateBoardResponse)
 }
 
 func DeleteBoard(ctx *gin.Context) {
-	deleteBoardResponse := boardService.DeleteBoard(views.DeleteBoardPayload{ID: ctx.Param("board_id")})
+	deleteBoardResponse := boardService.DeleteBoard(getAuditOrigin(ctx), views.DeleteBoardPayload{ID: ctx.Param("board_id")})
 	ctx.JSON(deleteBoardResponse.Code, deleteBoardResponse)
 }
 
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+
+	customRoleService "github.com/EmilyOng/tusk-manager/backend/services/customrole"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Resolves whether the custom roles of a board or of a workspace are managed, and the permissions of the actor.
+// Workspace admins hold every permission on the boards of the workspace.
+func getCustomRoleOwner(ctx *gin.Context) (workspaceID string, boardID string, grantor permissionTypes.Set) {
+	boardID = ctx.Param("board_id")
+	if len(boardID) > 0 {
+		return "", boardID, getBoardPermissions(ctx)
+	}
+	return ctx.Param("workspace_id"), "", permissionTypes.NewSet(permissionTypes.Catalogue...)
+}
+
+func GetPermissionCatalogue(ctx *gin.Context) {
+	getPermissionCatalogueResponse := customRoleService.GetPermissionCatalogue()
+	ctx.JSON(getPermissionCatalogueResponse.Code, getPermissionCatalogueResponse)
+}
+
+func GetBoardPermissions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, views.GetBoardPermissionsResponse{
+		Response:    views.Response{Code: http.StatusOK},
+		Permissions: getBoardPermissions(ctx).List(),
+	})
+}
+
+func GetCustomRoles(ctx *gin.Context) {
+	workspaceID, boardID, _ := getCustomRoleOwner(ctx)
+	getCustomRolesResponse := customRoleService.GetCustomRoles(
+		views.GetCustomRolesPayload{WorkspaceID: workspaceID, BoardID: boardID},
+	)
+	ctx.JSON(getCustomRolesResponse.Code, getCustomRolesResponse)
+}
+
+func CreateCustomRole(ctx *gin.Context) {
+	var payload views.CreateCustomRolePayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	var grantor permissionTypes.Set
+	payload.WorkspaceID, payload.BoardID, grantor = getCustomRoleOwner(ctx)
+	createCustomRoleResponse := customRoleService.CreateCustomRole(getAuditOrigin(ctx), grantor, payload)
+	ctx.JSON(createCustomRoleResponse.Code, createCustomRoleResponse)
+}
+
+func UpdateCustomRole(ctx *gin.Context) {
+	var payload views.UpdateCustomRolePayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	var grantor permissionTypes.Set
+	payload.ID = ctx.Param("role_id")
+	payload.WorkspaceID, payload.BoardID, grantor = getCustomRoleOwner(ctx)
+	updateCustomRoleResponse := customRoleService.UpdateCustomRole(getAuditOrigin(ctx), grantor, payload)
+	ctx.JSON(updateCustomRoleResponse.Code, updateCustomRoleResponse)
+}
+
+func DeleteCustomRole(ctx *gin.Context) {
+	workspaceID, boardID, grantor := getCustomRoleOwner(ctx)
+	deleteCustomRoleResponse := customRoleService.DeleteCustomRole(
+		getAuditOrigin(ctx),
+		grantor,
+		views.DeleteCustomRolePayload{ID: ctx.Param("role_id"), WorkspaceID: workspaceID, BoardID: boardID},
+	)
+	ctx.JSON(deleteCustomRoleResponse.Code, deleteCustomRoleResponse)
+}
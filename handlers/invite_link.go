@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	inviteLinkService "github.com/EmilyOng/tusk-manager/backend/services/invitelink"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetInviteLinks(ctx *gin.Context) {
+	getInviteLinksResponse := inviteLinkService.GetInviteLinks(views.GetInviteLinksPayload{BoardID: ctx.Param("board_id")})
+	ctx.JSON(getInviteLinksResponse.Code, getInviteLinksResponse)
+}
+
+func CreateInviteLink(ctx *gin.Context) {
+	var payload views.CreateInviteLinkPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	createInviteLinkResponse := inviteLinkService.CreateInviteLink(getAuditOrigin(ctx), getBoardPermissions(ctx), payload)
+	ctx.JSON(createInviteLinkResponse.Code, createInviteLinkResponse)
+}
+
+func RevokeInviteLink(ctx *gin.Context) {
+	revokeInviteLinkResponse := inviteLinkService.RevokeInviteLink(
+		getAuditOrigin(ctx),
+		views.RevokeInviteLinkPayload{ID: ctx.Param("link_id"), BoardID: ctx.Param("board_id")},
+	)
+	ctx.JSON(revokeInviteLinkResponse.Code, revokeInviteLinkResponse)
+}
+
+func RedeemInviteLink(ctx *gin.Context) {
+	var payload views.RedeemInviteLinkPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	redeemInviteLinkResponse := inviteLinkService.RedeemInviteLink(getAuditOrigin(ctx), payload)
+	ctx.JSON(redeemInviteLinkResponse.Code, redeemInviteLinkResponse)
+}
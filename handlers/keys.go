@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"net/http"
+
+	keyUtils "github.com/EmilyOng/tusk-manager/backend/utils/keys"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Publishes the public signing keys as a JWKS, so that other services can verify tokens
+func GetJWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, keyUtils.Default.JWKS())
+}
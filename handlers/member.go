@@ -3,7 +3,9 @@ package handlers
 import (
 	"net/http"
 
+	invitationService "github.com/EmilyOng/tusk-manager/backend/services/invitation"
 	memberService "github.com/EmilyOng/tusk-manager/backend/services/member"
+	scopeTypes "github.com/EmilyOng/tusk-manager/backend/types/scope"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 
 	"github.com/gin-gonic/gin"
@@ -24,7 +26,7 @@ func CreateMember(ctx *gin.Context) {
 		return
 	}
 
-	createMemberResponse := memberService.CreateMember(payload)
+	createMemberResponse := memberService.CreateMember(getAuditOrigin(ctx), getBoardPermissions(ctx), payload)
 	ctx.JSON(createMemberResponse.Code, createMemberResponse)
 }
 
@@ -43,11 +45,105 @@ func UpdateMember(ctx *gin.Context) {
 		return
 	}
 
-	updateMemberResponse := memberService.UpdateMember(payload)
+	updateMemberResponse := memberService.UpdateMember(getAuditOrigin(ctx), getBoardPermissions(ctx), payload)
 	ctx.JSON(updateMemberResponse.Code, updateMemberResponse)
 }
 
 func DeleteMember(ctx *gin.Context) {
-	deleteMemberResponse := memberService.DeleteMember(views.DeleteMemberPayload{ID: ctx.Param("member_id")})
+	deleteMemberResponse := memberService.DeleteMember(
+		getAuditOrigin(ctx),
+		getBoardPermissions(ctx),
+		views.DeleteMemberPayload{ID: ctx.Param("member_id")},
+	)
 	ctx.JSON(deleteMemberResponse.Code, deleteMemberResponse)
 }
+
+func TransferOwnership(ctx *gin.Context) {
+	var payload views.TransferOwnershipPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	transferOwnershipResponse := memberService.TransferOwnership(getAuditOrigin(ctx), payload)
+	ctx.JSON(transferOwnershipResponse.Code, transferOwnershipResponse)
+}
+
+func LeaveBoard(ctx *gin.Context) {
+	var payload views.LeaveBoardPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	if payload.DeleteBoard && !tokenPermits(ctx, scopeTypes.BoardsWrite, payload.BoardID) {
+		abortForbidden(ctx)
+		return
+	}
+
+	leaveBoardResponse := memberService.LeaveBoard(getAuditOrigin(ctx), payload)
+	ctx.JSON(leaveBoardResponse.Code, leaveBoardResponse)
+}
+
+func GetBoardInvitations(ctx *gin.Context) {
+	getBoardInvitationsResponse := invitationService.GetBoardInvitations(
+		views.GetBoardInvitationsPayload{BoardID: ctx.Param("board_id")},
+	)
+	ctx.JSON(getBoardInvitationsResponse.Code, getBoardInvitationsResponse)
+}
+
+func AcceptInvitation(ctx *gin.Context) {
+	var payload views.AcceptInvitationPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	acceptInvitationResponse := invitationService.AcceptInvitation(getAuditOrigin(ctx), payload)
+	ctx.JSON(acceptInvitationResponse.Code, acceptInvitationResponse)
+}
+
+func DeclineInvitation(ctx *gin.Context) {
+	var payload views.DeclineInvitationPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	declineInvitationResponse := invitationService.DeclineInvitation(getAuditOrigin(ctx), payload)
+	ctx.JSON(declineInvitationResponse.Code, declineInvitationResponse)
+}
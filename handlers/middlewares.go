@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
+	tokenService "github.com/EmilyOng/tusk-manager/backend/services/token"
 	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 
@@ -16,11 +18,28 @@ const (
 
 func SetAuthUser(ctx *gin.Context) {
 	token := GetAuthToken(ctx)
+	ctx.Set(authUtils.UserKey, nil)
+	ctx.Set(authUtils.SessionKey, nil)
+	ctx.Set(authUtils.PersonalAccessTokenKey, nil)
 
-	claims, err := authUtils.ValidateToken(token)
+	if strings.HasPrefix(token, authUtils.PersonalAccessTokenPrefix) {
+		personalAccessToken, user, err := tokenService.ValidatePersonalAccessToken(token)
+		if err != nil {
+			return
+		}
+
+		ctx.Set(authUtils.UserKey, views.AuthUserView{
+			ID:    user.ID,
+			Name:  user.Name,
+			Email: user.Email,
+			Token: token,
+		})
+		ctx.Set(authUtils.PersonalAccessTokenKey, personalAccessToken)
+		return
+	}
 
+	claims, err := authUtils.ValidateToken(token)
 	if err != nil {
-		ctx.Set(authUtils.UserKey, nil)
 		return
 	}
 
@@ -32,6 +51,17 @@ func SetAuthUser(ctx *gin.Context) {
 	}
 
 	ctx.Set(authUtils.UserKey, authUserView)
+	ctx.Set(authUtils.SessionKey, claims.SessionID)
+}
+
+// Retrieves the authenticated user set by SetAuthUser
+func getAuthUser(ctx *gin.Context) (authUserView views.AuthUserView, ok bool) {
+	userInterface, _ := ctx.Get(authUtils.UserKey)
+	if userInterface == nil {
+		return
+	}
+	authUserView, ok = userInterface.(views.AuthUserView)
+	return
 }
 
 func AuthGuard(ctx *gin.Context) {
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	publicationService "github.com/EmilyOng/tusk-manager/backend/services/publication"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetBoardPublication(ctx *gin.Context) {
+	getBoardPublicationResponse := publicationService.GetBoardPublication(views.GetBoardPublicationPayload{BoardID: ctx.Param("board_id")})
+	ctx.JSON(getBoardPublicationResponse.Code, getBoardPublicationResponse)
+}
+
+func PublishBoard(ctx *gin.Context) {
+	var payload views.PublishBoardPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	publishBoardResponse := publicationService.PublishBoard(getAuditOrigin(ctx), payload)
+	ctx.JSON(publishBoardResponse.Code, publishBoardResponse)
+}
+
+func UpdateBoardPublication(ctx *gin.Context) {
+	var payload views.UpdateBoardPublicationPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	updateBoardPublicationResponse := publicationService.UpdateBoardPublication(payload)
+	ctx.JSON(updateBoardPublicationResponse.Code, updateBoardPublicationResponse)
+}
+
+func UnpublishBoard(ctx *gin.Context) {
+	unpublishBoardResponse := publicationService.UnpublishBoard(
+		getAuditOrigin(ctx),
+		views.UnpublishBoardPayload{BoardID: ctx.Param("board_id")},
+	)
+	ctx.JSON(unpublishBoardResponse.Code, unpublishBoardResponse)
+}
+
+func GetPublicBoard(ctx *gin.Context) {
+	getPublicBoardResponse := publicationService.GetPublicBoard(views.GetPublicBoardPayload{Token: ctx.Param("token")})
+	ctx.JSON(getPublicBoardResponse.Code, getPublicBoardResponse)
+}
@@ -48,6 +48,41 @@ func UpdateState(ctx *gin.Context) {
 }
 
 func DeleteState(ctx *gin.Context) {
-	deleteStateResponse := stateService.DeleteState(views.DeleteStatePayload{ID: ctx.Param("state_id")})
+	var payload views.DeleteStatePayload
+
+	err := ctx.ShouldBindQuery(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.ID = ctx.Param("state_id")
+	deleteStateResponse := stateService.DeleteState(payload)
 	ctx.JSON(deleteStateResponse.Code, deleteStateResponse)
 }
+
+func ReorderStates(ctx *gin.Context) {
+	var payload views.ReorderStatesPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	reorderStatesResponse := stateService.ReorderStates(payload)
+	ctx.JSON(reorderStatesResponse.Code, reorderStatesResponse)
+}
@@ -4,11 +4,37 @@ import (
 	"net/http"
 
 	taskService "github.com/EmilyOng/tusk-manager/backend/services/task"
+	tokenService "github.com/EmilyOng/tusk-manager/backend/services/token"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	scopeTypes "github.com/EmilyOng/tusk-manager/backend/types/scope"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Retrieves the permissions on the board of the task. Creating tags through the task also requires personal
+// access tokens to be allowed to write tags.
+func getTaskPermissions(ctx *gin.Context) permissionTypes.Set {
+	permissions := getBoardPermissions(ctx)
+	token, ok := getPersonalAccessToken(ctx)
+	if !ok || !permissions.Has(permissionTypes.ManageTags) {
+		return permissions
+	}
+	for _, scope := range tokenService.GetScopes(token) {
+		if scope.Includes(scopeTypes.TagsWrite) {
+			return permissions
+		}
+	}
+
+	taskPermissions := permissionTypes.NewSet()
+	for _, permission := range permissions.List() {
+		if permission != permissionTypes.ManageTags {
+			taskPermissions.Add(permission)
+		}
+	}
+	return taskPermissions
+}
+
 func CreateTask(ctx *gin.Context) {
 	var payload views.CreateTaskPayload
 
@@ -24,7 +50,8 @@ func CreateTask(ctx *gin.Context) {
 		return
 	}
 
-	createTaskResponse := taskService.CreateTask(payload)
+	authUserView, _ := getAuthUser(ctx)
+	createTaskResponse := taskService.CreateTask(authUserView, getTaskPermissions(ctx), payload)
 	ctx.JSON(createTaskResponse.Code, createTaskResponse)
 }
 
@@ -43,11 +70,60 @@ func UpdateTask(ctx *gin.Context) {
 		return
 	}
 
-	updateTaskResponse := taskService.UpdateTask(payload)
+	authUserView, _ := getAuthUser(ctx)
+	updateTaskResponse := taskService.UpdateTask(authUserView, getTaskPermissions(ctx), payload)
 	ctx.JSON(updateTaskResponse.Code, updateTaskResponse)
 }
 
+func MoveTask(ctx *gin.Context) {
+	var payload views.MoveTaskPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.ID = ctx.Param("task_id")
+	moveTaskResponse := taskService.MoveTask(payload)
+	ctx.JSON(moveTaskResponse.Code, moveTaskResponse)
+}
+
 func DeleteTask(ctx *gin.Context) {
 	deleteTaskResponse := taskService.DeleteTask(views.DeleteTaskPayload{ID: ctx.Param("task_id")})
 	ctx.JSON(deleteTaskResponse.Code, deleteTaskResponse)
 }
+
+func AssignTask(ctx *gin.Context) {
+	var payload views.AssignTaskPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.ID = ctx.Param("task_id")
+	assignTaskResponse := taskService.AssignTask(payload)
+	ctx.JSON(assignTaskResponse.Code, assignTaskResponse)
+}
+
+func UnassignTask(ctx *gin.Context) {
+	unassignTaskResponse := taskService.UnassignTask(views.UnassignTaskPayload{
+		ID:     ctx.Param("task_id"),
+		UserID: ctx.Param("user_id"),
+	})
+	ctx.JSON(unassignTaskResponse.Code, unassignTaskResponse)
+}
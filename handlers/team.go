@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+
+	teamService "github.com/EmilyOng/tusk-manager/backend/services/team"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetWorkspaceTeams(ctx *gin.Context) {
+	getWorkspaceTeamsResponse := teamService.GetWorkspaceTeams(
+		views.GetWorkspaceTeamsPayload{WorkspaceID: ctx.Param("workspace_id")},
+	)
+	ctx.JSON(getWorkspaceTeamsResponse.Code, getWorkspaceTeamsResponse)
+}
+
+func CreateTeam(ctx *gin.Context) {
+	var payload views.CreateTeamPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.WorkspaceID = ctx.Param("workspace_id")
+	createTeamResponse := teamService.CreateTeam(payload)
+	ctx.JSON(createTeamResponse.Code, createTeamResponse)
+}
+
+func UpdateTeam(ctx *gin.Context) {
+	var payload views.UpdateTeamPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.ID = ctx.Param("team_id")
+	payload.WorkspaceID = ctx.Param("workspace_id")
+	updateTeamResponse := teamService.UpdateTeam(payload)
+	ctx.JSON(updateTeamResponse.Code, updateTeamResponse)
+}
+
+func DeleteTeam(ctx *gin.Context) {
+	deleteTeamResponse := teamService.DeleteTeam(
+		getAuditOrigin(ctx),
+		views.DeleteTeamPayload{ID: ctx.Param("team_id"), WorkspaceID: ctx.Param("workspace_id")},
+	)
+	ctx.JSON(deleteTeamResponse.Code, deleteTeamResponse)
+}
+
+func CreateTeamMember(ctx *gin.Context) {
+	var payload views.CreateTeamMemberPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.TeamID = ctx.Param("team_id")
+	payload.WorkspaceID = ctx.Param("workspace_id")
+	createTeamMemberResponse := teamService.CreateTeamMember(getAuditOrigin(ctx), payload)
+	ctx.JSON(createTeamMemberResponse.Code, createTeamMemberResponse)
+}
+
+func DeleteTeamMember(ctx *gin.Context) {
+	deleteTeamMemberResponse := teamService.DeleteTeamMember(
+		getAuditOrigin(ctx),
+		views.DeleteTeamMemberPayload{
+			TeamID:      ctx.Param("team_id"),
+			WorkspaceID: ctx.Param("workspace_id"),
+			UserID:      ctx.Param("user_id"),
+		},
+	)
+	ctx.JSON(deleteTeamMemberResponse.Code, deleteTeamMemberResponse)
+}
+
+func GetBoardTeams(ctx *gin.Context) {
+	getBoardTeamsResponse := teamService.GetBoardTeams(views.GetBoardTeamsPayload{BoardID: ctx.Param("board_id")})
+	ctx.JSON(getBoardTeamsResponse.Code, getBoardTeamsResponse)
+}
+
+func CreateBoardTeam(ctx *gin.Context) {
+	var payload views.CreateBoardTeamPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	createBoardTeamResponse := teamService.CreateBoardTeam(getAuditOrigin(ctx), getBoardPermissions(ctx), payload)
+	ctx.JSON(createBoardTeamResponse.Code, createBoardTeamResponse)
+}
+
+func UpdateBoardTeam(ctx *gin.Context) {
+	var payload views.UpdateBoardTeamPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.ID = ctx.Param("board_team_id")
+	payload.BoardID = ctx.Param("board_id")
+	updateBoardTeamResponse := teamService.UpdateBoardTeam(getAuditOrigin(ctx), getBoardPermissions(ctx), payload)
+	ctx.JSON(updateBoardTeamResponse.Code, updateBoardTeamResponse)
+}
+
+func DeleteBoardTeam(ctx *gin.Context) {
+	deleteBoardTeamResponse := teamService.DeleteBoardTeam(
+		getAuditOrigin(ctx),
+		getBoardPermissions(ctx),
+		views.DeleteBoardTeamPayload{ID: ctx.Param("board_team_id"), BoardID: ctx.Param("board_id")},
+	)
+	ctx.JSON(deleteBoardTeamResponse.Code, deleteBoardTeamResponse)
+}
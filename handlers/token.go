@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	tokenService "github.com/EmilyOng/tusk-manager/backend/services/token"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetPersonalAccessTokens(ctx *gin.Context) {
+	authUserView, _ := getAuthUser(ctx)
+	getPersonalAccessTokensResponse := tokenService.GetPersonalAccessTokens(authUserView)
+	ctx.JSON(getPersonalAccessTokensResponse.Code, getPersonalAccessTokensResponse)
+}
+
+func CreatePersonalAccessToken(ctx *gin.Context) {
+	var payload views.CreatePersonalAccessTokenPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	createPersonalAccessTokenResponse := tokenService.CreatePersonalAccessToken(authUserView, payload)
+	if createPersonalAccessTokenResponse.Code == http.StatusOK {
+		token := createPersonalAccessTokenResponse.Token
+		auditService.Record(getAuditOrigin(ctx), auditService.Event{
+			Action:     auditTypes.TokenCreated,
+			TargetType: "personal_access_token",
+			TargetID:   token.ID,
+			Details:    map[string]interface{}{"name": token.Name, "scopes": token.Scopes, "boardId": token.BoardID},
+		})
+	}
+	ctx.JSON(createPersonalAccessTokenResponse.Code, createPersonalAccessTokenResponse)
+}
+
+func RevokePersonalAccessToken(ctx *gin.Context) {
+	authUserView, _ := getAuthUser(ctx)
+	revokePersonalAccessTokenResponse := tokenService.RevokePersonalAccessToken(
+		authUserView,
+		views.RevokePersonalAccessTokenPayload{ID: ctx.Param("token_id")},
+	)
+	if revokePersonalAccessTokenResponse.Code == http.StatusOK {
+		auditService.Record(getAuditOrigin(ctx), auditService.Event{
+			Action:     auditTypes.TokenRevoked,
+			TargetType: "personal_access_token",
+			TargetID:   ctx.Param("token_id"),
+		})
+	}
+	ctx.JSON(revokePersonalAccessTokenResponse.Code, revokePersonalAccessTokenResponse)
+}
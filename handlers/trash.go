@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	trashService "github.com/EmilyOng/tusk-manager/backend/services/trash"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetBoardTrash(ctx *gin.Context) {
+	getBoardTrashResponse := trashService.GetBoardTrash(views.GetBoardTrashPayload{BoardID: ctx.Param("board_id")})
+	ctx.JSON(getBoardTrashResponse.Code, getBoardTrashResponse)
+}
+
+func RestoreTrashItem(ctx *gin.Context) {
+	var payload views.RestoreTrashItemPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	restoreTrashItemResponse := trashService.RestoreTrashItem(getBoardPermissions(ctx), payload)
+	ctx.JSON(restoreTrashItemResponse.Code, restoreTrashItemResponse)
+}
+
+func GetTrashedBoards(ctx *gin.Context) {
+	authUserView, _ := getAuthUser(ctx)
+	getTrashedBoardsResponse := trashService.GetTrashedBoards(views.GetTrashedBoardsPayload{UserID: authUserView.ID})
+	ctx.JSON(getTrashedBoardsResponse.Code, getTrashedBoardsResponse)
+}
+
+func RestoreBoard(ctx *gin.Context) {
+	restoreBoardResponse := trashService.RestoreBoard(getAuditOrigin(ctx), views.RestoreBoardPayload{ID: ctx.Param("board_id")})
+	ctx.JSON(restoreBoardResponse.Code, restoreBoardResponse)
+}
+
+func PurgeBoard(ctx *gin.Context) {
+	purgeBoardResponse := trashService.PurgeBoard(getAuditOrigin(ctx), views.PurgeBoardPayload{ID: ctx.Param("board_id")})
+	ctx.JSON(purgeBoardResponse.Code, purgeBoardResponse)
+}
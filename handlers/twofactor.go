@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	twoFactorService "github.com/EmilyOng/tusk-manager/backend/services/twofactor"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func EnrollTwoFactor(ctx *gin.Context) {
+	authUserView, _ := getAuthUser(ctx)
+	enrollTwoFactorResponse := twoFactorService.Enroll(authUserView)
+	ctx.JSON(enrollTwoFactorResponse.Code, enrollTwoFactorResponse)
+}
+
+func ConfirmTwoFactor(ctx *gin.Context) {
+	var payload views.ConfirmTwoFactorPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	confirmTwoFactorResponse := twoFactorService.Confirm(authUserView, payload)
+	ctx.JSON(confirmTwoFactorResponse.Code, confirmTwoFactorResponse)
+}
+
+func DisableTwoFactor(ctx *gin.Context) {
+	var payload views.DisableTwoFactorPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	disableTwoFactorResponse := twoFactorService.Disable(authUserView, payload)
+	ctx.JSON(disableTwoFactorResponse.Code, disableTwoFactorResponse)
+}
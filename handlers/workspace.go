@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+
+	workspaceService "github.com/EmilyOng/tusk-manager/backend/services/workspace"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetUserWorkspaces(ctx *gin.Context) {
+	authUserView, _ := getAuthUser(ctx)
+	getUserWorkspacesResponse := workspaceService.GetUserWorkspaces(views.GetUserWorkspacesPayload{UserID: authUserView.ID})
+	ctx.JSON(getUserWorkspacesResponse.Code, getUserWorkspacesResponse)
+}
+
+func CreateWorkspace(ctx *gin.Context) {
+	var payload views.CreateWorkspacePayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	authUserView, _ := getAuthUser(ctx)
+	createWorkspaceResponse := workspaceService.CreateWorkspace(authUserView, payload)
+	ctx.JSON(createWorkspaceResponse.Code, createWorkspaceResponse)
+}
+
+func UpdateWorkspace(ctx *gin.Context) {
+	var payload views.UpdateWorkspacePayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.ID = ctx.Param("workspace_id")
+	updateWorkspaceResponse := workspaceService.UpdateWorkspace(payload)
+	ctx.JSON(updateWorkspaceResponse.Code, updateWorkspaceResponse)
+}
+
+func DeleteWorkspace(ctx *gin.Context) {
+	deleteWorkspaceResponse := workspaceService.DeleteWorkspace(
+		getAuditOrigin(ctx),
+		views.DeleteWorkspacePayload{ID: ctx.Param("workspace_id")},
+	)
+	ctx.JSON(deleteWorkspaceResponse.Code, deleteWorkspaceResponse)
+}
+
+func GetWorkspaceMembers(ctx *gin.Context) {
+	getWorkspaceMembersResponse := workspaceService.GetWorkspaceMembers(
+		views.GetWorkspaceMembersPayload{WorkspaceID: ctx.Param("workspace_id")},
+	)
+	ctx.JSON(getWorkspaceMembersResponse.Code, getWorkspaceMembersResponse)
+}
+
+func CreateWorkspaceMember(ctx *gin.Context) {
+	var payload views.CreateWorkspaceMemberPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.WorkspaceID = ctx.Param("workspace_id")
+	createWorkspaceMemberResponse := workspaceService.CreateWorkspaceMember(getAuditOrigin(ctx), payload)
+	ctx.JSON(createWorkspaceMemberResponse.Code, createWorkspaceMemberResponse)
+}
+
+func UpdateWorkspaceMember(ctx *gin.Context) {
+	var payload views.UpdateWorkspaceMemberPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.ID = ctx.Param("member_id")
+	payload.WorkspaceID = ctx.Param("workspace_id")
+	updateWorkspaceMemberResponse := workspaceService.UpdateWorkspaceMember(getAuditOrigin(ctx), payload)
+	ctx.JSON(updateWorkspaceMemberResponse.Code, updateWorkspaceMemberResponse)
+}
+
+func DeleteWorkspaceMember(ctx *gin.Context) {
+	deleteWorkspaceMemberResponse := workspaceService.DeleteWorkspaceMember(
+		getAuditOrigin(ctx),
+		views.DeleteWorkspaceMemberPayload{ID: ctx.Param("member_id"), WorkspaceID: ctx.Param("workspace_id")},
+	)
+	ctx.JSON(deleteWorkspaceMemberResponse.Code, deleteWorkspaceMemberResponse)
+}
+
+func LeaveWorkspace(ctx *gin.Context) {
+	leaveWorkspaceResponse := workspaceService.LeaveWorkspace(
+		getAuditOrigin(ctx),
+		views.LeaveWorkspacePayload{WorkspaceID: ctx.Param("workspace_id")},
+	)
+	ctx.JSON(leaveWorkspaceResponse.Code, leaveWorkspaceResponse)
+}
+
+func MoveBoard(ctx *gin.Context) {
+	var payload views.MoveBoardPayload
+
+	err := ctx.ShouldBindJSON(&payload)
+	if err != nil {
+		ctx.AbortWithStatusJSON(
+			http.StatusBadRequest,
+			views.Response{
+				Message: typeMismatchErrorMessage,
+				Code:    http.StatusBadRequest,
+			},
+		)
+		return
+	}
+
+	payload.BoardID = ctx.Param("board_id")
+	moveBoardResponse := workspaceService.MoveBoard(getAuditOrigin(ctx), payload)
+	ctx.JSON(moveBoardResponse.Code, moveBoardResponse)
+}
@@ -5,6 +5,11 @@ import (
 
 	"github.com/EmilyOng/tusk-manager/backend/db"
 	"github.com/EmilyOng/tusk-manager/backend/router"
+	trashService "github.com/EmilyOng/tusk-manager/backend/services/trash"
+	keyUtils "github.com/EmilyOng/tusk-manager/backend/utils/keys"
+	mailUtils "github.com/EmilyOng/tusk-manager/backend/utils/mail"
+	oidcUtils "github.com/EmilyOng/tusk-manager/backend/utils/oidc"
+	throttleUtils "github.com/EmilyOng/tusk-manager/backend/utils/throttle"
 	"github.com/joho/godotenv"
 )
 
@@ -21,6 +26,27 @@ func main() {
 		log.Fatalln("Unable to setup database", err)
 	}
 
+	// Signing keys setup
+	err = keyUtils.Setup()
+	if err != nil {
+		log.Fatalln("Unable to setup signing keys", err)
+	}
+
+	// Mailer setup
+	err = mailUtils.Setup()
+	if err != nil {
+		log.Fatalln("Unable to setup mailer", err)
+	}
+
+	// Login throttle setup
+	throttleUtils.Setup()
+
+	// Single sign-on setup
+	oidcUtils.Setup()
+
+	// Trash purge setup
+	trashService.Setup()
+
 	// Router setup
 	router := router.Setup()
 	err = router.Run()
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Entry of the audit log, which is chained to the previous entry of its chain by its hash
+type AuditEvent struct {
+	ID         string            `gorm:"primaryKey" json:"id"`
+	Chain      string            `gorm:"uniqueIndex:idx_audit_events_chain_sequence,priority:1;not null;default:''" json:"chain"` // Empty for entries that predate chains
+	Sequence   int64             `gorm:"uniqueIndex:idx_audit_events_chain_sequence,priority:2;not null" json:"sequence"`         // Position in the chain, starting from 1
+	Action     auditTypes.Action `gorm:"index;not null" json:"action"`
+	ActorID    *string           `gorm:"index" json:"actorId"` // User that performed the event, if known
+	TargetType string            `json:"targetType"`
+	TargetID   *string           `gorm:"index" json:"targetId"`
+	BoardID    *string           `gorm:"index" json:"boardId"` // Board that the event belongs to, if any
+	IP         string            `json:"ip"`
+	UserAgent  string            `json:"userAgent"`
+	Details    string            `json:"details"` // JSON-encoded
+	CreatedAt  time.Time         `gorm:"index" json:"createdAt"`
+	PrevHash   string            `json:"prevHash"`
+	Hash       string            `gorm:"not null" json:"hash"`
+}
+
+func (auditEvent *AuditEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(auditEvent.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	auditEvent.ID = uuid.NewString()
+	return
+}
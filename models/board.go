@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	colorTypes "github.com/EmilyOng/tusk-manager/backend/types/color"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,10 +13,17 @@ type Board struct {
 	Name  string           `gorm:"not null" json:"name"`
 	Color colorTypes.Color `gorm:"not null" json:"color" ts_type:"Color"`
 
-	Tasks   []*Task   `gorm:"not null" json:"tasks"`  // Tasks belonging to the board
-	Tags    []*Tag    `gorm:"not null" json:"tags"`   // Tags belonging to the board
-	States  []*State  `gorm:"not null" json:"states"` // States belonging to the board
-	Members []*Member `json:"boardMembers"`           // Members belonging to the board
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Set while the board is in the trash
+
+	WorkspaceID string `gorm:"index" json:"workspaceId"` // Workspace that the board belongs to
+
+	Tasks   []*Task      `gorm:"not null" json:"tasks"`  // Tasks belonging to the board
+	Tags    []*Tag       `gorm:"not null" json:"tags"`   // Tags belonging to the board
+	States  []*State     `gorm:"not null" json:"states"` // States belonging to the board
+	Members []*Member    `json:"boardMembers"`           // Members belonging to the board
+	Teams   []*BoardTeam `json:"boardTeams"`             // Teams that have access to the board
 }
 
 func (board *Board) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Read-only snapshot of a board that anyone with the token can view
+type BoardPublication struct {
+	ID            string    `gorm:"primaryKey" json:"id"`
+	TokenHash     string    `gorm:"not null;uniqueIndex" json:"-"`
+	HiddenTagIDs  string    `json:"hiddenTagIds"`  // Space-separated
+	HiddenTaskIDs string    `json:"hiddenTaskIds"` // Space-separated
+	CreatedAt     time.Time `json:"createdAt"`
+
+	BoardID string `gorm:"not null;uniqueIndex" json:"boardId"`
+}
+
+func (boardPublication *BoardPublication) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(boardPublication.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	boardPublication.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role defined by a workspace or board, which grants its permissions on top of the preset role
+type CustomRole struct {
+	ID          string    `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"not null" json:"name"`
+	Permissions string    `gorm:"not null" json:"permissions"` // Space-separated permissions
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+
+	WorkspaceID *string `gorm:"index" json:"workspaceId"` // Workspace whose boards can assign the role, if any
+	BoardID     *string `gorm:"index" json:"boardId"`     // Board that can assign the role, if any
+}
+
+func (customRole *CustomRole) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(customRole.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	customRole.ID = uuid.NewString()
+	return
+}
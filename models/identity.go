@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Links a user to the subject of an external identity provider
+type UserIdentity struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	Issuer    string    `gorm:"not null;uniqueIndex:idx_user_identity_subject" json:"issuer"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_user_identity_subject" json:"subject"`
+	Email     string    `json:"email"` // Email reported by the identity provider
+	CreatedAt time.Time `json:"createdAt"`
+
+	UserID string `gorm:"not null;index" json:"userId"`
+}
+
+func (identity *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(identity.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	identity.ID = uuid.NewString()
+	return
+}
+
+// A pending authorization code flow, keyed by its state
+type OIDCAuthRequest struct {
+	State        string    `gorm:"primaryKey" json:"-"`
+	Nonce        string    `gorm:"not null" json:"-"`
+	CodeVerifier string    `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null" json:"expiresAt"`
+}
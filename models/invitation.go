@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	invitationTypes "github.com/EmilyOng/tusk-manager/backend/types/invitation"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invitation to a board for someone who does not have an account yet
+type Invitation struct {
+	ID          string                 `gorm:"primaryKey" json:"id"`
+	Email       string                 `gorm:"not null;index" json:"email"` // Lower-cased
+	Role        roleTypes.Role         `gorm:"not null" json:"role" ts_type:"Role"`
+	Status      invitationTypes.Status `gorm:"not null;index" json:"status"`
+	TokenHash   string                 `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt   time.Time              `gorm:"not null" json:"expiresAt"`
+	RespondedAt *time.Time             `json:"respondedAt"`
+	CreatedAt   time.Time              `json:"createdAt"`
+
+	BoardID   string `gorm:"not null;index" json:"boardId"`
+	InviterID string `gorm:"not null" json:"inviterId"` // User that sent the invitation
+}
+
+func (invitation *Invitation) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(invitation.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	invitation.ID = uuid.NewString()
+	return
+}
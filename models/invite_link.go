@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Shareable link that lets any authenticated user join a board
+type InviteLink struct {
+	ID        string         `gorm:"primaryKey" json:"id"`
+	Role      roleTypes.Role `gorm:"not null" json:"role" ts_type:"Role"`
+	TokenHash string         `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time      `gorm:"not null" json:"expiresAt"`
+	MaxUses   *int           `json:"maxUses"` // Unlimited when empty
+	Uses      int            `gorm:"not null;default:0" json:"uses"`
+	RevokedAt *time.Time     `json:"revokedAt"`
+	CreatedAt time.Time      `json:"createdAt"`
+
+	BoardID     string `gorm:"not null;index" json:"boardId"`
+	CreatedByID string `gorm:"not null" json:"createdById"`
+}
+
+func (inviteLink *InviteLink) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(inviteLink.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	inviteLink.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Tracks failed attempts for a throttled key (e.g. an account or a client IP)
+type LoginAttempt struct {
+	Key          string     `gorm:"primaryKey" json:"key"`
+	Failures     int        `gorm:"not null" json:"failures"`
+	LastFailedAt time.Time  `gorm:"not null" json:"lastFailedAt"`
+	LockedUntil  *time.Time `json:"lockedUntil"`
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -10,9 +12,13 @@ type Member struct {
 	ID   string         `gorm:"primary_key" json:"id"`
 	Role roleTypes.Role `gorm:"not null" json:"role" ts_type:"Role"`
 
-	UserID  string `json:"userId"` // User ID of the board member
-	User    *User  `json:"user"`
-	BoardID string `json:"boardId"` // Board that the member belongs to
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID       string  `json:"userId"` // User ID of the board member
+	User         *User   `json:"user"`
+	BoardID      string  `json:"boardId"`      // Board that the member belongs to
+	CustomRoleID *string `json:"customRoleId"` // Grants additional permissions, if any
 }
 
 func (member *Member) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessToken struct {
+	ID         string     `gorm:"primaryKey" json:"id"`
+	Name       string     `gorm:"not null" json:"name"`
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"not null" json:"scopes"` // Space-separated scopes
+	ExpiresAt  *time.Time `json:"expiresAt"`              // Never expires if empty
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+
+	UserID  string  `gorm:"not null;index" json:"userId"` // User that the token acts on behalf of
+	BoardID *string `json:"boardId"`                      // Board that the token is restricted to, if any
+}
+
+func (token *PersonalAccessToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(token.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	token.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// A single-use code to complete two-factor authentication without the authenticator app
+type RecoveryCode struct {
+	ID       string     `gorm:"primaryKey" json:"id"`
+	CodeHash string     `gorm:"not null;index" json:"-"`
+	UsedAt   *time.Time `json:"usedAt"`
+
+	UserID string `gorm:"not null;index" json:"userId"`
+}
+
+func (recoveryCode *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(recoveryCode.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	recoveryCode.ID = uuid.NewString()
+	return
+}
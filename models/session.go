@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// A login session, which groups the family of refresh tokens rotated from the same login
+type Session struct {
+	ID        string     `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt"` // Revoked sessions reject their access and refresh tokens
+
+	UserID        string          `gorm:"not null;index" json:"userId"` // User that the session belongs to
+	RefreshTokens []*RefreshToken `json:"refreshTokens"`
+}
+
+func (session *Session) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(session.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	session.ID = uuid.NewString()
+	return
+}
+
+type RefreshToken struct {
+	ID        string     `gorm:"primaryKey" json:"id"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"` // Set once the token has been rotated
+
+	SessionID string `gorm:"not null;index" json:"sessionId"` // Session that the token belongs to
+}
+
+func (refreshToken *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(refreshToken.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	refreshToken.ID = uuid.NewString()
+	return
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -10,6 +12,10 @@ type State struct {
 	Name            string `gorm:"not null" json:"name"`
 	CurrentPosition int    `gorm:"not null" json:"currentPosition"` // Sort key
 
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Set while the state is in the trash
+
 	Tasks   []*Task `gorm:"not null" json:"tasks"` // Tasks belonging to the state
 	BoardID string  `json:"boardId"`               // Board that the state belongs to
 }
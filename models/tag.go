@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	colorTypes "github.com/EmilyOng/tusk-manager/backend/types/color"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,6 +13,10 @@ type Tag struct {
 	Name  string           `gorm:"not null" json:"name"`
 	Color colorTypes.Color `gorm:"not null" json:"color" ts_type:"Color"`
 
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Set while the tag is in the trash
+
 	Tasks   []*Task `gorm:"many2many:task_tags" json:"tasks"`
 	BoardID string  `json:"boardId"` // Board that the tag belongs to
 }
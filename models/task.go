@@ -12,11 +12,25 @@ type Task struct {
 	Name        string     `gorm:"not null" json:"name"`
 	Description string     `gorm:"default:''" json:"description"`
 	DueAt       *time.Time `json:"dueAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	Position    string     `gorm:"not null;default:'';index:idx_task_position,priority:2" json:"position"` // Sort key within the state
 
-	Tags    []*Tag `gorm:"many2many:task_tags" json:"tags"`
-	UserID  string `json:"userId"`                  // Owner of the task
-	BoardID string `json:"boardId"`                 // Board that the task belongs to
-	StateID string `gorm:"not null" json:"stateId"` // State that the task is at
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Set while the task is in the trash
+
+	Tags      []*Tag          `gorm:"many2many:task_tags" json:"tags"`
+	Assignees []*TaskAssignee `json:"assignees"`                                                  // Board members that the task is assigned to
+	UserID    string          `json:"userId"`                                                     // Creator of the task, which cannot be changed
+	BoardID   string          `json:"boardId"`                                                    // Board that the task belongs to
+	StateID   string          `gorm:"not null;index:idx_task_position,priority:1" json:"stateId"` // State that the task is at
+}
+
+// Assignment of a task to a member of its board
+type TaskAssignee struct {
+	TaskID string `gorm:"primaryKey" json:"taskId"`
+	UserID string `gorm:"primaryKey;index" json:"userId"` // User ID of a user who can access the board
+
+	CreatedAt time.Time `json:"createdAt"` // When the task was assigned
 }
 
 func (task *Task) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Named group of workspace members, which can be given access to the boards of the workspace
+type Team struct {
+	ID          string `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"not null" json:"name"`
+	WorkspaceID string `gorm:"not null;index" json:"workspaceId"` // Workspace that the team belongs to
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Members []*TeamMember `json:"members"` // Members belonging to the team
+	Boards  []*BoardTeam  `json:"boards"`  // Boards that the team has access to
+}
+
+func (team *Team) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(team.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	team.ID = uuid.NewString()
+	return
+}
+
+type TeamMember struct {
+	ID string `gorm:"primaryKey" json:"id"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID string `gorm:"not null;uniqueIndex:idx_team_member" json:"userId"`
+	User   *User  `json:"user"`
+	TeamID string `gorm:"not null;uniqueIndex:idx_team_member" json:"teamId"`
+}
+
+func (teamMember *TeamMember) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(teamMember.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	teamMember.ID = uuid.NewString()
+	return
+}
+
+// Role that every member of the team holds on the board
+type BoardTeam struct {
+	ID   string         `gorm:"primaryKey" json:"id"`
+	Role roleTypes.Role `gorm:"not null" json:"role" ts_type:"Role"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	TeamID       string  `gorm:"not null;uniqueIndex:idx_board_team" json:"teamId"`
+	Team         *Team   `json:"team"`
+	BoardID      string  `gorm:"not null;uniqueIndex:idx_board_team" json:"boardId"`
+	CustomRoleID *string `json:"customRoleId"` // Grants additional permissions, if any
+}
+
+func (boardTeam *BoardTeam) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(boardTeam.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	boardTeam.ID = uuid.NewString()
+	return
+}
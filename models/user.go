@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -10,9 +12,21 @@ type User struct {
 	Name     string `gorm:"not null" json:"name"`
 	Email    string `gorm:"not null" json:"email"`
 	Password string `gorm:"not null" json:"password"`
+	IsAdmin  bool   `gorm:"not null;default:false" json:"isAdmin"` // Granted directly in the database
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	EmailVerified   bool       `gorm:"not null;default:false" json:"emailVerified"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	PendingEmail    string     `json:"pendingEmail"` // Replaces the email once it is verified
+
+	TOTPEnabled  bool   `gorm:"not null;default:false" json:"totpEnabled"`
+	TOTPSecret   string `json:"-"` // Pending until two-factor authentication is confirmed
+	TOTPLastStep int64  `json:"-"` // Last time step used, so that codes cannot be replayed
 
 	Members []*Member `json:"boardMembers"` // Boards that the user can access
-	Tasks   []*Task   `json:"tasks"`        // Tasks that the user owns
+	Tasks   []*Task   `json:"tasks"`        // Tasks that the user created
 }
 
 func (user *User) BeforeCreate(tx *gorm.DB) (err error) {
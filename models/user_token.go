@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	tokenTypes "github.com/EmilyOng/tusk-manager/backend/types/token"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// A hashed, single-use and expiring token sent to the user (e.g. for password resets)
+type UserToken struct {
+	ID        string             `gorm:"primaryKey" json:"id"`
+	Purpose   tokenTypes.Purpose `gorm:"not null" json:"purpose"`
+	TokenHash string             `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time          `gorm:"not null" json:"expiresAt"`
+	UsedAt    *time.Time         `json:"usedAt"`
+	CreatedAt time.Time          `json:"createdAt"`
+
+	UserID string `gorm:"not null;index" json:"userId"` // User that the token is issued to
+}
+
+func (userToken *UserToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(userToken.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	userToken.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Groups boards, whose access can be managed together
+type Workspace struct {
+	ID     string  `gorm:"primaryKey" json:"id"`
+	Name   string  `gorm:"not null" json:"name"`
+	UserID *string `gorm:"uniqueIndex" json:"userId"` // User of a personal workspace, empty for shared workspaces
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	Boards  []*Board           `json:"boards"`  // Boards belonging to the workspace
+	Members []*WorkspaceMember `json:"members"` // Members belonging to the workspace
+}
+
+func (workspace *Workspace) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(workspace.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	workspace.ID = uuid.NewString()
+	return
+}
+
+// Creates the workspace that holds the boards of the user, which do not belong to a shared workspace
+func NewPersonalWorkspace(userID string) Workspace {
+	return Workspace{
+		Name:   "Personal",
+		UserID: &userID,
+		Members: []*WorkspaceMember{{
+			Role:   workspaceTypes.Admin,
+			UserID: userID,
+		}},
+	}
+}
+
+type WorkspaceMember struct {
+	ID   string              `gorm:"primaryKey" json:"id"`
+	Role workspaceTypes.Role `gorm:"not null" json:"role" ts_type:"WorkspaceRole"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	UserID      string `gorm:"not null;uniqueIndex:idx_workspace_member" json:"userId"`
+	User        *User  `json:"user"`
+	WorkspaceID string `gorm:"not null;uniqueIndex:idx_workspace_member" json:"workspaceId"`
+}
+
+func (workspaceMember *WorkspaceMember) BeforeCreate(tx *gorm.DB) (err error) {
+	if len(workspaceMember.ID) > 0 {
+		return
+	}
+	// Generates a new UUID
+	workspaceMember.ID = uuid.NewString()
+	return
+}
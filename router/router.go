@@ -1,14 +1,25 @@
 package router
 
 import (
+	"log"
+
 	"github.com/EmilyOng/tusk-manager/backend/constants"
 	"github.com/EmilyOng/tusk-manager/backend/handlers"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	scopeTypes "github.com/EmilyOng/tusk-manager/backend/types/scope"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func Setup() (router *gin.Engine) {
 	router = gin.Default()
+	// Client IPs are only taken from the forwarding headers of trusted proxies, so that they cannot be spoofed
+	err := router.SetTrustedProxies(commonUtils.GetEnvList("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalln("Invalid trusted proxies", err)
+	}
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{constants.FrontendLocalHostUrl, constants.FrontendProductionUrl},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
@@ -18,52 +29,160 @@ func Setup() (router *gin.Engine) {
 
 	router.Use(handlers.SetAuthUser)
 
+	router.GET("/.well-known/jwks.json", handlers.GetJWKS)
+
 	api := router.Group("/api")
 	{
 		auth := api.Group("/auth")
 		{
 			auth.POST("/login", handlers.Login)
+			auth.POST("/login/2fa", handlers.LoginTwoFactor)
 			auth.POST("/signup", handlers.SignUp)
 			auth.POST("/logout", handlers.Logout)
+			auth.POST("/refresh", handlers.Refresh)
+			auth.POST("/password/forgot", handlers.ForgotPassword)
+			auth.POST("/password/reset", handlers.ResetPassword)
+			auth.POST("/verify", handlers.VerifyEmail)
+			auth.POST("/verify/resend", handlers.ResendVerificationEmail)
+			auth.POST("/email/confirm", handlers.ConfirmEmailChange)
+			auth.GET("/oidc/login", handlers.OIDCLogin)
+			auth.POST("/oidc/callback", handlers.OIDCCallback)
 			auth.GET("/", handlers.IsAuthenticated)
+
+			twoFactor := auth.Group("/2fa", handlers.AuthGuard, handlers.RequireSession)
+			{
+				twoFactor.POST("/enroll", handlers.EnrollTwoFactor)
+				twoFactor.POST("/confirm", handlers.ConfirmTwoFactor)
+				twoFactor.POST("/disable", handlers.DisableTwoFactor)
+			}
 		}
+		// Invitees can decline without an account
+		api.POST("/invitations/decline", handlers.DeclineInvitation)
+		api.GET("/public/boards/:token", handlers.GetPublicBoard)
+
 		guard := api.Group("/", handlers.AuthGuard)
 		{
 			states := guard.Group("/states")
 			{
-				states.POST("/", handlers.CreateState)
-				states.PUT("/", handlers.UpdateState)
-				states.DELETE("/:state_id", handlers.DeleteState)
+				states.POST("/", handlers.Authorize(permissionTypes.ManageStates, scopeTypes.StatesWrite, handlers.FromBoardIDPayload), handlers.CreateState)
+				states.PUT("/", handlers.Authorize(permissionTypes.ManageStates, scopeTypes.StatesWrite, handlers.FromStatePayload), handlers.UpdateState)
+				states.DELETE("/:state_id", handlers.Authorize(permissionTypes.ManageStates, scopeTypes.StatesWrite, handlers.FromStateParam), handlers.DeleteState)
 			}
 			boards := guard.Group("/boards")
 			{
-				boards.GET("/", handlers.GetUserBoards)
-				boards.PUT("/", handlers.UpdateBoard)
-				boards.DELETE("/:board_id", handlers.DeleteBoard)
-				boards.GET("/:board_id", handlers.GetBoard)
-				boards.POST("/", handlers.CreateBoard)
-				boards.GET("/:board_id/tasks", handlers.GetBoardTasks)
-				boards.GET("/:board_id/tags", handlers.GetBoardTags)
-				boards.GET("/:board_id/states", handlers.GetBoardStates)
-				boards.GET("/:board_id/members", handlers.GetBoardMemberProfiles)
+				viewer := func(scope scopeTypes.Scope) gin.HandlerFunc {
+					return handlers.Authorize(permissionTypes.ViewBoard, scope, handlers.FromBoardParam("board_id"))
+				}
+				boards.GET("/", handlers.RequireScope(scopeTypes.BoardsRead), handlers.GetUserBoards)
+				boards.PUT("/", handlers.Authorize(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardPayload), handlers.UpdateBoard)
+				boards.DELETE("/:board_id", handlers.Authorize(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.DeleteBoard)
+				boards.GET("/:board_id", viewer(scopeTypes.BoardsRead), handlers.GetBoard)
+				boards.POST("/", handlers.RequireScope(scopeTypes.BoardsWrite), handlers.CreateBoard)
+				boards.GET("/:board_id/tasks", viewer(scopeTypes.TasksRead), handlers.GetBoardTasks)
+				boards.GET("/:board_id/tags", viewer(scopeTypes.TagsRead), handlers.GetBoardTags)
+				boards.GET("/:board_id/states", viewer(scopeTypes.StatesRead), handlers.GetBoardStates)
+				boards.PUT("/:board_id/states", handlers.Authorize(permissionTypes.ManageStates, scopeTypes.StatesWrite, handlers.FromBoardParam("board_id")), handlers.ReorderStates)
+				boards.GET("/:board_id/members", viewer(scopeTypes.MembersRead), handlers.GetBoardMemberProfiles)
+				boards.GET("/:board_id/permissions", viewer(scopeTypes.BoardsRead), handlers.GetBoardPermissions)
+				boards.GET("/:board_id/roles", viewer(scopeTypes.MembersRead), handlers.GetCustomRoles)
+				boards.POST("/:board_id/roles", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.CreateCustomRole)
+				boards.PUT("/:board_id/roles/:role_id", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.UpdateCustomRole)
+				boards.DELETE("/:board_id/roles/:role_id", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.DeleteCustomRole)
+				boards.GET("/:board_id/teams", viewer(scopeTypes.MembersRead), handlers.GetBoardTeams)
+				boards.POST("/:board_id/teams", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.CreateBoardTeam)
+				boards.PUT("/:board_id/teams/:board_team_id", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.UpdateBoardTeam)
+				boards.DELETE("/:board_id/teams/:board_team_id", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.DeleteBoardTeam)
+				boards.GET("/:board_id/invitations", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersRead, handlers.FromBoardParam("board_id")), handlers.GetBoardInvitations)
+				boards.GET("/:board_id/links", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersRead, handlers.FromBoardParam("board_id")), handlers.GetInviteLinks)
+				boards.POST("/:board_id/links", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.CreateInviteLink)
+				boards.DELETE("/:board_id/links/:link_id", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.RevokeInviteLink)
+				boards.GET("/:board_id/publication", handlers.Authorize(permissionTypes.ManageBoard, scopeTypes.BoardsRead, handlers.FromBoardParam("board_id")), handlers.GetBoardPublication)
+				boards.POST("/:board_id/publication", handlers.Authorize(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.PublishBoard)
+				boards.PUT("/:board_id/publication", handlers.Authorize(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.UpdateBoardPublication)
+				boards.DELETE("/:board_id/publication", handlers.Authorize(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.UnpublishBoard)
+				boards.POST("/:board_id/move", handlers.Authorize(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.MoveBoard)
+				boards.POST("/:board_id/transfer", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardParam("board_id")), handlers.TransferOwnership)
+				boards.POST("/:board_id/leave", viewer(scopeTypes.MembersWrite), handlers.LeaveBoard)
+				boards.GET("/:board_id/audit", handlers.Authorize(permissionTypes.ViewAudit, scopeTypes.AuditRead, handlers.FromBoardParam("board_id")), handlers.GetBoardAuditEvents)
+				boards.GET("/:board_id/trash", viewer(scopeTypes.BoardsRead), handlers.GetBoardTrash)
+				boards.POST("/:board_id/trash/restore", handlers.AuthorizeAny([]permissionTypes.Permission{permissionTypes.DeleteTasks, permissionTypes.ManageTags, permissionTypes.ManageStates}, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.RestoreTrashItem)
+				boards.GET("/trash", handlers.RequireScope(scopeTypes.BoardsRead), handlers.GetTrashedBoards)
+				boards.POST("/trash/:board_id/restore", handlers.AuthorizeTrashed(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.RestoreBoard)
+				boards.DELETE("/trash/:board_id", handlers.AuthorizeTrashed(permissionTypes.ManageBoard, scopeTypes.BoardsWrite, handlers.FromBoardParam("board_id")), handlers.PurgeBoard)
+			}
+			workspaces := guard.Group("/workspaces")
+			{
+				admin := func(scope scopeTypes.Scope) gin.HandlerFunc {
+					return handlers.AuthorizeWorkspace(workspaceTypes.Admin, scope, "workspace_id")
+				}
+				workspaces.GET("/", handlers.RequireScope(scopeTypes.WorkspacesRead), handlers.GetUserWorkspaces)
+				workspaces.POST("/", handlers.RequireScope(scopeTypes.WorkspacesWrite), handlers.CreateWorkspace)
+				workspaces.PUT("/:workspace_id", admin(scopeTypes.WorkspacesWrite), handlers.UpdateWorkspace)
+				workspaces.DELETE("/:workspace_id", admin(scopeTypes.WorkspacesWrite), handlers.DeleteWorkspace)
+				workspaces.GET("/:workspace_id/members", handlers.AuthorizeWorkspace(workspaceTypes.Member, scopeTypes.WorkspacesRead, "workspace_id"), handlers.GetWorkspaceMembers)
+				workspaces.POST("/:workspace_id/members", admin(scopeTypes.WorkspacesWrite), handlers.CreateWorkspaceMember)
+				workspaces.PUT("/:workspace_id/members/:member_id", admin(scopeTypes.WorkspacesWrite), handlers.UpdateWorkspaceMember)
+				workspaces.DELETE("/:workspace_id/members/:member_id", admin(scopeTypes.WorkspacesWrite), handlers.DeleteWorkspaceMember)
+				workspaces.GET("/:workspace_id/teams", handlers.AuthorizeWorkspace(workspaceTypes.Member, scopeTypes.WorkspacesRead, "workspace_id"), handlers.GetWorkspaceTeams)
+				workspaces.POST("/:workspace_id/teams", admin(scopeTypes.WorkspacesWrite), handlers.CreateTeam)
+				workspaces.PUT("/:workspace_id/teams/:team_id", admin(scopeTypes.WorkspacesWrite), handlers.UpdateTeam)
+				workspaces.DELETE("/:workspace_id/teams/:team_id", admin(scopeTypes.WorkspacesWrite), handlers.DeleteTeam)
+				workspaces.POST("/:workspace_id/teams/:team_id/members", admin(scopeTypes.WorkspacesWrite), handlers.CreateTeamMember)
+				workspaces.DELETE("/:workspace_id/teams/:team_id/members/:user_id", admin(scopeTypes.WorkspacesWrite), handlers.DeleteTeamMember)
+				workspaces.GET("/:workspace_id/roles", handlers.AuthorizeWorkspace(workspaceTypes.Member, scopeTypes.WorkspacesRead, "workspace_id"), handlers.GetCustomRoles)
+				workspaces.POST("/:workspace_id/roles", admin(scopeTypes.WorkspacesWrite), handlers.CreateCustomRole)
+				workspaces.PUT("/:workspace_id/roles/:role_id", admin(scopeTypes.WorkspacesWrite), handlers.UpdateCustomRole)
+				workspaces.DELETE("/:workspace_id/roles/:role_id", admin(scopeTypes.WorkspacesWrite), handlers.DeleteCustomRole)
+				workspaces.POST("/:workspace_id/leave", handlers.AuthorizeWorkspace(workspaceTypes.Member, scopeTypes.WorkspacesWrite, "workspace_id"), handlers.LeaveWorkspace)
 			}
 			tasks := guard.Group("/tasks")
 			{
-				tasks.POST("/", handlers.CreateTask)
-				tasks.PUT("/", handlers.UpdateTask)
-				tasks.DELETE("/:task_id", handlers.DeleteTask)
+				tasks.POST("/", handlers.Authorize(permissionTypes.CreateTasks, scopeTypes.TasksWrite, handlers.FromBoardIDPayload), handlers.CreateTask)
+				tasks.PUT("/", handlers.AuthorizeAny([]permissionTypes.Permission{permissionTypes.EditTasks, permissionTypes.MoveTasks}, scopeTypes.TasksWrite, handlers.FromTaskPayload), handlers.UpdateTask)
+				tasks.POST("/:task_id/move", handlers.Authorize(permissionTypes.MoveTasks, scopeTypes.TasksWrite, handlers.FromTaskParam), handlers.MoveTask)
+				tasks.DELETE("/:task_id", handlers.Authorize(permissionTypes.DeleteTasks, scopeTypes.TasksWrite, handlers.FromTaskParam), handlers.DeleteTask)
+				tasks.POST("/:task_id/assignees", handlers.Authorize(permissionTypes.EditTasks, scopeTypes.TasksWrite, handlers.FromTaskParam), handlers.AssignTask)
+				tasks.DELETE("/:task_id/assignees/:user_id", handlers.Authorize(permissionTypes.EditTasks, scopeTypes.TasksWrite, handlers.FromTaskParam), handlers.UnassignTask)
 			}
 			tags := guard.Group("/tags")
 			{
-				tags.POST("/", handlers.CreateTag)
-				tags.DELETE("/:tag_id", handlers.DeleteTag)
-				tags.PUT("/", handlers.UpdateTag)
+				tags.POST("/", handlers.Authorize(permissionTypes.ManageTags, scopeTypes.TagsWrite, handlers.FromBoardIDPayload), handlers.CreateTag)
+				tags.DELETE("/:tag_id", handlers.Authorize(permissionTypes.ManageTags, scopeTypes.TagsWrite, handlers.FromTagParam), handlers.DeleteTag)
+				tags.PUT("/", handlers.Authorize(permissionTypes.ManageTags, scopeTypes.TagsWrite, handlers.FromTagPayload), handlers.UpdateTag)
 			}
 			members := guard.Group("/members")
 			{
-				members.POST("/", handlers.CreateMember)
-				members.PUT("/", handlers.UpdateMember)
-				members.DELETE("/:member_id", handlers.DeleteMember)
+				members.POST("/", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromBoardIDPayload), handlers.CreateMember)
+				members.PUT("/", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromMemberPayload), handlers.UpdateMember)
+				members.DELETE("/:member_id", handlers.Authorize(permissionTypes.ManageMembers, scopeTypes.MembersWrite, handlers.FromMemberParam), handlers.DeleteMember)
+			}
+			guard.GET("/permissions", handlers.GetPermissionCatalogue)
+			account := guard.Group("/account", handlers.RequireSession)
+			{
+				account.PUT("/profile", handlers.UpdateProfile)
+				account.POST("/password", handlers.ChangePassword)
+				account.POST("/email", handlers.ChangeEmail)
+				account.GET("/export", handlers.ExportAccount)
+				account.POST("/delete", handlers.DeleteAccount)
+			}
+			audit := guard.Group("/audit", handlers.RequireSession, handlers.RequireAdmin)
+			{
+				audit.GET("/", handlers.GetAuditEvents)
+				audit.GET("/verify", handlers.VerifyAuditLog)
+			}
+			invitations := guard.Group("/invitations", handlers.RequireSession)
+			{
+				invitations.POST("/accept", handlers.AcceptInvitation)
+			}
+			links := guard.Group("/links", handlers.RequireSession)
+			{
+				links.POST("/redeem", handlers.RedeemInviteLink)
+			}
+			tokens := guard.Group("/tokens", handlers.RequireSession)
+			{
+				tokens.GET("/", handlers.GetPersonalAccessTokens)
+				tokens.POST("/", handlers.CreatePersonalAccessToken)
+				tokens.DELETE("/:token_id", handlers.RevokePersonalAccessToken)
 			}
 		}
 	}
@@ -0,0 +1,1096 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	boardService "github.com/EmilyOng/tusk-manager/backend/services/board"
+	invitationService "github.com/EmilyOng/tusk-manager/backend/services/invitation"
+	sessionService "github.com/EmilyOng/tusk-manager/backend/services/session"
+	tokenService "github.com/EmilyOng/tusk-manager/backend/services/token"
+	userService "github.com/EmilyOng/tusk-manager/backend/services/user"
+	workspaceService "github.com/EmilyOng/tusk-manager/backend/services/workspace"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	tokenTypes "github.com/EmilyOng/tusk-manager/backend/types/token"
+	verificationTypes "github.com/EmilyOng/tusk-manager/backend/types/verification"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
+	mailUtils "github.com/EmilyOng/tusk-manager/backend/utils/mail"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	passwordResetDuration     = time.Hour
+	emailVerificationDuration = 7 * 24 * time.Hour
+	// How recent the login of a user without a password must be to change the account
+	reauthenticationWindow = 10 * time.Minute
+
+	passwordResetRequestedMessage = "If an account exists for '%s', a password reset link has been sent."
+	invalidPasswordResetMessage   = "The password reset link is invalid or has expired."
+	emptyPasswordMessage          = "The password cannot be empty."
+	unableToResetPasswordMessage  = "Unable to reset the password."
+
+	verificationRequestedMessage    = "If '%s' has not been verified, a verification link has been sent."
+	invalidEmailVerificationMessage = "The verification link is invalid or has expired."
+	unableToVerifyEmailMessage      = "Unable to verify the email."
+
+	unableToExportAccountMessage = "Unable to export your data."
+	unableToDeleteAccountMessage = "Unable to delete your account."
+	accountMismatchMessage       = "The email does not match your account."
+	invalidPasswordMessage       = "The password is incorrect, please try again."
+	reauthenticationMessage      = "Please log in again with single sign-on to confirm that it is you."
+	soleOwnerMessage             = "You are the only owner of %d board(s), please transfer the ownership or delete them first."
+	soleAdminMessage             = "You are the only admin of %d workspace(s), please promote another member or delete them first."
+
+	emptyNameMessage               = "The name cannot be empty."
+	emptyEmailMessage              = "The email cannot be empty."
+	emailTakenMessage              = "The email '%s' is already in use."
+	sameEmailMessage               = "The email is the same as your current email."
+	invalidEmailChangeMessage      = "The confirmation link is invalid or has expired."
+	unableToUpdateProfileMessage   = "Unable to update your profile."
+	unableToChangePasswordMessage  = "Unable to change your password."
+	unableToChangeEmailMessage     = "Unable to change your email."
+	unableToGenerateSessionMessage = "Unable to generate authentication token."
+
+	successfullyResetPasswordMessage   = "Your password has been reset, please log in again."
+	successfullyVerifiedEmailMessage   = "Your email '%s' has been verified!"
+	successfullyDeletedAccountMessage  = "Your account has been deleted. Goodbye!"
+	successfullyUpdatedProfileMessage  = "Successfully updated your profile!"
+	successfullyChangedPasswordMessage = "Your password has been changed, and your other sessions have been logged out."
+	emailChangeRequestedMessage        = "A confirmation link has been sent to '%s'. Your email changes once it is confirmed."
+	successfullyChangedEmailMessage    = "Your email has been changed to '%s', please log in again."
+
+	passwordResetSubject = "Reset your Tusk password"
+	passwordResetBody    = "Hi %s,\n\nReset your password within the next hour using the link below:\n%s\n\nIf you did not request this, you can ignore this email."
+
+	emailChangeSubject = "Confirm your new Tusk email"
+	emailChangeBody    = "Hi %s,\n\nConfirm that you want to use this email address for Tusk using the link below:\n%s\n\nIf you did not request this, you can ignore this email."
+
+	emailChangedSubject = "Your Tusk email has been changed"
+	emailChangedBody    = "Hi %s,\n\nThe email of your Tusk account has been changed to '%s'. If you did not make this change, please contact us immediately."
+
+	emailVerificationSubject = "Verify your Tusk email"
+	emailVerificationBody    = "Hi %s,\n\nConfirm your email address using the link below:\n%s\n\nIf you did not sign up for Tusk, you can ignore this email."
+)
+
+var (
+	errInvalidUserToken = errors.New("invalid user token")
+	errSoleOwner        = errors.New("the user is the only owner of boards")
+	errSoleAdmin        = errors.New("the user is the only admin of workspaces")
+	errEmailTaken       = errors.New("the email is already in use")
+	errStaleLogin       = errors.New("the login is not recent enough")
+	errInvalidPassword  = errors.New("the password is incorrect")
+)
+
+// Retrieves the policy from the 'EMAIL_VERIFICATION_POLICY' environment variable
+func GetVerificationPolicy() verificationTypes.Policy {
+	return verificationTypes.Policy(commonUtils.GetEnv("EMAIL_VERIFICATION_POLICY", string(verificationTypes.None)))
+}
+
+// Issues a single-use token to the user, invalidating any unused tokens for the same purpose
+func IssueUserToken(tx *gorm.DB, userID string, purpose tokenTypes.Purpose, duration time.Duration) (string, error) {
+	err := tx.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).
+		Error
+	if err != nil {
+		return "", err
+	}
+
+	token, hash, err := authUtils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	userToken := models.UserToken{
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(duration),
+		UserID:    userID,
+	}
+	err = tx.Create(&userToken).Error
+	return token, err
+}
+
+// Marks the token as used, provided that it is valid for the purpose
+func ConsumeUserToken(tx *gorm.DB, token string, purpose tokenTypes.Purpose) (userToken models.UserToken, err error) {
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", authUtils.HashOpaqueToken(token), purpose).
+		First(&userToken).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = errInvalidUserToken
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	if userToken.UsedAt != nil || userToken.ExpiresAt.Before(time.Now()) {
+		err = errInvalidUserToken
+		return
+	}
+
+	err = tx.Model(&userToken).Update("used_at", time.Now()).Error
+	return
+}
+
+// Sends a password reset link. Neither the response nor its timing reveals whether the account exists,
+// as the account is looked up and the email is sent in the background.
+func ForgotPassword(payload views.ForgotPasswordPayload) views.ForgotPasswordResponse {
+	go sendPasswordReset(payload.Email)
+	return views.ForgotPasswordResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(passwordResetRequestedMessage, payload.Email),
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+func sendPasswordReset(email string) {
+	user, err := userService.FindUser(email)
+	if err != nil {
+		return
+	}
+
+	var token string
+	err = db.DB.Transaction(func(tx *gorm.DB) (err error) {
+		token, err = IssueUserToken(tx, user.ID, tokenTypes.PasswordReset, passwordResetDuration)
+		return
+	})
+	if err != nil {
+		log.Println("Unable to issue password reset token", err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", commonUtils.GetFrontendUrl(), token)
+	err = mailUtils.Send(mailUtils.Message{
+		To:      user.Email,
+		Subject: passwordResetSubject,
+		Body:    fmt.Sprintf(passwordResetBody, user.Name, link),
+	})
+	if err != nil {
+		log.Println("Unable to send password reset email", err)
+	}
+}
+
+// Resets the password using a reset token, and logs the user out of every session
+func ResetPassword(payload views.ResetPasswordPayload) views.ResetPasswordResponse {
+	if len(payload.Password) == 0 {
+		return views.ResetPasswordResponse{
+			Response: views.Response{
+				Message: emptyPasswordMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	hashedPassword, err := authUtils.HashPassword(payload.Password)
+	if err != nil {
+		return views.ResetPasswordResponse{
+			Response: views.Response{
+				Message: unableToResetPasswordMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		userToken, err := ConsumeUserToken(tx, payload.Token, tokenTypes.PasswordReset)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.User{ID: userToken.UserID}).Update("password", hashedPassword).Error
+		if err != nil {
+			return err
+		}
+
+		return sessionService.RevokeUserSessions(tx, userToken.UserID)
+	})
+
+	if errors.Is(err, errInvalidUserToken) {
+		return views.ResetPasswordResponse{
+			Response: views.Response{
+				Message: invalidPasswordResetMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.ResetPasswordResponse{
+			Response: views.Response{
+				Message: unableToResetPasswordMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.ResetPasswordResponse{
+		Response: views.Response{
+			Message: successfullyResetPasswordMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Sends a link to verify the email address of the user
+func SendVerificationEmail(user models.User) error {
+	var token string
+	err := db.DB.Transaction(func(tx *gorm.DB) (err error) {
+		token, err = IssueUserToken(tx, user.ID, tokenTypes.EmailVerification, emailVerificationDuration)
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", commonUtils.GetFrontendUrl(), token)
+	return mailUtils.Send(mailUtils.Message{
+		To:      user.Email,
+		Subject: emailVerificationSubject,
+		Body:    fmt.Sprintf(emailVerificationBody, user.Name, link),
+	})
+}
+
+// Resends the verification link. The response does not reveal whether the account exists.
+func ResendVerificationEmail(payload views.ResendVerificationPayload) views.ResendVerificationResponse {
+	response := views.ResendVerificationResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(verificationRequestedMessage, payload.Email),
+			Code:    http.StatusOK,
+		},
+	}
+
+	user, err := userService.FindUser(payload.Email)
+	if err != nil || user.EmailVerified {
+		return response
+	}
+
+	err = SendVerificationEmail(user)
+	if err != nil {
+		log.Println("Unable to send verification email", err)
+	}
+	return response
+}
+
+func VerifyEmail(origin auditService.Origin, payload views.VerifyEmailPayload) views.VerifyEmailResponse {
+	var user models.User
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		userToken, err := ConsumeUserToken(tx, payload.Token, tokenTypes.EmailVerification)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("id = ?", userToken.UserID).First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+		err = tx.Model(&user).Select("email_verified", "email_verified_at").Updates(&user).Error
+		if err != nil {
+			return err
+		}
+
+		// Invitations that were held back until the email is verified
+		return invitationService.AcceptPendingInvitationsTx(tx, origin, user)
+	})
+
+	if errors.Is(err, errInvalidUserToken) {
+		return views.VerifyEmailResponse{
+			Response: views.Response{
+				Message: invalidEmailVerificationMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.VerifyEmailResponse{
+			Response: views.Response{
+				Message: unableToVerifyEmailMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.VerifyEmailResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyVerifiedEmailMessage, user.Email),
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Collects the personal data of the actor into a single archive
+func ExportAccount(origin auditService.Origin) views.ExportAccountResponse {
+	export := views.AccountExportView{
+		ExportedAt:  time.Now(),
+		Memberships: []views.AccountMembershipView{},
+		Workspaces:  []views.UserWorkspaceView{},
+		Boards:      []views.AccountBoardView{},
+		Tasks:       []views.AccountTaskView{},
+		Tokens:      []views.PersonalAccessTokenView{},
+		Identities:  []views.AccountIdentityView{},
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Where("id = ?", origin.ActorID).First(&user).Error
+		if err != nil {
+			return err
+		}
+		export.Profile = views.AccountProfileView{
+			ID:              user.ID,
+			Name:            user.Name,
+			Email:           user.Email,
+			EmailVerified:   user.EmailVerified,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			PendingEmail:    user.PendingEmail,
+			TOTPEnabled:     user.TOTPEnabled,
+		}
+
+		var members []models.Member
+		err = tx.Where("user_id = ?", user.ID).Find(&members).Error
+		if err != nil {
+			return err
+		}
+		var boardIDs []string
+		for _, member := range members {
+			boardIDs = append(boardIDs, member.BoardID)
+		}
+		var boards []views.BoardMinimalView
+		err = tx.Model(&models.Board{}).Where("id IN ?", boardIDs).Order("name").Find(&boards).Error
+		if err != nil {
+			return err
+		}
+		boardsByID := map[string]views.BoardMinimalView{}
+		for _, board := range boards {
+			boardsByID[board.ID] = board
+		}
+
+		for _, member := range members {
+			export.Memberships = append(export.Memberships, views.AccountMembershipView{
+				ID:    member.ID,
+				Role:  member.Role,
+				Board: boardsByID[member.BoardID],
+			})
+			if member.Role != roleTypes.Owner {
+				continue
+			}
+
+			boardView := views.AccountBoardView{BoardMinimalView: boardsByID[member.BoardID]}
+			err = tx.Model(&models.State{}).
+				Where("board_id = ?", member.BoardID).
+				Order("current_position").
+				Find(&boardView.States).
+				Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&models.Tag{}).Where("board_id = ?", member.BoardID).Order("name").Find(&boardView.Tags).Error
+			if err != nil {
+				return err
+			}
+			export.Boards = append(export.Boards, boardView)
+		}
+
+		var tasks []models.Task
+		err = tx.Where("user_id = ?", user.ID).Preload("Tags").Order("name").Find(&tasks).Error
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			taskView := views.AccountTaskView{
+				TaskMinimalView: views.TaskMinimalView{
+					ID:          task.ID,
+					Name:        task.Name,
+					Description: task.Description,
+					DueAt:       task.DueAt,
+				},
+				BoardID: task.BoardID,
+				StateID: task.StateID,
+				TagIDs:  []string{},
+			}
+			for _, tag := range task.Tags {
+				taskView.TagIDs = append(taskView.TagIDs, tag.ID)
+			}
+			export.Tasks = append(export.Tasks, taskView)
+		}
+
+		var tokens []models.PersonalAccessToken
+		err = tx.Where("user_id = ?", user.ID).Order("created_at").Find(&tokens).Error
+		if err != nil {
+			return err
+		}
+		for _, token := range tokens {
+			export.Tokens = append(export.Tokens, views.PersonalAccessTokenView{
+				ID:         token.ID,
+				Name:       token.Name,
+				Scopes:     tokenService.GetScopes(token),
+				BoardID:    token.BoardID,
+				ExpiresAt:  token.ExpiresAt,
+				LastUsedAt: token.LastUsedAt,
+				CreatedAt:  token.CreatedAt,
+			})
+		}
+
+		return tx.Model(&models.UserIdentity{}).
+			Where("user_id = ?", user.ID).
+			Order("created_at").
+			Find(&export.Identities).
+			Error
+	})
+	if err == nil {
+		workspacesResponse := workspaceService.GetUserWorkspaces(views.GetUserWorkspacesPayload{UserID: origin.ActorID})
+		if workspacesResponse.Code != http.StatusOK {
+			err = errors.New(workspacesResponse.Message)
+		}
+		export.Workspaces = workspacesResponse.Workspaces
+	}
+	if err != nil {
+		return views.ExportAccountResponse{
+			Response: views.Response{
+				Message: unableToExportAccountMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	auditService.Record(origin, auditService.Event{
+		Action:     auditTypes.AccountExported,
+		TargetType: "user",
+		TargetID:   origin.ActorID,
+	})
+	return views.ExportAccountResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Export:   export,
+	}
+}
+
+// Finds the boards where the user is the only owner, locking their owners until the transaction ends
+func findSolelyOwnedBoards(tx *gorm.DB, userID string) (boardIDs []string, err error) {
+	var owners []models.Member
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND board_id IN (?)",
+			roleTypes.Owner,
+			tx.Model(&models.Member{}).Select("board_id").Where("user_id = ? AND role = ?", userID, roleTypes.Owner),
+		).
+		Find(&owners).
+		Error
+	if err != nil {
+		return
+	}
+
+	owned := map[string]bool{}
+	for _, owner := range owners {
+		if owner.UserID == userID {
+			owned[owner.BoardID] = true
+		}
+	}
+	for _, owner := range owners {
+		if owner.UserID != userID {
+			delete(owned, owner.BoardID)
+		}
+	}
+	for boardID := range owned {
+		boardIDs = append(boardIDs, boardID)
+	}
+	return
+}
+
+// Counts the shared workspaces where the user is the only admin, locking their admins until the transaction ends
+func countSolelyAdministeredWorkspaces(tx *gorm.DB, userID string) (int, error) {
+	var admins []models.WorkspaceMember
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND workspace_id IN (?)",
+			workspaceTypes.Admin,
+			tx.Model(&models.WorkspaceMember{}).
+				Joins("JOIN workspaces ON workspaces.id = workspace_members.workspace_id").
+				Select("workspace_id").
+				Where("workspace_members.user_id = ? AND workspace_members.role = ? AND workspaces.user_id IS NULL",
+					userID, workspaceTypes.Admin),
+		).
+		Find(&admins).
+		Error
+	if err != nil {
+		return 0, err
+	}
+
+	administered := map[string]bool{}
+	for _, admin := range admins {
+		if admin.UserID == userID {
+			administered[admin.WorkspaceID] = true
+		}
+	}
+	for _, admin := range admins {
+		if admin.UserID != userID {
+			delete(administered, admin.WorkspaceID)
+		}
+	}
+	return len(administered), nil
+}
+
+// Moves the boards that remain in the personal workspace of the user, including those in the trash, to the
+// personal workspace of another owner of each board, and deletes the personal workspace. Boards without another
+// owner are purged if nobody else can restore or access them, and are otherwise left without a workspace.
+// The memberships of the user must have been removed beforehand.
+func deletePersonalWorkspace(tx *gorm.DB, origin auditService.Origin, userID string) error {
+	var workspace models.Workspace
+	result := tx.Where("user_id = ?", userID).Limit(1).Find(&workspace)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	var boards []models.Board
+	err := tx.Unscoped().Where("workspace_id = ?", workspace.ID).
+		Preload("Members", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("id")
+		}).
+		Find(&boards).
+		Error
+	if err != nil {
+		return err
+	}
+	for _, board := range boards {
+		var successorID string
+		for _, member := range board.Members {
+			if member.Role == roleTypes.Owner {
+				successorID = member.UserID
+				break
+			}
+		}
+		if len(successorID) == 0 && (board.DeletedAt.Valid || len(board.Members) == 0) {
+			_, err = boardService.PurgeBoardTx(tx, origin, board.ID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if len(successorID) == 0 {
+			// No member is trusted with the workspace of the board
+			err = tx.Unscoped().Model(&models.Board{ID: board.ID}).Update("workspace_id", nil).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		successorWorkspace, err := workspaceService.GetPersonalWorkspaceTx(tx, successorID)
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&models.Board{ID: board.ID}).Update("workspace_id", successorWorkspace.ID).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Where("workspace_id = ?", workspace.ID).Delete(&models.WorkspaceMember{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Delete(&workspace).Error
+}
+
+// Deletes the actor's account. Boards that the actor solely owns are permanently deleted when requested, tasks
+// that the actor created are kept without a creator or the actor as an assignee, and every credential is removed, all within one transaction.
+func DeleteAccount(origin auditService.Origin, sessionID string, payload views.DeleteAccountPayload) views.DeleteAccountResponse {
+	var user models.User
+	err := db.DB.Where("id = ?", origin.ActorID).First(&user).Error
+	if err != nil {
+		return views.DeleteAccountResponse{
+			Response: views.Response{
+				Message: unableToDeleteAccountMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(payload.Email), user.Email) {
+		return views.DeleteAccountResponse{
+			Response: views.Response{
+				Message: accountMismatchMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	response, err := reauthenticate(user, sessionID, payload.Password)
+	if err != nil {
+		return views.DeleteAccountResponse{Response: *response}
+	}
+
+	var soleOwnedBoards []views.BoardMinimalView
+	var soleAdminWorkspaces int
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		boardIDs, err := findSolelyOwnedBoards(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if len(boardIDs) > 0 && !payload.DeleteOwnedBoards {
+			err = tx.Unscoped().Model(&models.Board{}).Where("id IN ?", boardIDs).Order("name").Find(&soleOwnedBoards).Error
+			if err != nil {
+				return err
+			}
+			return errSoleOwner
+		}
+		soleAdminWorkspaces, err = countSolelyAdministeredWorkspaces(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		if soleAdminWorkspaces > 0 {
+			return errSoleAdmin
+		}
+
+		for _, boardID := range boardIDs {
+			_, err = boardService.PurgeBoardTx(tx, origin, boardID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Tasks stay on their boards, without a creator or the user as an assignee
+		err = tx.Unscoped().Model(&models.Task{}).Where("user_id = ?", user.ID).Update("user_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id = ?", user.ID).Delete(&models.TaskAssignee{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id = ?", user.ID).Delete(&models.Member{}).Error
+		if err != nil {
+			return err
+		}
+		err = deletePersonalWorkspace(tx, origin, user.ID)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id = ?", user.ID).Delete(&models.WorkspaceMember{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id = ?", user.ID).Delete(&models.TeamMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		// Credentials
+		err = tx.Where("session_id IN (?)", tx.Model(&models.Session{}).Select("id").Where("user_id = ?", user.ID)).
+			Delete(&models.RefreshToken{}).
+			Error
+		if err != nil {
+			return err
+		}
+		for _, credential := range []interface{}{
+			&models.Session{},
+			&models.PersonalAccessToken{},
+			&models.UserToken{},
+			&models.RecoveryCode{},
+			&models.UserIdentity{},
+		} {
+			err = tx.Where("user_id = ?", user.ID).Delete(credential).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Delete(&user).Error
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.AccountDeleted,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Details:    map[string]interface{}{"deletedBoards": len(boardIDs)},
+		})
+	})
+
+	if errors.Is(err, errSoleAdmin) {
+		return views.DeleteAccountResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(soleAdminMessage, soleAdminWorkspaces),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errSoleOwner) {
+		return views.DeleteAccountResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(soleOwnerMessage, len(soleOwnedBoards)),
+				Code:    http.StatusUnprocessableEntity,
+			},
+			OwnedBoards: soleOwnedBoards,
+		}
+	}
+	if err != nil {
+		return views.DeleteAccountResponse{
+			Response: views.Response{
+				Message: unableToDeleteAccountMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeleteAccountResponse{
+		Response: views.Response{
+			Message: successfullyDeletedAccountMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Renames the actor, returning a new access token for the session so that its claims stay up to date.
+// Other sessions pick up the name when they refresh.
+func UpdateProfile(actor views.AuthUserView, sessionID string, payload views.UpdateProfilePayload) views.UpdateProfileResponse {
+	name := strings.TrimSpace(payload.Name)
+	if len(name) == 0 {
+		return views.UpdateProfileResponse{
+			Response: views.Response{
+				Message: emptyNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	user := models.User{ID: actor.ID}
+	err := db.DB.Model(&user).Update("name", name).Error
+	if err == nil {
+		err = db.DB.First(&user).Error
+	}
+	if err != nil {
+		return views.UpdateProfileResponse{
+			Response: views.Response{
+				Message: unableToUpdateProfileMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	token, err := authUtils.GenerateToken(user, sessionID)
+	if err != nil {
+		return views.UpdateProfileResponse{
+			Response: views.Response{
+				Message: unableToGenerateSessionMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UpdateProfileResponse{
+		Response: views.Response{
+			Message: successfullyUpdatedProfileMessage,
+			Code:    http.StatusOK,
+		},
+		User: views.AuthUserView{
+			ID:    user.ID,
+			Name:  user.Name,
+			Email: user.Email,
+			Token: token,
+		},
+	}
+}
+
+// Confirms that the caller is the user before changing the account, by the password or, for users that only
+// sign in through an identity provider, by a login within the session in the last few minutes
+func reauthenticate(user models.User, sessionID string, password string) (*views.Response, error) {
+	if len(user.Password) > 0 {
+		if authUtils.ComparePassword(user.Password, password) != nil {
+			return &views.Response{Message: invalidPasswordMessage, Code: http.StatusUnauthorized}, errInvalidPassword
+		}
+		return nil, nil
+	}
+
+	var session models.Session
+	err := db.DB.Where("id = ? AND user_id = ?", sessionID, user.ID).First(&session).Error
+	if err == nil && session.CreatedAt.Before(time.Now().Add(-reauthenticationWindow)) {
+		err = errStaleLogin
+	}
+	if err != nil {
+		return &views.Response{Message: reauthenticationMessage, Code: http.StatusForbidden}, err
+	}
+	return nil, nil
+}
+
+// Changes the password of the actor, revoking every session and starting a new one for the caller.
+// Users without a password set one instead.
+func ChangePassword(origin auditService.Origin, sessionID string, payload views.ChangePasswordPayload) views.ChangePasswordResponse {
+	if len(payload.NewPassword) == 0 {
+		return views.ChangePasswordResponse{
+			Response: views.Response{
+				Message: emptyPasswordMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	user, err := userService.FindUserByID(origin.ActorID)
+	if err != nil {
+		return views.ChangePasswordResponse{
+			Response: views.Response{
+				Message: unableToChangePasswordMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	response, err := reauthenticate(user, sessionID, payload.CurrentPassword)
+	if err != nil {
+		return views.ChangePasswordResponse{Response: *response}
+	}
+
+	hashedPassword, err := authUtils.HashPassword(payload.NewPassword)
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Model(&user).Update("password", hashedPassword).Error
+			if err != nil {
+				return err
+			}
+
+			err = sessionService.RevokeUserSessions(tx, user.ID)
+			if err != nil {
+				return err
+			}
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.PasswordChanged,
+				TargetType: "user",
+				TargetID:   user.ID,
+			})
+		})
+	}
+	if err != nil {
+		return views.ChangePasswordResponse{
+			Response: views.Response{
+				Message: unableToChangePasswordMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	accessToken, refreshToken, err := sessionService.CreateSession(user)
+	if err != nil {
+		return views.ChangePasswordResponse{
+			Response: views.Response{
+				Message: unableToGenerateSessionMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.ChangePasswordResponse{
+		Response: views.Response{
+			Message: successfullyChangedPasswordMessage,
+			Code:    http.StatusOK,
+		},
+		User: views.AuthUserView{
+			ID:           user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Token:        accessToken,
+			RefreshToken: refreshToken,
+		},
+	}
+}
+
+// Sends a confirmation link to the new email. The current email stays in use until the link is opened.
+func ChangeEmail(origin auditService.Origin, sessionID string, payload views.ChangeEmailPayload) views.ChangeEmailResponse {
+	email := strings.TrimSpace(payload.Email)
+	if len(email) == 0 {
+		return views.ChangeEmailResponse{
+			Response: views.Response{
+				Message: emptyEmailMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	user, err := userService.FindUserByID(origin.ActorID)
+	if err != nil {
+		return views.ChangeEmailResponse{
+			Response: views.Response{
+				Message: unableToChangeEmailMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	response, err := reauthenticate(user, sessionID, payload.Password)
+	if err != nil {
+		return views.ChangeEmailResponse{Response: *response}
+	}
+	if email == user.Email {
+		return views.ChangeEmailResponse{
+			Response: views.Response{
+				Message: sameEmailMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	_, err = userService.FindUser(email)
+	if err == nil {
+		return views.ChangeEmailResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(emailTakenMessage, email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var token string
+	err = db.DB.Transaction(func(tx *gorm.DB) (err error) {
+		err = tx.Model(&user).Update("pending_email", email).Error
+		if err != nil {
+			return
+		}
+
+		token, err = IssueUserToken(tx, user.ID, tokenTypes.EmailChange, emailVerificationDuration)
+		if err != nil {
+			return
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.EmailChangeRequested,
+			TargetType: "user",
+			TargetID:   user.ID,
+		})
+	})
+	if err != nil {
+		return views.ChangeEmailResponse{
+			Response: views.Response{
+				Message: unableToChangeEmailMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	link := fmt.Sprintf("%s/confirm-email?token=%s", commonUtils.GetFrontendUrl(), token)
+	err = mailUtils.Send(mailUtils.Message{
+		To:      email,
+		Subject: emailChangeSubject,
+		Body:    fmt.Sprintf(emailChangeBody, user.Name, link),
+	})
+	if err != nil {
+		log.Println("Unable to send email change confirmation", err)
+	}
+
+	return views.ChangeEmailResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(emailChangeRequestedMessage, email),
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Replaces the email with the pending email, which is verified by the token.
+// Every session is revoked, since the access tokens carry the previous email.
+func ConfirmEmailChange(origin auditService.Origin, payload views.ConfirmEmailChangePayload) views.ConfirmEmailChangeResponse {
+	var user models.User
+	var previousEmail string
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		userToken, err := ConsumeUserToken(tx, payload.Token, tokenTypes.EmailChange)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", userToken.UserID).First(&user).Error
+		if err != nil {
+			return err
+		}
+		if len(user.PendingEmail) == 0 {
+			return errInvalidUserToken
+		}
+
+		var existingUsers int64
+		err = tx.Model(&models.User{}).Where("email = ?", user.PendingEmail).Count(&existingUsers).Error
+		if err != nil {
+			return err
+		}
+		if existingUsers > 0 {
+			return errEmailTaken
+		}
+
+		now := time.Now()
+		previousEmail = user.Email
+		user.Email = user.PendingEmail
+		user.PendingEmail = ""
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+		err = tx.Model(&user).
+			Select("email", "pending_email", "email_verified", "email_verified_at").
+			Updates(&user).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = sessionService.RevokeUserSessions(tx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		origin.ActorID = user.ID
+		err = auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.EmailChanged,
+			TargetType: "user",
+			TargetID:   user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Invitations that were sent to the new email
+		return invitationService.AcceptPendingInvitationsTx(tx, origin, user)
+	})
+
+	if errors.Is(err, errInvalidUserToken) {
+		return views.ConfirmEmailChangeResponse{
+			Response: views.Response{
+				Message: invalidEmailChangeMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errEmailTaken) {
+		return views.ConfirmEmailChangeResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(emailTakenMessage, user.PendingEmail),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.ConfirmEmailChangeResponse{
+			Response: views.Response{
+				Message: unableToChangeEmailMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	err = mailUtils.Send(mailUtils.Message{
+		To:      previousEmail,
+		Subject: emailChangedSubject,
+		Body:    fmt.Sprintf(emailChangedBody, user.Name, user.Email),
+	})
+	if err != nil {
+		log.Println("Unable to send email change notice", err)
+	}
+
+	return views.ConfirmEmailChangeResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyChangedEmailMessage, user.Email),
+			Code:    http.StatusOK,
+		},
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	datetime "github.com/EmilyOng/tusk-manager/backend/utils/datetime"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+)
+
+const (
+	// Serializes appending to a chain across connections, together with the hash of the chain
+	chainLockKey = 727_010_401
+
+	defaultPageSize = 50
+	maxPageSize     = 200
+	verifyBatchSize = 500
+
+	unableToGetAuditEventsMessage = "Unable to retrieve the audit events."
+	unableToVerifyAuditLogMessage = "Unable to verify the audit log."
+	invalidAuditFilterMessage     = "The filter '%s' is not valid."
+)
+
+// Who performed the event, and where the request came from
+type Origin struct {
+	ActorID   string
+	IP        string
+	UserAgent string
+}
+
+type Event struct {
+	Action     auditTypes.Action
+	TargetType string
+	TargetID   string
+	BoardID    string
+	Details    map[string]interface{}
+}
+
+func optional(value string) *string {
+	if len(value) == 0 {
+		return nil
+	}
+	return &value
+}
+
+// Chains the events of a board together, and other events by the user or client that they concern, so
+// that appending only waits for others appending to the same chain
+func chainOf(origin Origin, event Event) string {
+	switch {
+	case len(event.BoardID) > 0:
+		return "board:" + event.BoardID
+	case len(origin.ActorID) > 0:
+		return "user:" + origin.ActorID
+	case event.TargetType == "user" && len(event.TargetID) > 0:
+		return "user:" + event.TargetID
+	default:
+		return "client:" + origin.IP
+	}
+}
+
+// Hashes the contents of the entry together with the hash of the previous entry
+func computeHash(auditEvent models.AuditEvent) string {
+	contents := []interface{}{
+		auditEvent.Sequence,
+		auditEvent.Action,
+		auditEvent.ActorID,
+		auditEvent.TargetType,
+		auditEvent.TargetID,
+		auditEvent.BoardID,
+		auditEvent.IP,
+		auditEvent.UserAgent,
+		auditEvent.Details,
+		auditEvent.CreatedAt.UTC().Format(time.RFC3339Nano),
+		auditEvent.PrevHash,
+	}
+	// Entries that predate chains keep their hash
+	if len(auditEvent.Chain) > 0 {
+		contents = append(contents, auditEvent.Chain)
+	}
+	bytes, _ := json.Marshal(contents)
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// Appends the event to its chain, within the transaction of the change that it records
+func RecordTx(tx *gorm.DB, origin Origin, event Event) error {
+	details := "{}"
+	if event.Details != nil {
+		bytes, err := json.Marshal(event.Details)
+		if err != nil {
+			return err
+		}
+		details = string(bytes)
+	}
+
+	chain := chainOf(origin, event)
+	return tx.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", chainLockKey, chain).Error
+		if err != nil {
+			return err
+		}
+
+		var last models.AuditEvent
+		err = tx.Where("chain = ?", chain).Order("sequence DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		auditEvent := models.AuditEvent{
+			Chain:      chain,
+			Sequence:   last.Sequence + 1,
+			Action:     event.Action,
+			ActorID:    optional(origin.ActorID),
+			TargetType: event.TargetType,
+			TargetID:   optional(event.TargetID),
+			BoardID:    optional(event.BoardID),
+			IP:         origin.IP,
+			UserAgent:  origin.UserAgent,
+			Details:    details,
+			// Postgres stores timestamps to the microsecond
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+			PrevHash:  last.Hash,
+		}
+		auditEvent.Hash = computeHash(auditEvent)
+		return tx.Create(&auditEvent).Error
+	})
+}
+
+// Records an event that is not part of a transaction, where failures are logged rather than returned
+func Record(origin Origin, event Event) {
+	err := RecordTx(db.DB, origin, event)
+	if err != nil {
+		log.Println("Unable to record audit event", event.Action, err)
+	}
+}
+
+func filterEvents(payload views.GetAuditEventsPayload) (query *gorm.DB, invalidFilter string) {
+	query = db.DB.Model(&models.AuditEvent{})
+	if len(payload.Action) > 0 {
+		query = query.Where("action = ?", payload.Action)
+	}
+	if len(payload.ActorID) > 0 {
+		query = query.Where("actor_id = ?", payload.ActorID)
+	}
+	if len(payload.TargetID) > 0 {
+		query = query.Where("target_id = ?", payload.TargetID)
+	}
+	if len(payload.BoardID) > 0 {
+		query = query.Where("board_id = ?", payload.BoardID)
+	}
+	if len(payload.From) > 0 {
+		from, err := time.Parse(datetime.DatetimeLayout, payload.From)
+		if err != nil {
+			return nil, payload.From
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if len(payload.To) > 0 {
+		to, err := time.Parse(datetime.DatetimeLayout, payload.To)
+		if err != nil {
+			return nil, payload.To
+		}
+		query = query.Where("created_at < ?", to)
+	}
+	return
+}
+
+// Lists the matching events, most recent first
+func GetAuditEvents(payload views.GetAuditEventsPayload) views.GetAuditEventsResponse {
+	if payload.Page < 1 {
+		payload.Page = 1
+	}
+	if payload.PageSize < 1 {
+		payload.PageSize = defaultPageSize
+	}
+	if payload.PageSize > maxPageSize {
+		payload.PageSize = maxPageSize
+	}
+
+	query, invalidFilter := filterEvents(payload)
+	if query == nil {
+		return views.GetAuditEventsResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidAuditFilterMessage, invalidFilter),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	page := views.AuditEventPageView{
+		Events:   []views.AuditEventView{},
+		Page:     payload.Page,
+		PageSize: payload.PageSize,
+	}
+	err := query.Session(&gorm.Session{}).Count(&page.Total).Error
+	if err == nil {
+		err = query.
+			Order("created_at DESC, sequence DESC").
+			Offset((payload.Page - 1) * payload.PageSize).
+			Limit(payload.PageSize).
+			Find(&page.Events).
+			Error
+	}
+	if err != nil {
+		return views.GetAuditEventsResponse{
+			Response: views.Response{
+				Message: unableToGetAuditEventsMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.GetAuditEventsResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Page:     page,
+	}
+}
+
+// Walks the chain from its first entry, checking that every entry is linked and unaltered
+func verifyChain(chain string, verification *views.AuditVerificationView) error {
+	previous := models.AuditEvent{}
+
+	for {
+		var auditEvents []models.AuditEvent
+		err := db.DB.Model(&models.AuditEvent{}).
+			Where("chain = ? AND sequence > ?", chain, previous.Sequence).
+			Order("sequence").
+			Limit(verifyBatchSize).
+			Find(&auditEvents).
+			Error
+		if err != nil || len(auditEvents) == 0 {
+			return err
+		}
+
+		for _, auditEvent := range auditEvents {
+			if auditEvent.Sequence != previous.Sequence+1 ||
+				auditEvent.PrevHash != previous.Hash ||
+				auditEvent.Hash != computeHash(auditEvent) {
+				sequence := auditEvent.Sequence
+				verification.BrokenAt = &sequence
+				verification.Chain = &chain
+				return nil
+			}
+			previous = auditEvent
+			verification.Checked++
+		}
+	}
+}
+
+// Verifies every chain, stopping at the first entry that does not match its chain
+func VerifyAuditLog() views.VerifyAuditLogResponse {
+	var verification views.AuditVerificationView
+
+	var chains []string
+	err := db.DB.Model(&models.AuditEvent{}).Distinct("chain").Order("chain").Pluck("chain", &chains).Error
+	for _, chain := range chains {
+		if err != nil || verification.BrokenAt != nil {
+			break
+		}
+		err = verifyChain(chain, &verification)
+	}
+	if err != nil {
+		return views.VerifyAuditLogResponse{
+			Response: views.Response{
+				Message: unableToVerifyAuditLogMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	verification.Valid = verification.BrokenAt == nil
+	return views.VerifyAuditLogResponse{
+		Response:     views.Response{Code: http.StatusOK},
+		Verification: verification,
+	}
+}
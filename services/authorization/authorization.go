@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	"gorm.io/gorm"
+)
+
+// Granting permissions that the grantor does not hold
+var ErrPermissionNotHeld = errors.New("the grantor does not hold the permissions")
+
+// Selects the shared workspaces that the user administers, whose boards the user can access without being a
+// member of them. Personal workspaces do not grant such access, so that the creator of a board loses access
+// to it once it has left the board or has been removed.
+func AdministeredWorkspaceIDs(tx *gorm.DB, userID string) *gorm.DB {
+	return tx.Model(&models.WorkspaceMember{}).
+		Select("workspace_members.workspace_id").
+		Joins("JOIN workspaces ON workspaces.id = workspace_members.workspace_id").
+		Where("workspace_members.user_id = ? AND workspace_members.role = ? AND workspaces.user_id IS NULL",
+			userID, workspaceTypes.Admin)
+}
+
+// Checks within the transaction whether the user can access the board, through a membership, a team of the board
+// or the adminship of the board's shared workspace
+func HasBoardAccessTx(tx *gorm.DB, userID string, boardID string) (bool, error) {
+	var boards int64
+	err := tx.Unscoped().Model(&models.Board{}).
+		Where("id = ?", boardID).
+		Where("id IN (?) OR id IN (?) OR workspace_id IN (?)",
+			tx.Model(&models.Member{}).Select("board_id").Where("user_id = ?", userID),
+			tx.Model(&models.BoardTeam{}).
+				Select("board_teams.board_id").
+				Joins("JOIN team_members ON team_members.team_id = board_teams.team_id").
+				Where("team_members.user_id = ?", userID),
+			AdministeredWorkspaceIDs(tx, userID),
+		).
+		Count(&boards).
+		Error
+	return boards > 0, err
+}
+
+// Retrieves the role that the user holds on the board, which is the highest of the direct and team roles.
+// Admins of the board's shared workspace hold the owner role, even without being a member of the board.
+func GetBoardRole(userID string, boardID string) (roleTypes.Role, error) {
+	var member models.Member
+	err := db.DB.Model(&models.Member{}).
+		Where("user_id = ? AND board_id = ?", userID, boardID).
+		First(&member).
+		Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return member.Role, err
+	}
+	role := member.Role
+	if role == roleTypes.Owner {
+		return role, nil
+	}
+
+	var teamRoles []roleTypes.Role
+	teamErr := db.DB.Model(&models.BoardTeam{}).
+		Joins("JOIN team_members ON team_members.team_id = board_teams.team_id").
+		Where("board_teams.board_id = ? AND team_members.user_id = ?", boardID, userID).
+		Pluck("board_teams.role", &teamRoles).
+		Error
+	if teamErr != nil {
+		return role, teamErr
+	}
+	for _, teamRole := range teamRoles {
+		role = role.Max(teamRole)
+	}
+	if role == roleTypes.Owner {
+		return role, nil
+	}
+
+	var admins int64
+	adminErr := db.DB.Unscoped().Model(&models.Board{}).
+		Where("id = ? AND workspace_id IN (?)", boardID, AdministeredWorkspaceIDs(db.DB, userID)).
+		Count(&admins).
+		Error
+	if adminErr != nil {
+		return role, adminErr
+	}
+	if admins > 0 {
+		return roleTypes.Owner, nil
+	}
+	if len(role) > 0 {
+		return role, nil
+	}
+	return role, err
+}
+
+// Retrieves the permissions that the user holds on the board, which combine the direct and team roles
+// together with their custom roles. Admins of the board's shared workspace hold every permission.
+// Boards in the trash cannot be accessed.
+func GetBoardPermissions(userID string, boardID string) (permissionTypes.Set, error) {
+	err := db.DB.Select("id").Where("id = ?", boardID).Take(&models.Board{}).Error
+	if err != nil {
+		return permissionTypes.NewSet(), err
+	}
+	return getBoardPermissions(userID, boardID)
+}
+
+// Retrieves the permissions that the user held on the board before it was moved to the trash
+func GetTrashedBoardPermissions(userID string, boardID string) (permissionTypes.Set, error) {
+	err := db.DB.Unscoped().Select("id").Where("id = ? AND deleted_at IS NOT NULL", boardID).Take(&models.Board{}).Error
+	if err != nil {
+		return permissionTypes.NewSet(), err
+	}
+	return getBoardPermissions(userID, boardID)
+}
+
+func getBoardPermissions(userID string, boardID string) (permissionTypes.Set, error) {
+	permissions := permissionTypes.NewSet()
+	var customRoleIDs []string
+
+	var members []models.Member
+	err := db.DB.Where("user_id = ? AND board_id = ?", userID, boardID).Find(&members).Error
+	if err != nil {
+		return permissions, err
+	}
+	for _, member := range members {
+		permissions.Add(member.Role.Permissions()...)
+		if member.CustomRoleID != nil {
+			customRoleIDs = append(customRoleIDs, *member.CustomRoleID)
+		}
+	}
+
+	var boardTeams []models.BoardTeam
+	err = db.DB.Model(&models.BoardTeam{}).
+		Joins("JOIN team_members ON team_members.team_id = board_teams.team_id").
+		Where("board_teams.board_id = ? AND team_members.user_id = ?", boardID, userID).
+		Find(&boardTeams).
+		Error
+	if err != nil {
+		return permissions, err
+	}
+	for _, boardTeam := range boardTeams {
+		permissions.Add(boardTeam.Role.Permissions()...)
+		if boardTeam.CustomRoleID != nil {
+			customRoleIDs = append(customRoleIDs, *boardTeam.CustomRoleID)
+		}
+	}
+
+	if len(customRoleIDs) > 0 {
+		var customRoles []models.CustomRole
+		err = db.DB.Where("id IN ?", customRoleIDs).Find(&customRoles).Error
+		if err != nil {
+			return permissions, err
+		}
+		for _, customRole := range customRoles {
+			permissions.Add(GetCustomRolePermissions(customRole)...)
+		}
+	}
+
+	var admins int64
+	err = db.DB.Unscoped().Model(&models.Board{}).
+		Where("id = ? AND workspace_id IN (?)", boardID, AdministeredWorkspaceIDs(db.DB, userID)).
+		Count(&admins).
+		Error
+	if err != nil {
+		return permissions, err
+	}
+	if admins > 0 {
+		permissions.Add(permissionTypes.Catalogue...)
+	}
+
+	if len(permissions) == 0 {
+		// The user does not have any access to the board
+		return permissions, gorm.ErrRecordNotFound
+	}
+	return permissions, nil
+}
+
+func GetCustomRolePermissions(customRole models.CustomRole) (permissions []permissionTypes.Permission) {
+	for _, permission := range strings.Fields(customRole.Permissions) {
+		permissions = append(permissions, permissionTypes.Permission(permission))
+	}
+	return
+}
+
+// Retrieves the custom role if it can be assigned on the board, which is when it belongs to the board
+// or to the workspace of the board
+func FindBoardCustomRole(tx *gorm.DB, boardID string, customRoleID string) (customRole models.CustomRole, err error) {
+	err = tx.Where("id = ? AND (board_id = ? OR workspace_id IN (?))",
+		customRoleID,
+		boardID,
+		tx.Model(&models.Board{}).Select("workspace_id").Where("id = ?", boardID),
+	).First(&customRole).Error
+	return
+}
+
+// Retrieves the permissions that the preset role grants together with the custom role, if any,
+// which must be assignable on the board
+func GetGrantPermissions(tx *gorm.DB, boardID string, role roleTypes.Role, customRoleID *string) (permissionTypes.Set, error) {
+	permissions := permissionTypes.NewSet(role.Permissions()...)
+	if customRoleID == nil {
+		return permissions, nil
+	}
+	customRole, err := FindBoardCustomRole(tx, boardID, *customRoleID)
+	if err != nil {
+		return permissions, err
+	}
+	permissions.Add(GetCustomRolePermissions(customRole)...)
+	return permissions, nil
+}
+
+// Ensures that the grantor holds every permission that the preset role and custom role grant on the board,
+// so that permissions can only be passed on
+func CheckGrant(tx *gorm.DB, boardID string, grantor permissionTypes.Set, role roleTypes.Role, customRoleID *string) error {
+	permissions, err := GetGrantPermissions(tx, boardID, role, customRoleID)
+	if err != nil {
+		return err
+	}
+	if !grantor.Includes(permissions) {
+		return ErrPermissionNotHeld
+	}
+	return nil
+}
+
+// Retrieves the role that the user holds in the workspace
+func GetWorkspaceRole(userID string, workspaceID string) (workspaceTypes.Role, error) {
+	var workspaceMember models.WorkspaceMember
+	err := db.DB.Model(&models.WorkspaceMember{}).
+		Where("user_id = ? AND workspace_id = ?", userID, workspaceID).
+		First(&workspaceMember).
+		Error
+	return workspaceMember.Role, err
+}
+
+// Retrieves the board that a board-scoped resource (task, tag, state, member) belongs to
+func GetResourceBoardID(model interface{}, id string) (string, error) {
+	var resource struct {
+		BoardID string
+	}
+	err := db.DB.Model(model).Select("board_id").Where("id = ?", id).Take(&resource).Error
+	return resource.BoardID, err
+}
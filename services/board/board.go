@@ -4,13 +4,20 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/EmilyOng/tusk-manager/backend/db"
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	workspaceService "github.com/EmilyOng/tusk-manager/backend/services/workspace"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
 	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
 	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -23,20 +30,66 @@ const (
 	unableToGetBoardStatesMessage  = "Unable to retrieve the states for the board (%s)."
 	unableToGetBoardMembersMessage = "Unable to retrieve the members for the board (%s)."
 	boardNotFoundMessage           = "The board cannot be found (%s)."
+	userMismatchMessage            = "The user (%s) does not match the authenticated user."
+	notWorkspaceMemberMessage      = "You can only create boards in workspaces that you are a member of."
 
 	successfullyCreatedBoardMessage = "Successfully created the board '%s'!"
 	successfullyUpdatedBoardMessage = "Successfully updated the board '%s'!"
-	successfullyDeletedBoardMessage = "Successfully deleted the board '%s'!"
+	successfullyDeletedBoardMessage = "Successfully moved the board '%s' to the trash!"
 )
 
-func CreateBoard(payload views.CreateBoardPayload) views.CreateBoardResponse {
+// Creates a board owned by the actor
+func CreateBoard(actor views.AuthUserView, payload views.CreateBoardPayload) views.CreateBoardResponse {
+	if len(payload.UserID) > 0 && payload.UserID != actor.ID {
+		return views.CreateBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(userMismatchMessage, payload.UserID),
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
+	if len(payload.WorkspaceID) > 0 {
+		_, err := authorizationService.GetWorkspaceRole(actor.ID, payload.WorkspaceID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return views.CreateBoardResponse{
+				Response: views.Response{
+					Message: notWorkspaceMemberMessage,
+					Code:    http.StatusForbidden,
+				},
+			}
+		}
+		if err != nil {
+			return views.CreateBoardResponse{
+				Response: views.Response{
+					Message: fmt.Sprintf(unableToCreateBoardMessage, payload.Name),
+					Code:    http.StatusInternalServerError,
+				},
+			}
+		}
+	}
+
 	owner := models.Member{
 		Role:   roleTypes.Owner,
-		UserID: payload.UserID,
+		UserID: actor.ID,
+	}
+	board := models.Board{
+		Name:        payload.Name,
+		Color:       payload.Color,
+		WorkspaceID: payload.WorkspaceID,
+		Members:     []*models.Member{&owner},
 	}
-	board := models.Board{Name: payload.Name, Color: payload.Color, Members: []*models.Member{&owner}}
 
 	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Boards are created in the personal workspace by default
+		if len(board.WorkspaceID) == 0 {
+			workspace, err := workspaceService.GetPersonalWorkspaceTx(tx, actor.ID)
+			if err != nil {
+				return err
+			}
+			board.WorkspaceID = workspace.ID
+		}
+
 		result := tx.Create(&board)
 		if result.Error != nil {
 			return result.Error
@@ -74,9 +127,10 @@ func CreateBoard(payload views.CreateBoardPayload) views.CreateBoardResponse {
 			Code:    http.StatusOK,
 		},
 		Board: views.BoardMinimalView{
-			ID:    board.ID,
-			Name:  board.Name,
-			Color: board.Color,
+			ID:          board.ID,
+			Name:        board.Name,
+			Color:       board.Color,
+			WorkspaceID: board.WorkspaceID,
 		},
 	}
 }
@@ -105,9 +159,10 @@ func GetBoard(payload views.GetBoardPayload) views.GetBoardResponse {
 	return views.GetBoardResponse{
 		Response: views.Response{Code: http.StatusOK},
 		Board: views.BoardMinimalView{
-			ID:    board.ID,
-			Name:  board.Name,
-			Color: board.Color,
+			ID:          board.ID,
+			Name:        board.Name,
+			Color:       board.Color,
+			WorkspaceID: board.WorkspaceID,
 		},
 	}
 }
@@ -116,8 +171,10 @@ func GetBoardTasks(payload views.GetBoardTasksPayload) views.GetBoardTasksRespon
 	board := models.Board{ID: payload.BoardID}
 	var tasks []models.Task
 
-	err := db.DB.Model(&board).Order("tasks.name").Preload("Tags", func(db *gorm.DB) *gorm.DB {
+	err := db.DB.Model(&board).Order("tasks.position").Preload("Tags", func(db *gorm.DB) *gorm.DB {
 		return db.Order("tags.name")
+	}).Preload("Assignees", func(db *gorm.DB) *gorm.DB {
+		return db.Order("task_assignees.created_at")
 	}).Association("Tasks").Find(&tasks)
 	if err != nil {
 		return views.GetBoardTasksResponse{
@@ -157,7 +214,7 @@ func GetBoardTags(payload views.GetBoardTagsPayload) views.GetBoardTagsResponse
 func GetBoardStates(payload views.GetBoardStatesPayload) views.GetBoardStatesResponse {
 	board := models.Board{ID: payload.BoardID}
 	var statesView []views.StateMinimalView
-	err := db.DB.Model(&board).Order("states.current_position").Association("States").Find(&statesView)
+	err := db.DB.Model(&board).Order("states.current_position, states.id").Association("States").Find(&statesView)
 	if err != nil {
 		return views.GetBoardStatesResponse{
 			Response: views.Response{
@@ -173,17 +230,39 @@ func GetBoardStates(payload views.GetBoardStatesPayload) views.GetBoardStatesRes
 	}
 }
 
+// Lists the users with access to the board, together with the direct and team roles that grant it
 func GetBoardMemberProfiles(payload views.GetBoardMemberProfilesPayload) views.GetBoardMemberProfilesResponse {
 	var members []models.Member
+	var boardTeams []models.BoardTeam
+	selectUser := func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id", "name", "email")
+	}
 
 	err := db.DB.
-		Preload("User", func(tx *gorm.DB) *gorm.DB {
-			return tx.Select("id", "name", "email")
-		}).
+		Preload("User", selectUser).
 		Model(&models.Member{}).
 		Where("board_id = ?", payload.BoardID).
 		Find(&members).
 		Error
+	if err == nil {
+		err = db.DB.
+			Preload("Team").
+			Preload("Team.Members.User", selectUser).
+			Where("board_id = ?", payload.BoardID).
+			Find(&boardTeams).
+			Error
+	}
+	customRolePermissions := map[string][]permissionTypes.Permission{}
+	if err == nil {
+		var customRoles []models.CustomRole
+		err = db.DB.Where("board_id = ? OR workspace_id IN (?)",
+			payload.BoardID,
+			db.DB.Model(&models.Board{}).Select("workspace_id").Where("id = ?", payload.BoardID),
+		).Find(&customRoles).Error
+		for _, customRole := range customRoles {
+			customRolePermissions[customRole.ID] = authorizationService.GetCustomRolePermissions(customRole)
+		}
+	}
 
 	if err != nil {
 		return views.GetBoardMemberProfilesResponse{
@@ -194,17 +273,52 @@ func GetBoardMemberProfiles(payload views.GetBoardMemberProfilesPayload) views.G
 		}
 	}
 
-	var membersView []views.MemberFullView
+	membersView := []views.MemberProfileView{}
+	permissions := []permissionTypes.Set{}
+	indexes := map[string]int{}
+	grant := func(user *models.User, access views.MemberAccessView) *views.MemberProfileView {
+		index, ok := indexes[user.ID]
+		if !ok {
+			index = len(membersView)
+			indexes[user.ID] = index
+			permissions = append(permissions, permissionTypes.NewSet())
+			membersView = append(membersView, views.MemberProfileView{
+				MemberFullView: views.MemberFullView{
+					User: views.UserMinimalView{
+						ID:    user.ID,
+						Name:  user.Name,
+						Email: user.Email,
+					},
+				},
+			})
+		}
+		memberView := &membersView[index]
+		memberView.Role = memberView.Role.Max(access.Role)
+		memberView.Access = append(memberView.Access, access)
+		permissions[index].Add(access.Role.Permissions()...)
+		if access.CustomRoleID != nil {
+			permissions[index].Add(customRolePermissions[*access.CustomRoleID]...)
+		}
+		return memberView
+	}
+
 	for _, member := range members {
-		membersView = append(membersView, views.MemberFullView{
-			ID:   member.ID,
-			Role: member.Role,
-			User: views.UserMinimalView{
-				ID:    member.UserID,
-				Name:  member.User.Name,
-				Email: member.User.Email,
-			},
-		})
+		memberView := grant(member.User, views.MemberAccessView{Role: member.Role, CustomRoleID: member.CustomRoleID})
+		memberView.ID = member.ID
+		memberView.CustomRoleID = member.CustomRoleID
+	}
+	for _, boardTeam := range boardTeams {
+		team := views.TeamMinimalView{ID: boardTeam.Team.ID, Name: boardTeam.Team.Name}
+		for _, teamMember := range boardTeam.Team.Members {
+			grant(teamMember.User, views.MemberAccessView{
+				Role:         boardTeam.Role,
+				CustomRoleID: boardTeam.CustomRoleID,
+				Team:         &team,
+			})
+		}
+	}
+	for index := range membersView {
+		membersView[index].Permissions = permissions[index].List()
 	}
 
 	return views.GetBoardMemberProfilesResponse{
@@ -213,9 +327,26 @@ func GetBoardMemberProfiles(payload views.GetBoardMemberProfilesPayload) views.G
 	}
 }
 
-func UpdateBoard(payload views.UpdateBoardPayload) views.UpdateBoardResponse {
+func UpdateBoard(actor views.AuthUserView, payload views.UpdateBoardPayload) views.UpdateBoardResponse {
+	if len(payload.UserID) > 0 && payload.UserID != actor.ID {
+		return views.UpdateBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(userMismatchMessage, payload.UserID),
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
+	// The workspace is changed by moving the board instead
 	board := models.Board{ID: payload.ID, Name: payload.Name, Color: payload.Color}
-	err := db.DB.Save(&board).Error
+	result := db.DB.Model(&board).Select("name", "color").Updates(&board)
+	err := result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+	if err == nil {
+		err = db.DB.First(&board).Error
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return views.UpdateBoardResponse{
@@ -239,67 +370,129 @@ func UpdateBoard(payload views.UpdateBoardPayload) views.UpdateBoardResponse {
 			Code:    http.StatusOK,
 		},
 		Board: views.BoardMinimalView{
-			ID:    board.ID,
-			Name:  board.Name,
-			Color: board.Color,
+			ID:          board.ID,
+			Name:        board.Name,
+			Color:       board.Color,
+			WorkspaceID: board.WorkspaceID,
 		},
 	}
 }
 
-func DeleteBoard(payload views.DeleteBoardPayload) views.DeleteBoardResponse {
-	board := models.Board{ID: payload.ID}
-
-	err := db.DB.Transaction(func(tx *gorm.DB) error {
-		result := tx.
-			Preload("Tasks").
-			Preload("Tags").
-			Preload("States").
-			Preload("Members").
-			First(&board)
-
+// Moves the board to the trash together with its tasks, tags and states, within the caller's transaction.
+// The access of members and teams is kept so that the board can be restored, while pending invitations,
+// invite links and the publication are revoked.
+func DeleteBoardTx(tx *gorm.DB, origin auditService.Origin, boardID string) (board models.Board, err error) {
+	board.ID = boardID
+	err = tx.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&board)
 		if result.Error != nil {
 			return result.Error
 		}
 
-		// Delete associated tasks
-		if len(board.Tasks) > 0 {
-			result = tx.Model(&models.Task{}).Delete(&board.Tasks)
+		// Everything is deleted at the same time as the board, so that it is restored together with the board
+		deletedAt := gorm.DeletedAt{Time: time.Now(), Valid: true}
+		for _, model := range []interface{}{&models.Task{}, &models.Tag{}, &models.State{}} {
+			result = tx.Model(model).Where("board_id = ?", board.ID).Update("deleted_at", deletedAt)
 			if result.Error != nil {
 				return result.Error
 			}
 		}
 
-		// Delete associated tags
-		if len(board.Tags) > 0 {
-			result = tx.Model(&models.Tag{}).Delete(&board.Tags)
-			if result.Error != nil {
-				return result.Error
-			}
+		// Delete pending invitations and invite links
+		result = tx.Where("board_id = ?", board.ID).Delete(&models.Invitation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		result = tx.Where("board_id = ?", board.ID).Delete(&models.InviteLink{})
+		if result.Error != nil {
+			return result.Error
 		}
 
-		// Delete associated states
-		if len(board.States) > 0 {
-			result = tx.Model(&models.State{}).Delete(&board.States)
-			if result.Error != nil {
-				return result.Error
-			}
+		// Unpublish the board
+		result = tx.Where("board_id = ?", board.ID).Delete(&models.BoardPublication{})
+		if result.Error != nil {
+			return result.Error
 		}
 
-		// Delete associated members
-		if len(board.Members) > 0 {
-			result = tx.Model(&models.Member{}).Delete(&board.Members)
+		result = tx.Model(&board).Update("deleted_at", deletedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.BoardDeleted,
+			TargetType: "board",
+			TargetID:   board.ID,
+			BoardID:    board.ID,
+			Details:    map[string]interface{}{"name": board.Name},
+		})
+	})
+	return
+}
+
+// Permanently deletes the board together with everything that belongs to it, including what is in the trash,
+// within the caller's transaction
+func PurgeBoardTx(tx *gorm.DB, origin auditService.Origin, boardID string) (board models.Board, err error) {
+	board.ID = boardID
+	err = tx.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&board)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Delete the links between tasks and tags, and the assignments of tasks
+		result = tx.Exec("DELETE FROM task_tags WHERE task_id IN (?) OR tag_id IN (?)",
+			tx.Unscoped().Model(&models.Task{}).Select("id").Where("board_id = ?", board.ID),
+			tx.Unscoped().Model(&models.Tag{}).Select("id").Where("board_id = ?", board.ID),
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		result = tx.Where("task_id IN (?)",
+			tx.Unscoped().Model(&models.Task{}).Select("id").Where("board_id = ?", board.ID),
+		).Delete(&models.TaskAssignee{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for _, model := range []interface{}{
+			&models.Task{},
+			&models.Tag{},
+			&models.State{},
+			&models.Member{},
+			&models.BoardTeam{},
+			&models.CustomRole{},
+			&models.Invitation{},
+			&models.InviteLink{},
+			&models.BoardPublication{},
+		} {
+			result = tx.Unscoped().Where("board_id = ?", board.ID).Delete(model)
 			if result.Error != nil {
 				return result.Error
 			}
 		}
 
-		// Delete the board
-		result = tx.Delete(&board)
+		result = tx.Unscoped().Delete(&board)
 		if result.Error != nil {
 			return result.Error
 		}
 
-		return nil
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.BoardPurged,
+			TargetType: "board",
+			TargetID:   board.ID,
+			BoardID:    board.ID,
+			Details:    map[string]interface{}{"name": board.Name},
+		})
+	})
+	return
+}
+
+func DeleteBoard(origin auditService.Origin, payload views.DeleteBoardPayload) views.DeleteBoardResponse {
+	var board models.Board
+	err := db.DB.Transaction(func(tx *gorm.DB) (err error) {
+		board, err = DeleteBoardTx(tx, origin, payload.ID)
+		return
 	})
 
 	if err != nil {
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+)
+
+const (
+	unableToGetCustomRolesMessage   = "Unable to retrieve the custom roles."
+	unableToCreateCustomRoleMessage = "Unable to create the custom role '%s'."
+	unableToUpdateCustomRoleMessage = "Unable to update the custom role (%s)."
+	unableToDeleteCustomRoleMessage = "Unable to delete the custom role (%s)."
+	emptyCustomRoleNameMessage      = "The name of the custom role cannot be empty."
+	emptyPermissionsMessage         = "The custom role must have at least one permission."
+	invalidPermissionMessage        = "The permission '%s' is not valid."
+	customRoleNotFoundMessage       = "The custom role cannot be found (%s)."
+	permissionNotHeldMessage        = "You cannot grant or revoke permissions that you do not hold."
+
+	successfullyCreatedCustomRoleMessage = "Successfully created the custom role '%s'!"
+	successfullyUpdatedCustomRoleMessage = "Successfully updated the custom role '%s'!"
+	successfullyDeletedCustomRoleMessage = "Successfully deleted the custom role '%s'!"
+)
+
+var presets = []roleTypes.Role{roleTypes.Owner, roleTypes.Editor, roleTypes.Viewer}
+
+func toView(customRole models.CustomRole) views.CustomRoleView {
+	return views.CustomRoleView{
+		ID:          customRole.ID,
+		Name:        customRole.Name,
+		Permissions: authorizationService.GetCustomRolePermissions(customRole),
+		WorkspaceID: customRole.WorkspaceID,
+		BoardID:     customRole.BoardID,
+	}
+}
+
+// Validates the name and permissions of a custom role, describing why they are invalid otherwise
+func parseCustomRole(name string, permissions []permissionTypes.Permission) (string, permissionTypes.Set, *views.Response) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return name, nil, &views.Response{
+			Message: emptyCustomRoleNameMessage,
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+	if len(permissions) == 0 {
+		return name, nil, &views.Response{
+			Message: emptyPermissionsMessage,
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return name, nil, &views.Response{
+				Message: fmt.Sprintf(invalidPermissionMessage, permission),
+				Code:    http.StatusUnprocessableEntity,
+			}
+		}
+	}
+	return name, permissionTypes.NewSet(permissions...), nil
+}
+
+func formatPermissions(permissions permissionTypes.Set) string {
+	var fields []string
+	for _, permission := range permissions.List() {
+		fields = append(fields, string(permission))
+	}
+	return strings.Join(fields, " ")
+}
+
+// Restricts the query to the custom roles of the board, or of the workspace otherwise
+func ownedBy(tx *gorm.DB, workspaceID string, boardID string) *gorm.DB {
+	if len(boardID) > 0 {
+		return tx.Where("board_id = ?", boardID)
+	}
+	return tx.Where("workspace_id = ?", workspaceID)
+}
+
+func GetPermissionCatalogue() views.GetPermissionCatalogueResponse {
+	catalogue := views.PermissionCatalogueView{
+		Permissions: permissionTypes.Catalogue,
+		Presets:     []views.PermissionPresetView{},
+	}
+	for _, preset := range presets {
+		catalogue.Presets = append(catalogue.Presets, views.PermissionPresetView{
+			Role:        preset,
+			Permissions: preset.Permissions(),
+		})
+	}
+	return views.GetPermissionCatalogueResponse{
+		Response:  views.Response{Code: http.StatusOK},
+		Catalogue: catalogue,
+	}
+}
+
+// Lists the custom roles of the workspace, or those that can be assigned on the board
+func GetCustomRoles(payload views.GetCustomRolesPayload) views.GetCustomRolesResponse {
+	var customRoles []models.CustomRole
+	query := db.DB.Where("workspace_id = ?", payload.WorkspaceID)
+	if len(payload.BoardID) > 0 {
+		query = db.DB.Where("board_id = ? OR workspace_id IN (?)",
+			payload.BoardID,
+			db.DB.Model(&models.Board{}).Select("workspace_id").Where("id = ?", payload.BoardID),
+		)
+	}
+	err := query.Order("name").Find(&customRoles).Error
+	if err != nil {
+		return views.GetCustomRolesResponse{
+			Response: views.Response{
+				Message: unableToGetCustomRolesMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	rolesView := []views.CustomRoleView{}
+	for _, customRole := range customRoles {
+		rolesView = append(rolesView, toView(customRole))
+	}
+	return views.GetCustomRolesResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Roles:    rolesView,
+	}
+}
+
+// Creates a custom role for the workspace or board. The grantor must hold its permissions.
+func CreateCustomRole(origin auditService.Origin, grantor permissionTypes.Set, payload views.CreateCustomRolePayload) views.CreateCustomRoleResponse {
+	name, permissions, response := parseCustomRole(payload.Name, payload.Permissions)
+	if response != nil {
+		return views.CreateCustomRoleResponse{Response: *response}
+	}
+	if !grantor.Includes(permissions) {
+		return views.CreateCustomRoleResponse{
+			Response: views.Response{
+				Message: permissionNotHeldMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
+	customRole := models.CustomRole{Name: name, Permissions: formatPermissions(permissions)}
+	if len(payload.BoardID) > 0 {
+		customRole.BoardID = &payload.BoardID
+	} else {
+		customRole.WorkspaceID = &payload.WorkspaceID
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&customRole).Error
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.CustomRoleCreated,
+			TargetType: "custom_role",
+			TargetID:   customRole.ID,
+			BoardID:    payload.BoardID,
+			Details:    map[string]interface{}{"workspaceId": customRole.WorkspaceID, "permissions": customRole.Permissions},
+		})
+	})
+	if err != nil {
+		return views.CreateCustomRoleResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateCustomRoleMessage, name),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.CreateCustomRoleResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedCustomRoleMessage, customRole.Name),
+			Code:    http.StatusOK,
+		},
+		Role: toView(customRole),
+	}
+}
+
+// Changes the custom role for everyone holding it. The grantor must hold both the previous and the new permissions.
+func UpdateCustomRole(origin auditService.Origin, grantor permissionTypes.Set, payload views.UpdateCustomRolePayload) views.UpdateCustomRoleResponse {
+	name, permissions, response := parseCustomRole(payload.Name, payload.Permissions)
+	if response != nil {
+		return views.UpdateCustomRoleResponse{Response: *response}
+	}
+
+	var customRole models.CustomRole
+	err := ownedBy(db.DB, payload.WorkspaceID, payload.BoardID).Where("id = ?", payload.ID).First(&customRole).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UpdateCustomRoleResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(customRoleNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	previousPermissions := permissionTypes.NewSet(authorizationService.GetCustomRolePermissions(customRole)...)
+	if err == nil && (!grantor.Includes(previousPermissions) || !grantor.Includes(permissions)) {
+		return views.UpdateCustomRoleResponse{
+			Response: views.Response{
+				Message: permissionNotHeldMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
+	if err == nil {
+		previous := customRole.Permissions
+		customRole.Name = name
+		customRole.Permissions = formatPermissions(permissions)
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Model(&customRole).Select("name", "permissions").Updates(&customRole).Error
+			if err != nil || previous == customRole.Permissions {
+				return err
+			}
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.CustomRoleUpdated,
+				TargetType: "custom_role",
+				TargetID:   customRole.ID,
+				BoardID:    payload.BoardID,
+				Details: map[string]interface{}{
+					"workspaceId": customRole.WorkspaceID,
+					"from":        previous,
+					"to":          customRole.Permissions,
+				},
+			})
+		})
+	}
+	if err != nil {
+		return views.UpdateCustomRoleResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToUpdateCustomRoleMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UpdateCustomRoleResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyUpdatedCustomRoleMessage, customRole.Name),
+			Code:    http.StatusOK,
+		},
+		Role: toView(customRole),
+	}
+}
+
+// Deletes the custom role, leaving everyone holding it with their preset role.
+// The grantor must hold the permissions of the custom role.
+func DeleteCustomRole(origin auditService.Origin, grantor permissionTypes.Set, payload views.DeleteCustomRolePayload) views.DeleteCustomRoleResponse {
+	var customRole models.CustomRole
+	notHeld := false
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := ownedBy(tx, payload.WorkspaceID, payload.BoardID).Where("id = ?", payload.ID).First(&customRole).Error
+		if err != nil {
+			return err
+		}
+		if !grantor.Includes(permissionTypes.NewSet(authorizationService.GetCustomRolePermissions(customRole)...)) {
+			notHeld = true
+			return nil
+		}
+
+		err = tx.Model(&models.Member{}).Where("custom_role_id = ?", customRole.ID).Update("custom_role_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.BoardTeam{}).Where("custom_role_id = ?", customRole.ID).Update("custom_role_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&customRole).Error
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.CustomRoleDeleted,
+			TargetType: "custom_role",
+			TargetID:   customRole.ID,
+			BoardID:    payload.BoardID,
+			Details:    map[string]interface{}{"workspaceId": customRole.WorkspaceID, "name": customRole.Name},
+		})
+	})
+
+	if notHeld {
+		return views.DeleteCustomRoleResponse{
+			Response: views.Response{
+				Message: permissionNotHeldMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.DeleteCustomRoleResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(customRoleNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.DeleteCustomRoleResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToDeleteCustomRoleMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeleteCustomRoleResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyDeletedCustomRoleMessage, customRole.Name),
+			Code:    http.StatusOK,
+		},
+	}
+}
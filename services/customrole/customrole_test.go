@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+)
+
+var ownerPermissions = permissionTypes.NewSet(roleTypes.Owner.Permissions()...)
+
+func TestCustomRolesAreValidated(t *testing.T) {
+	tests := []struct {
+		name        string
+		roleName    string
+		permissions []permissionTypes.Permission
+		message     string
+	}{
+		{name: "empty name", roleName: " ", permissions: []permissionTypes.Permission{permissionTypes.ViewBoard}, message: emptyCustomRoleNameMessage},
+		{name: "no permissions", roleName: "Role", message: emptyPermissionsMessage},
+		{name: "unknown permission", roleName: "Role", permissions: []permissionTypes.Permission{"board:own"}, message: fmt.Sprintf(invalidPermissionMessage, "board:own")},
+	}
+
+	for _, test := range tests {
+		_, _, response := parseCustomRole(test.roleName, test.permissions)
+		if response == nil || response.Code != http.StatusUnprocessableEntity || response.Message != test.message {
+			t.Errorf("%s: parseCustomRole() = %+v, want %d %s", test.name, response, http.StatusUnprocessableEntity, test.message)
+		}
+	}
+}
+
+func TestCustomRolesAreLimitedToTheGrantor(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	origin := auditService.Origin{ActorID: owner.ID}
+
+	// Manages the members without holding the permissions of an owner
+	manager := permissionTypes.NewSet(roleTypes.Editor.Permissions()...)
+	manager.Add(permissionTypes.ManageMembers)
+
+	created := CreateCustomRole(origin, manager, views.CreateCustomRolePayload{
+		Name:        "Auditor",
+		Permissions: []permissionTypes.Permission{permissionTypes.ViewAudit},
+		BoardID:     board.ID,
+	})
+	if created.Code != http.StatusForbidden {
+		t.Errorf("creating a role with a permission that is not held = %d %s, want %d", created.Code, created.Message, http.StatusForbidden)
+	}
+
+	tagger := CreateCustomRole(origin, manager, views.CreateCustomRolePayload{
+		Name:        "Tagger",
+		Permissions: []permissionTypes.Permission{permissionTypes.ManageTags},
+		BoardID:     board.ID,
+	})
+	if tagger.Code != http.StatusOK {
+		t.Fatalf("creating a role with a permission that is held = %d %s, want %d", tagger.Code, tagger.Message, http.StatusOK)
+	}
+	updated := UpdateCustomRole(origin, manager, views.UpdateCustomRolePayload{
+		ID:          tagger.Role.ID,
+		Name:        "Tagger",
+		Permissions: []permissionTypes.Permission{permissionTypes.ManageTags, permissionTypes.ViewAudit},
+		BoardID:     board.ID,
+	})
+	if updated.Code != http.StatusForbidden {
+		t.Errorf("adding a permission that is not held = %d %s, want %d", updated.Code, updated.Message, http.StatusForbidden)
+	}
+
+	// Roles with permissions that are not held can neither be reduced nor deleted
+	auditor := CreateCustomRole(origin, ownerPermissions, views.CreateCustomRolePayload{
+		Name:        "Auditor",
+		Permissions: []permissionTypes.Permission{permissionTypes.ViewAudit, permissionTypes.ManageTags},
+		BoardID:     board.ID,
+	})
+	if auditor.Code != http.StatusOK {
+		t.Fatalf("creating a role as an owner = %d %s, want %d", auditor.Code, auditor.Message, http.StatusOK)
+	}
+	updated = UpdateCustomRole(origin, manager, views.UpdateCustomRolePayload{
+		ID:          auditor.Role.ID,
+		Name:        "Auditor",
+		Permissions: []permissionTypes.Permission{permissionTypes.ManageTags},
+		BoardID:     board.ID,
+	})
+	if updated.Code != http.StatusForbidden {
+		t.Errorf("removing a permission that is not held = %d %s, want %d", updated.Code, updated.Message, http.StatusForbidden)
+	}
+	deleted := DeleteCustomRole(origin, manager, views.DeleteCustomRolePayload{ID: auditor.Role.ID, BoardID: board.ID})
+	if deleted.Code != http.StatusForbidden {
+		t.Errorf("deleting a role with a permission that is not held = %d %s, want %d", deleted.Code, deleted.Message, http.StatusForbidden)
+	}
+}
+
+func TestCustomRolesGrantTheirPermissions(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	otherBoard, _ := dbTestUtils.CreateBoard(t, owner)
+	origin := auditService.Origin{ActorID: owner.ID}
+	viewer := dbTestUtils.CreateUser(t)
+	member := dbTestUtils.AddMember(t, board.ID, viewer, roleTypes.Viewer)
+
+	tagger := CreateCustomRole(origin, ownerPermissions, views.CreateCustomRolePayload{
+		Name:        "Tagger",
+		Permissions: []permissionTypes.Permission{permissionTypes.ManageTags},
+		BoardID:     board.ID,
+	})
+	if tagger.Code != http.StatusOK {
+		t.Fatalf("creating the role = %d %s, want %d", tagger.Code, tagger.Message, http.StatusOK)
+	}
+	if err := db.DB.Model(&models.Member{}).Where("id = ?", member.ID).Update("custom_role_id", tagger.Role.ID).Error; err != nil {
+		t.Fatalf("unable to assign the role: %v", err)
+	}
+
+	permissions, err := authorizationService.GetBoardPermissions(viewer.ID, board.ID)
+	if err != nil || !permissions.Has(permissionTypes.ManageTags) || permissions.Has(permissionTypes.EditTasks) {
+		t.Errorf("the viewer with the role holds %v (%v), want the viewer permissions and %s", permissions.List(), err, permissionTypes.ManageTags)
+	}
+
+	// The role of a board cannot be changed through another board
+	deleted := DeleteCustomRole(origin, ownerPermissions, views.DeleteCustomRolePayload{ID: tagger.Role.ID, BoardID: otherBoard.ID})
+	if deleted.Code != http.StatusUnprocessableEntity || deleted.Message != fmt.Sprintf(customRoleNotFoundMessage, tagger.Role.ID) {
+		t.Errorf("deleting the role through another board = %d %s, want %d", deleted.Code, deleted.Message, http.StatusUnprocessableEntity)
+	}
+
+	deleted = DeleteCustomRole(origin, ownerPermissions, views.DeleteCustomRolePayload{ID: tagger.Role.ID, BoardID: board.ID})
+	if deleted.Code != http.StatusOK {
+		t.Fatalf("deleting the role = %d %s, want %d", deleted.Code, deleted.Message, http.StatusOK)
+	}
+	permissions, err = authorizationService.GetBoardPermissions(viewer.ID, board.ID)
+	if err != nil || permissions.Has(permissionTypes.ManageTags) || !permissions.Has(permissionTypes.ViewBoard) {
+		t.Errorf("the viewer holds %v (%v) once the role is deleted, want the viewer permissions", permissions.List(), err)
+	}
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	invitationTypes "github.com/EmilyOng/tusk-manager/backend/types/invitation"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
+	mailUtils "github.com/EmilyOng/tusk-manager/backend/utils/mail"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	invitationDuration = 14 * 24 * time.Hour
+
+	unableToCreateInvitationMessage  = "Unable to invite '%s'."
+	unableToGetInvitationsMessage    = "Unable to retrieve the invitations for the board (%s)."
+	unableToRespondInvitationMessage = "Unable to respond to the invitation."
+	invalidInvitationMessage         = "The invitation is invalid or has expired."
+	otherInviteeMessage              = "The invitation was sent to another email address, please log in with that address."
+
+	successfullyCreatedInvitationMessage  = "An invitation has been sent to '%s'!"
+	successfullyAcceptedInvitationMessage = "Welcome to the board!"
+	successfullyDeclinedInvitationMessage = "The invitation has been declined."
+
+	invitationSubject = "You have been invited to a board on Tusk"
+	invitationBody    = "Hi,\n\n%s has invited you to the board '%s' as a %s. Sign up with this email address, or open the link below:\n%s\n\nThe invitation expires in 14 days. If you were not expecting it, you can decline it or ignore this email."
+)
+
+var (
+	errInvalidInvitation = errors.New("invalid invitation")
+	errOtherInvitee      = errors.New("the invitation was sent to another email")
+)
+
+// Adds the user to the board of the invitation, unless the user is already a member
+func addMember(tx *gorm.DB, invitation models.Invitation, userID string) (member models.Member, err error) {
+	err = tx.Where("user_id = ? AND board_id = ?", userID, invitation.BoardID).First(&member).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+
+	member = models.Member{
+		Role:    invitation.Role,
+		UserID:  userID,
+		BoardID: invitation.BoardID,
+	}
+	err = tx.Create(&member).Error
+	return
+}
+
+// Marks the invitation as responded, and records the response
+func respond(tx *gorm.DB, origin auditService.Origin, invitation *models.Invitation, status invitationTypes.Status) error {
+	now := time.Now()
+	invitation.Status = status
+	invitation.RespondedAt = &now
+	err := tx.Model(invitation).Select("status", "responded_at").Updates(invitation).Error
+	if err != nil {
+		return err
+	}
+
+	action := auditTypes.InvitationAccepted
+	if status == invitationTypes.Declined {
+		action = auditTypes.InvitationDeclined
+	}
+	return auditService.RecordTx(tx, origin, auditService.Event{
+		Action:     action,
+		TargetType: "invitation",
+		TargetID:   invitation.ID,
+		BoardID:    invitation.BoardID,
+		Details:    map[string]interface{}{"email": invitation.Email, "role": invitation.Role},
+	})
+}
+
+// Finds the pending invitation of the token, locking it for the response
+func findPendingInvitation(tx *gorm.DB, token string) (invitation models.Invitation, err error) {
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", authUtils.HashOpaqueToken(token)).
+		First(&invitation).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) ||
+		(err == nil && (invitation.Status != invitationTypes.Pending || invitation.ExpiresAt.Before(time.Now()))) {
+		err = errInvalidInvitation
+	}
+	return
+}
+
+// Invites an email that is not registered, replacing any pending invitation of the email to the board
+func CreateInvitation(origin auditService.Origin, payload views.CreateMemberPayload) views.CreateMemberResponse {
+	email := strings.ToLower(strings.TrimSpace(payload.Email))
+
+	var invitation models.Invitation
+	var token string
+	var board models.Board
+	var inviter models.User
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", payload.BoardID).First(&board).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("id = ?", origin.ActorID).First(&inviter).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("email = ? AND board_id = ? AND status = ?", email, payload.BoardID, invitationTypes.Pending).
+			Delete(&models.Invitation{}).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var hash string
+		token, hash, err = authUtils.GenerateOpaqueToken()
+		if err != nil {
+			return err
+		}
+		invitation = models.Invitation{
+			Email:     email,
+			Role:      payload.Role,
+			Status:    invitationTypes.Pending,
+			TokenHash: hash,
+			ExpiresAt: time.Now().Add(invitationDuration),
+			BoardID:   payload.BoardID,
+			InviterID: origin.ActorID,
+		}
+		err = tx.Create(&invitation).Error
+		if err != nil {
+			return err
+		}
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.MemberInvited,
+			TargetType: "invitation",
+			TargetID:   invitation.ID,
+			BoardID:    invitation.BoardID,
+			Details:    map[string]interface{}{"email": invitation.Email, "role": invitation.Role},
+		})
+	})
+	if err != nil {
+		return views.CreateMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateInvitationMessage, payload.Email),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	link := fmt.Sprintf("%s/invitations?token=%s", commonUtils.GetFrontendUrl(), token)
+	err = mailUtils.Send(mailUtils.Message{
+		To:      invitation.Email,
+		Subject: invitationSubject,
+		Body:    fmt.Sprintf(invitationBody, inviter.Name, board.Name, invitation.Role, link),
+	})
+	if err != nil {
+		log.Println("Unable to send invitation email", err)
+	}
+
+	return views.CreateMemberResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedInvitationMessage, invitation.Email),
+			Code:    http.StatusOK,
+		},
+		Invitation: &invitation,
+	}
+}
+
+// Lists the pending invitations of the board that have not expired
+func GetBoardInvitations(payload views.GetBoardInvitationsPayload) views.GetBoardInvitationsResponse {
+	var invitations []views.InvitationView
+	err := db.DB.Model(&models.Invitation{}).
+		Where("board_id = ? AND status = ? AND expires_at > ?", payload.BoardID, invitationTypes.Pending, time.Now()).
+		Order("created_at").
+		Find(&invitations).
+		Error
+	if err != nil {
+		return views.GetBoardInvitationsResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToGetInvitationsMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.GetBoardInvitationsResponse{
+		Response:    views.Response{Code: http.StatusOK},
+		Invitations: invitations,
+	}
+}
+
+// Accepts the invitation on behalf of the actor, who received the token by email. The invitation is only for
+// the email that it was sent to, so that a forwarded or leaked link cannot be used by another account.
+func AcceptInvitation(origin auditService.Origin, payload views.AcceptInvitationPayload) views.AcceptInvitationResponse {
+	var member models.Member
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		invitation, err := findPendingInvitation(tx, payload.Token)
+		if err != nil {
+			return err
+		}
+
+		var actor models.User
+		err = tx.Where("id = ?", origin.ActorID).First(&actor).Error
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(actor.Email, invitation.Email) {
+			return errOtherInvitee
+		}
+
+		member, err = addMember(tx, invitation, origin.ActorID)
+		if err != nil {
+			return err
+		}
+		return respond(tx, origin, &invitation, invitationTypes.Accepted)
+	})
+
+	if errors.Is(err, errInvalidInvitation) {
+		return views.AcceptInvitationResponse{
+			Response: views.Response{
+				Message: invalidInvitationMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errOtherInvitee) {
+		return views.AcceptInvitationResponse{
+			Response: views.Response{
+				Message: otherInviteeMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if err != nil {
+		return views.AcceptInvitationResponse{
+			Response: views.Response{
+				Message: unableToRespondInvitationMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.AcceptInvitationResponse{
+		Response: views.Response{
+			Message: successfullyAcceptedInvitationMessage,
+			Code:    http.StatusOK,
+		},
+		Member: views.MemberMinimalView{
+			ID:      member.ID,
+			Role:    member.Role,
+			UserID:  member.UserID,
+			BoardID: member.BoardID,
+		},
+	}
+}
+
+// Declines the invitation, which does not require an account
+func DeclineInvitation(origin auditService.Origin, payload views.DeclineInvitationPayload) views.DeclineInvitationResponse {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		invitation, err := findPendingInvitation(tx, payload.Token)
+		if err != nil {
+			return err
+		}
+		return respond(tx, origin, &invitation, invitationTypes.Declined)
+	})
+
+	if errors.Is(err, errInvalidInvitation) {
+		return views.DeclineInvitationResponse{
+			Response: views.Response{
+				Message: invalidInvitationMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.DeclineInvitationResponse{
+			Response: views.Response{
+				Message: unableToRespondInvitationMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeclineInvitationResponse{
+		Response: views.Response{
+			Message: successfullyDeclinedInvitationMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Turns the pending invitations of the user's email into memberships
+func AcceptPendingInvitationsTx(tx *gorm.DB, origin auditService.Origin, user models.User) error {
+	var invitations []models.Invitation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("email = ? AND status = ? AND expires_at > ?", strings.ToLower(user.Email), invitationTypes.Pending, time.Now()).
+		Find(&invitations).
+		Error
+	if err != nil {
+		return err
+	}
+
+	origin.ActorID = user.ID
+	for i := range invitations {
+		_, err = addMember(tx, invitations[i], user.ID)
+		if err != nil {
+			return err
+		}
+		err = respond(tx, origin, &invitations[i], invitationTypes.Accepted)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Accepts the pending invitations outside of a transaction, where failures are logged rather than returned
+func AcceptPendingInvitations(origin auditService.Origin, user models.User) {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		return AcceptPendingInvitationsTx(tx, origin, user)
+	})
+	if err != nil {
+		log.Println("Unable to accept pending invitations", err)
+	}
+}
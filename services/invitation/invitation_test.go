@@ -0,0 +1,126 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	invitationTypes "github.com/EmilyOng/tusk-manager/backend/types/invitation"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+)
+
+// Invites the email to the board, returning the token that would be sent by email
+func createTestInvitation(t *testing.T, board models.Board, inviter models.User, email string, expiresAt time.Time) string {
+	t.Helper()
+	token, hash, err := authUtils.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("unable to generate the token: %v", err)
+	}
+	invitation := models.Invitation{
+		Email:     strings.ToLower(email),
+		Role:      roleTypes.Editor,
+		Status:    invitationTypes.Pending,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+		BoardID:   board.ID,
+		InviterID: inviter.ID,
+	}
+	if err := db.DB.Create(&invitation).Error; err != nil {
+		t.Fatalf("unable to create the invitation: %v", err)
+	}
+	return token
+}
+
+func countMembers(t *testing.T, boardID string, userID string) int64 {
+	t.Helper()
+	var members int64
+	if err := db.DB.Model(&models.Member{}).Where("board_id = ? AND user_id = ?", boardID, userID).Count(&members).Error; err != nil {
+		t.Fatalf("unable to count the members: %v", err)
+	}
+	return members
+}
+
+func TestAcceptInvitationOnlyByTheInvitee(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	invitee := dbTestUtils.CreateUser(t)
+	other := dbTestUtils.CreateUser(t)
+	token := createTestInvitation(t, board, owner, invitee.Email, time.Now().Add(time.Hour))
+
+	forwarded := AcceptInvitation(auditService.Origin{ActorID: other.ID}, views.AcceptInvitationPayload{Token: token})
+	if forwarded.Code != http.StatusForbidden || forwarded.Message != otherInviteeMessage {
+		t.Errorf("accepting the invitation of another email = %d %s, want %d", forwarded.Code, forwarded.Message, http.StatusForbidden)
+	}
+	if countMembers(t, board.ID, other.ID) > 0 {
+		t.Fatalf("the invitation of another email added the actor to the board")
+	}
+
+	accepted := AcceptInvitation(auditService.Origin{ActorID: invitee.ID}, views.AcceptInvitationPayload{Token: token})
+	if accepted.Code != http.StatusOK || accepted.Member.Role != roleTypes.Editor || accepted.Member.UserID != invitee.ID {
+		t.Fatalf("accepting the invitation = %d %s with %+v, want an editor", accepted.Code, accepted.Message, accepted.Member)
+	}
+
+	// The invitation can only be used once
+	again := AcceptInvitation(auditService.Origin{ActorID: invitee.ID}, views.AcceptInvitationPayload{Token: token})
+	if again.Code != http.StatusUnprocessableEntity || again.Message != invalidInvitationMessage {
+		t.Errorf("accepting the invitation again = %d %s, want %d", again.Code, again.Message, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestRespondOnlyToPendingInvitations(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	invitee := dbTestUtils.CreateUser(t)
+	origin := auditService.Origin{ActorID: invitee.ID}
+
+	expired := createTestInvitation(t, board, owner, invitee.Email, time.Now().Add(-time.Minute))
+	declined := createTestInvitation(t, board, owner, invitee.Email, time.Now().Add(time.Hour))
+	if response := DeclineInvitation(auditService.Origin{}, views.DeclineInvitationPayload{Token: declined}); response.Code != http.StatusOK {
+		t.Fatalf("declining the invitation = %d %s, want %d", response.Code, response.Message, http.StatusOK)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: expired},
+		{name: "declined", token: declined},
+		{name: "unknown", token: "unknown"},
+	}
+	for _, test := range tests {
+		response := AcceptInvitation(origin, views.AcceptInvitationPayload{Token: test.token})
+		if response.Code != http.StatusUnprocessableEntity || response.Message != invalidInvitationMessage {
+			t.Errorf("%s: AcceptInvitation() = %d %s, want %d", test.name, response.Code, response.Message, http.StatusUnprocessableEntity)
+		}
+	}
+	if countMembers(t, board.ID, invitee.ID) > 0 {
+		t.Errorf("an invitation that is not pending added the invitee to the board")
+	}
+}
+
+func TestAcceptPendingInvitationsOfTheEmail(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	expiredBoard, _ := dbTestUtils.CreateBoard(t, owner)
+	invitee := dbTestUtils.CreateUser(t)
+	createTestInvitation(t, board, owner, invitee.Email, time.Now().Add(time.Hour))
+	createTestInvitation(t, expiredBoard, owner, invitee.Email, time.Now().Add(-time.Minute))
+
+	AcceptPendingInvitations(auditService.Origin{}, invitee)
+	if countMembers(t, board.ID, invitee.ID) != 1 {
+		t.Errorf("the pending invitation did not add the invitee to the board")
+	}
+	if countMembers(t, expiredBoard.ID, invitee.ID) > 0 {
+		t.Errorf("the expired invitation added the invitee to the board")
+	}
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	accountService "github.com/EmilyOng/tusk-manager/backend/services/account"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
+	datetime "github.com/EmilyOng/tusk-manager/backend/utils/datetime"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultInviteLinkDuration = 7 * 24 * time.Hour
+
+	unableToCreateInviteLinkMessage = "Unable to create the invite link."
+	unableToGetInviteLinksMessage   = "Unable to retrieve the invite links for the board (%s)."
+	unableToRevokeInviteLinkMessage = "Unable to revoke the invite link (%s)."
+	unableToRedeemInviteLinkMessage = "Unable to join the board."
+	inviteLinkNotFoundMessage       = "The invite link cannot be found (%s)."
+	invalidInviteLinkMessage        = "The invite link is invalid, has expired or has been used up."
+	invalidRoleMessage              = "The role '%s' is not valid."
+	invalidExpiryMessage            = "The expiry '%s' is not valid."
+	invalidMaxUsesMessage           = "The maximum number of uses must be at least 1."
+	userNotVerifiedMessage          = "Please verify your email before joining the board."
+	permissionNotHeldMessage        = "You cannot grant permissions that you do not hold."
+
+	successfullyCreatedInviteLinkMessage  = "Successfully created the invite link! Copy it now, as it will not be shown again."
+	successfullyRevokedInviteLinkMessage  = "Successfully revoked the invite link!"
+	successfullyRedeemedInviteLinkMessage = "Welcome to the board!"
+)
+
+var errInvalidInviteLink = errors.New("invalid invite link")
+
+func toView(inviteLink models.InviteLink) views.InviteLinkView {
+	return views.InviteLinkView{
+		ID:        inviteLink.ID,
+		Role:      inviteLink.Role,
+		ExpiresAt: inviteLink.ExpiresAt,
+		MaxUses:   inviteLink.MaxUses,
+		Uses:      inviteLink.Uses,
+		RevokedAt: inviteLink.RevokedAt,
+		CreatedAt: inviteLink.CreatedAt,
+		BoardID:   inviteLink.BoardID,
+	}
+}
+
+// Creates a link to join the board with the role. The grantor must hold the permissions of the role.
+func CreateInviteLink(origin auditService.Origin, grantor permissionTypes.Set, payload views.CreateInviteLinkPayload) views.CreateInviteLinkResponse {
+	if !payload.Role.IsValid() {
+		return views.CreateInviteLinkResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if !grantor.Includes(permissionTypes.NewSet(payload.Role.Permissions()...)) {
+		return views.CreateInviteLinkResponse{
+			Response: views.Response{
+				Message: permissionNotHeldMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if payload.MaxUses != nil && *payload.MaxUses < 1 {
+		return views.CreateInviteLinkResponse{
+			Response: views.Response{
+				Message: invalidMaxUsesMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	expiresAt := time.Now().Add(defaultInviteLinkDuration)
+	if len(payload.ExpiresAt) > 0 {
+		var err error
+		expiresAt, err = time.Parse(datetime.DatetimeLayout, payload.ExpiresAt)
+		if err != nil || expiresAt.Before(time.Now()) {
+			return views.CreateInviteLinkResponse{
+				Response: views.Response{
+					Message: fmt.Sprintf(invalidExpiryMessage, payload.ExpiresAt),
+					Code:    http.StatusUnprocessableEntity,
+				},
+			}
+		}
+	}
+
+	token, hash, err := authUtils.GenerateOpaqueToken()
+	inviteLink := models.InviteLink{
+		Role:        payload.Role,
+		TokenHash:   hash,
+		ExpiresAt:   expiresAt,
+		MaxUses:     payload.MaxUses,
+		BoardID:     payload.BoardID,
+		CreatedByID: origin.ActorID,
+	}
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Create(&inviteLink).Error
+			if err != nil {
+				return err
+			}
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.InviteLinkCreated,
+				TargetType: "invite_link",
+				TargetID:   inviteLink.ID,
+				BoardID:    inviteLink.BoardID,
+				Details:    map[string]interface{}{"role": inviteLink.Role, "maxUses": inviteLink.MaxUses},
+			})
+		})
+	}
+	if err != nil {
+		return views.CreateInviteLinkResponse{
+			Response: views.Response{
+				Message: unableToCreateInviteLinkMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	inviteLinkView := toView(inviteLink)
+	inviteLinkView.URL = fmt.Sprintf("%s/join?token=%s", commonUtils.GetFrontendUrl(), token)
+	return views.CreateInviteLinkResponse{
+		Response: views.Response{
+			Message: successfullyCreatedInviteLinkMessage,
+			Code:    http.StatusOK,
+		},
+		InviteLink: inviteLinkView,
+	}
+}
+
+// Lists the links of the board that can still be redeemed
+func GetInviteLinks(payload views.GetInviteLinksPayload) views.GetInviteLinksResponse {
+	var inviteLinks []models.InviteLink
+	err := db.DB.Model(&models.InviteLink{}).
+		Where("board_id = ? AND revoked_at IS NULL AND expires_at > ?", payload.BoardID, time.Now()).
+		Where("max_uses IS NULL OR uses < max_uses").
+		Order("created_at").
+		Find(&inviteLinks).
+		Error
+	if err != nil {
+		return views.GetInviteLinksResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToGetInviteLinksMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	var inviteLinksView []views.InviteLinkView
+	for _, inviteLink := range inviteLinks {
+		inviteLinksView = append(inviteLinksView, toView(inviteLink))
+	}
+	return views.GetInviteLinksResponse{
+		Response:    views.Response{Code: http.StatusOK},
+		InviteLinks: inviteLinksView,
+	}
+}
+
+func RevokeInviteLink(origin auditService.Origin, payload views.RevokeInviteLinkPayload) views.RevokeInviteLinkResponse {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var inviteLink models.InviteLink
+		err := tx.Where("id = ? AND board_id = ? AND revoked_at IS NULL", payload.ID, payload.BoardID).
+			First(&inviteLink).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&inviteLink).Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.InviteLinkRevoked,
+			TargetType: "invite_link",
+			TargetID:   inviteLink.ID,
+			BoardID:    inviteLink.BoardID,
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.RevokeInviteLinkResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(inviteLinkNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.RevokeInviteLinkResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToRevokeInviteLinkMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.RevokeInviteLinkResponse{
+		Response: views.Response{
+			Message: successfullyRevokedInviteLinkMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Adds the actor to the board of the link. The link is locked, so that concurrent
+// redemptions cannot exceed the maximum number of uses.
+func RedeemInviteLink(origin auditService.Origin, payload views.RedeemInviteLinkPayload) views.RedeemInviteLinkResponse {
+	var user models.User
+	err := db.DB.Where("id = ?", origin.ActorID).First(&user).Error
+	if err == nil && !user.EmailVerified && accountService.GetVerificationPolicy().RequiresForInvite() {
+		return views.RedeemInviteLinkResponse{
+			Response: views.Response{
+				Message: userNotVerifiedMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
+	var member models.Member
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			var inviteLink models.InviteLink
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("token_hash = ?", authUtils.HashOpaqueToken(payload.Token)).
+				First(&inviteLink).
+				Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvalidInviteLink
+			}
+			if err != nil {
+				return err
+			}
+			if inviteLink.RevokedAt != nil ||
+				inviteLink.ExpiresAt.Before(time.Now()) ||
+				(inviteLink.MaxUses != nil && inviteLink.Uses >= *inviteLink.MaxUses) {
+				return errInvalidInviteLink
+			}
+
+			// Existing members do not use up the link
+			err = tx.Where("user_id = ? AND board_id = ?", user.ID, inviteLink.BoardID).First(&member).Error
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			member = models.Member{
+				Role:    inviteLink.Role,
+				UserID:  user.ID,
+				BoardID: inviteLink.BoardID,
+			}
+			err = tx.Create(&member).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&inviteLink).Update("uses", gorm.Expr("uses + 1")).Error
+			if err != nil {
+				return err
+			}
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.InviteLinkRedeemed,
+				TargetType: "member",
+				TargetID:   member.ID,
+				BoardID:    member.BoardID,
+				Details:    map[string]interface{}{"inviteLinkId": inviteLink.ID, "role": member.Role},
+			})
+		})
+	}
+
+	if errors.Is(err, errInvalidInviteLink) {
+		return views.RedeemInviteLinkResponse{
+			Response: views.Response{
+				Message: invalidInviteLinkMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.RedeemInviteLinkResponse{
+			Response: views.Response{
+				Message: unableToRedeemInviteLinkMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.RedeemInviteLinkResponse{
+		Response: views.Response{
+			Message: successfullyRedeemedInviteLinkMessage,
+			Code:    http.StatusOK,
+		},
+		Member: views.MemberMinimalView{
+			ID:      member.ID,
+			Role:    member.Role,
+			UserID:  member.UserID,
+			BoardID: member.BoardID,
+		},
+	}
+}
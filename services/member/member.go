@@ -7,9 +7,19 @@ import (
 
 	"github.com/EmilyOng/tusk-manager/backend/db"
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	accountService "github.com/EmilyOng/tusk-manager/backend/services/account"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	boardService "github.com/EmilyOng/tusk-manager/backend/services/board"
+	invitationService "github.com/EmilyOng/tusk-manager/backend/services/invitation"
+	taskService "github.com/EmilyOng/tusk-manager/backend/services/task"
 	userService "github.com/EmilyOng/tusk-manager/backend/services/user"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -19,10 +29,36 @@ const (
 	unableToDeleteMemberMessage = "Unable to delete member (%s)."
 	memberAlreadyExistsMessage  = "The member '%s' already exists."
 	memberNotFoundMessage       = "The member cannot be found (%s)."
+	notMemberMessage            = "You are not a member of this board."
+	invalidRoleMessage          = "The role '%s' is not valid."
+	memberNotVerifiedMessage    = "The user '%s' has not verified their email yet."
+	lastOwnerMessage            = "The board must have at least one owner, please transfer the ownership first."
+	leaveAsLastOwnerMessage     = "You are the last owner of the board, please nominate a successor or delete the board."
+	notOwnerMessage             = "Only an owner can transfer the ownership of the board."
+	transferToSelfMessage       = "The ownership cannot be transferred to yourself."
+	customRoleNotFoundMessage   = "The custom role cannot be found on the board (%s)."
+	customRoleInviteeMessage    = "Custom roles can only be assigned once '%s' has joined the board."
+	permissionNotHeldMessage    = "You cannot grant or revoke permissions that you do not hold."
+
+	unableToTransferOwnershipMessage = "Unable to transfer the ownership to member (%s)."
+	unableToLeaveBoardMessage        = "Unable to leave the board (%s)."
+	unableToVerifyGrantMessage       = "Unable to verify the permissions to grant."
 
 	successfullyCreatedMemberMessage = "Board has been shared with '%s'!"
 	successfullyUpdatedMemberMessage = "Successfully updated member '%s'!"
 	successfullyDeletedMemberMessage = "Successfully deleted member '%s'!"
+
+	successfullyTransferredOwnershipMessage = "Successfully transferred the ownership to '%s'!"
+	successfullyLeftBoardMessage            = "Successfully left the board!"
+	successfullyLeftAndDeletedBoardMessage  = "Successfully left and deleted the board '%s'!"
+)
+
+var (
+	errLastOwner  = errors.New("the board must have at least one owner")
+	errNotOwner   = errors.New("the actor is not an owner of the board")
+	errSameMember = errors.New("the successor is the actor")
+	errNotGranted = errors.New("the grantor cannot grant the role")
+	errNotMember  = errors.New("the actor is not a member of the board")
 )
 
 func FindMember(memberID string) (member models.Member, err error) {
@@ -30,30 +66,133 @@ func FindMember(memberID string) (member models.Member, err error) {
 	return
 }
 
-func UpdateMember(payload views.UpdateMemberPayload) views.UpdateMemberResponse {
-	member, err := FindMember(payload.ID)
+// Reads the member, locking it until the transaction ends so that its role cannot change in between
+func lockMember(tx *gorm.DB, memberID string) (member models.Member, err error) {
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", memberID).First(&member).Error
+	return
+}
 
+// Ensures that the grantor holds the permissions of the grant, describing why not otherwise
+func checkGrant(boardID string, grantor permissionTypes.Set, role roleTypes.Role, customRoleID *string) *views.Response {
+	err := authorizationService.CheckGrant(db.DB, boardID, grantor, role, customRoleID)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &views.Response{
+			Message: fmt.Sprintf(customRoleNotFoundMessage, *customRoleID),
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+	if errors.Is(err, authorizationService.ErrPermissionNotHeld) {
+		return &views.Response{
+			Message: permissionNotHeldMessage,
+			Code:    http.StatusForbidden,
+		}
+	}
+	return &views.Response{
+		Message: unableToVerifyGrantMessage,
+		Code:    http.StatusInternalServerError,
+	}
+}
+
+// Ensures that another owner remains on the board once the member is no longer an owner.
+// The owners are locked, so that concurrent changes cannot remove every owner.
+func ensureOtherOwner(tx *gorm.DB, boardID string, memberID string) error {
+	var owners []models.Member
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("board_id = ? AND role = ?", boardID, roleTypes.Owner).
+		Find(&owners).
+		Error
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return views.UpdateMemberResponse{
-				Response: views.Response{
-					Message: fmt.Sprintf(memberNotFoundMessage, payload.ID),
-					Code:    http.StatusUnprocessableEntity,
-				},
-			}
+		return err
+	}
+	for _, owner := range owners {
+		if owner.ID != memberID {
+			return nil
 		}
+	}
+	return errLastOwner
+}
 
+// Changes the role of the member. The grantor must hold the permissions of both the previous and the new role.
+func UpdateMember(origin auditService.Origin, grantor permissionTypes.Set, payload views.UpdateMemberPayload) views.UpdateMemberResponse {
+	if !payload.Role.IsValid() {
 		return views.UpdateMemberResponse{
 			Response: views.Response{
-				Message: fmt.Sprintf(unableToGetMemberMessage, payload.ID),
-				Code:    http.StatusInternalServerError,
+				Message: fmt.Sprintf(invalidRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
 			},
 		}
 	}
 
-	// Update member's role
-	member.Role = payload.Role
-	err = db.DB.Save(&member).Error
+	var member models.Member
+	var previousRole roleTypes.Role
+	var previousCustomRoleID *string
+	var response *views.Response
+	err := db.DB.Transaction(func(tx *gorm.DB) (err error) {
+		// The grant is checked against the role that is being replaced
+		member, err = lockMember(tx, payload.ID)
+		if err != nil {
+			return err
+		}
+		response = checkGrant(member.BoardID, grantor, member.Role, member.CustomRoleID)
+		if response == nil {
+			response = checkGrant(member.BoardID, grantor, payload.Role, payload.CustomRoleID)
+		}
+		if response != nil {
+			return errNotGranted
+		}
+
+		// Update member's role
+		previousRole = member.Role
+		previousCustomRoleID = member.CustomRoleID
+		member.Role = payload.Role
+		member.CustomRoleID = payload.CustomRoleID
+		if previousRole == roleTypes.Owner && member.Role != roleTypes.Owner {
+			err := ensureOtherOwner(tx, member.BoardID, member.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Save(&member).Error
+		if err != nil || previousRole == member.Role && sameCustomRole(previousCustomRoleID, member.CustomRoleID) {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.MemberRoleChanged,
+			TargetType: "member",
+			TargetID:   member.ID,
+			BoardID:    member.BoardID,
+			Details: map[string]interface{}{
+				"userId":         member.UserID,
+				"from":           previousRole,
+				"to":             member.Role,
+				"fromCustomRole": previousCustomRoleID,
+				"toCustomRole":   member.CustomRoleID,
+			},
+		})
+	})
+	if errors.Is(err, errNotGranted) {
+		return views.UpdateMemberResponse{Response: *response}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UpdateMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(memberNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errLastOwner) {
+		return views.UpdateMemberResponse{
+			Response: views.Response{
+				Message: lastOwnerMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
 	if err != nil {
 		return views.UpdateMemberResponse{
 			Response: views.Response{
@@ -79,47 +218,43 @@ func UpdateMember(payload views.UpdateMemberPayload) views.UpdateMemberResponse
 			Code:    http.StatusOK,
 		},
 		Member: views.MemberFullView{
-			ID:   member.ID,
-			Role: member.Role,
-			User: user,
+			ID:           member.ID,
+			Role:         member.Role,
+			CustomRoleID: member.CustomRoleID,
+			User:         user,
 		},
 	}
 }
 
-func DeleteMember(payload views.DeleteMemberPayload) views.DeleteMemberResponse {
-	member, err := FindMember(payload.ID)
+func sameCustomRole(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
 
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return views.DeleteMemberResponse{
-				Response: views.Response{
-					Message: fmt.Sprintf(memberNotFoundMessage, payload.ID),
-					Code:    http.StatusUnprocessableEntity,
-				},
-			}
+// Removes the member from the board. The grantor must hold the permissions of the member's role.
+func DeleteMember(origin auditService.Origin, grantor permissionTypes.Set, payload views.DeleteMemberPayload) views.DeleteMemberResponse {
+	var member models.Member
+	var response *views.Response
+	err := db.DB.Transaction(func(tx *gorm.DB) (err error) {
+		member, err = lockMember(tx, payload.ID)
+		if err != nil {
+			return err
 		}
-
-		return views.DeleteMemberResponse{
-			Response: views.Response{
-				Message: fmt.Sprintf(unableToDeleteMemberMessage, payload.ID),
-				Code:    http.StatusInternalServerError,
-			},
+		response = checkGrant(member.BoardID, grantor, member.Role, member.CustomRoleID)
+		if response != nil {
+			return errNotGranted
 		}
-	}
 
-	var user views.UserMinimalView
-	err = db.DB.Model(&models.User{}).Where("id = ?", member.UserID).Find(&user).Error
-	if err != nil {
-		return views.DeleteMemberResponse{
-			Response: views.Response{
-				Message: fmt.Sprintf(unableToGetMemberMessage, payload.ID),
-				Code:    http.StatusInternalServerError,
-			},
+		if member.Role == roleTypes.Owner {
+			err = ensureOtherOwner(tx, member.BoardID, member.ID)
+			if err != nil {
+				return err
+			}
 		}
-	}
 
-	err = db.DB.Transaction(func(tx *gorm.DB) error {
-		err := tx.Model(&models.Board{ID: member.BoardID}).
+		err = tx.Model(&models.Board{ID: member.BoardID}).
 			Association("Members").
 			Delete(&member)
 		if err != nil {
@@ -134,9 +269,42 @@ func DeleteMember(payload views.DeleteMemberPayload) views.DeleteMemberResponse
 		}
 
 		err = tx.Delete(&member).Error
-		return err
+		if err != nil {
+			return err
+		}
+		err = taskService.UnassignInaccessibleTx(tx, []string{member.BoardID})
+		if err != nil {
+			return err
+		}
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.MemberRemoved,
+			TargetType: "member",
+			TargetID:   member.ID,
+			BoardID:    member.BoardID,
+			Details:    map[string]interface{}{"userId": member.UserID, "role": member.Role},
+		})
 	})
 
+	if errors.Is(err, errNotGranted) {
+		return views.DeleteMemberResponse{Response: *response}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.DeleteMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(memberNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errLastOwner) {
+		return views.DeleteMemberResponse{
+			Response: views.Response{
+				Message: lastOwnerMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
 	if err != nil {
 		return views.DeleteMemberResponse{
 			Response: views.Response{
@@ -146,6 +314,17 @@ func DeleteMember(payload views.DeleteMemberPayload) views.DeleteMemberResponse
 		}
 	}
 
+	var user views.UserMinimalView
+	err = db.DB.Model(&models.User{}).Where("id = ?", member.UserID).Find(&user).Error
+	if err != nil {
+		return views.DeleteMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToGetMemberMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
 	return views.DeleteMemberResponse{
 		Response: views.Response{
 			Message: fmt.Sprintf(successfullyDeletedMemberMessage, user.Email),
@@ -154,14 +333,41 @@ func DeleteMember(payload views.DeleteMemberPayload) views.DeleteMemberResponse
 	}
 }
 
-func CreateMember(payload views.CreateMemberPayload) views.CreateMemberResponse {
+// Shares the board with the user. The grantor must hold the permissions of the role.
+func CreateMember(origin auditService.Origin, grantor permissionTypes.Set, payload views.CreateMemberPayload) views.CreateMemberResponse {
+	if !payload.Role.IsValid() {
+		return views.CreateMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	response := checkGrant(payload.BoardID, grantor, payload.Role, payload.CustomRoleID)
+	if response != nil {
+		return views.CreateMemberResponse{Response: *response}
+	}
+
 	// Check validity of invitee's email
 	user, err := userService.FindUser(payload.Email)
 
+	if errors.Is(err, gorm.ErrRecordNotFound) && payload.CustomRoleID != nil {
+		return views.CreateMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(customRoleInviteeMessage, payload.Email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
 	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The invitee joins the board once they sign up
+		return invitationService.CreateInvitation(origin, payload)
+	}
+
+	if !user.EmailVerified && accountService.GetVerificationPolicy().RequiresForInvite() {
 		return views.CreateMemberResponse{
 			Response: views.Response{
-				Message: fmt.Sprintf(memberNotFoundMessage, payload.Email),
+				Message: fmt.Sprintf(memberNotVerifiedMessage, payload.Email),
 				Code:    http.StatusUnprocessableEntity,
 			},
 		}
@@ -184,11 +390,25 @@ func CreateMember(payload views.CreateMemberPayload) views.CreateMemberResponse
 	}
 
 	member := models.Member{
-		Role:    payload.Role,
-		UserID:  user.ID,
-		BoardID: payload.BoardID,
+		Role:         payload.Role,
+		UserID:       user.ID,
+		BoardID:      payload.BoardID,
+		CustomRoleID: payload.CustomRoleID,
 	}
-	err = db.DB.Create(&member).Error
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&member).Error
+		if err != nil {
+			return err
+		}
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.MemberInvited,
+			TargetType: "member",
+			TargetID:   member.ID,
+			BoardID:    member.BoardID,
+			Details:    map[string]interface{}{"userId": member.UserID, "role": member.Role, "customRole": member.CustomRoleID},
+		})
+	})
 	if err != nil {
 		return views.CreateMemberResponse{
 			Response: views.Response{
@@ -204,8 +424,9 @@ func CreateMember(payload views.CreateMemberPayload) views.CreateMemberResponse
 			Code:    http.StatusOK,
 		},
 		Member: views.MemberFullView{
-			ID:   member.ID,
-			Role: member.Role,
+			ID:           member.ID,
+			Role:         member.Role,
+			CustomRoleID: member.CustomRoleID,
 			User: views.UserMinimalView{
 				ID:    user.ID,
 				Name:  user.Name,
@@ -214,3 +435,249 @@ func CreateMember(payload views.CreateMemberPayload) views.CreateMemberResponse
 		},
 	}
 }
+
+// Makes the member an owner, while the actor (an owner) keeps the given role
+func TransferOwnership(origin auditService.Origin, payload views.TransferOwnershipPayload) views.TransferOwnershipResponse {
+	if len(payload.Role) == 0 {
+		payload.Role = roleTypes.Editor
+	}
+	if !payload.Role.IsValid() {
+		return views.TransferOwnershipResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var successor models.Member
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var owner models.Member
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("board_id = ? AND user_id = ?", payload.BoardID, origin.ActorID).
+			First(&owner).
+			Error
+		if err != nil {
+			return err
+		}
+		if owner.Role != roleTypes.Owner {
+			return errNotOwner
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND board_id = ?", payload.MemberID, payload.BoardID).
+			First(&successor).
+			Error
+		if err != nil {
+			return err
+		}
+		if successor.ID == owner.ID {
+			return errSameMember
+		}
+
+		successor.Role = roleTypes.Owner
+		owner.Role = payload.Role
+		err = tx.Save(&successor).Error
+		if err == nil {
+			err = tx.Save(&owner).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.OwnershipTransferred,
+			TargetType: "member",
+			TargetID:   successor.ID,
+			BoardID:    payload.BoardID,
+			Details:    map[string]interface{}{"from": owner.UserID, "to": successor.UserID, "previousOwnerRole": owner.Role},
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.TransferOwnershipResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(memberNotFoundMessage, payload.MemberID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errNotOwner) {
+		return views.TransferOwnershipResponse{
+			Response: views.Response{
+				Message: notOwnerMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if errors.Is(err, errSameMember) {
+		return views.TransferOwnershipResponse{
+			Response: views.Response{
+				Message: transferToSelfMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.TransferOwnershipResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToTransferOwnershipMessage, payload.MemberID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	var user views.UserMinimalView
+	err = db.DB.Model(&models.User{}).Where("id = ?", successor.UserID).Find(&user).Error
+	if err != nil {
+		return views.TransferOwnershipResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToGetMemberMessage, successor.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	return views.TransferOwnershipResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyTransferredOwnershipMessage, user.Email),
+			Code:    http.StatusOK,
+		},
+		Member: views.MemberFullView{
+			ID:   successor.ID,
+			Role: successor.Role,
+			User: user,
+		},
+	}
+}
+
+// Removes the actor from the board. The last owner must either nominate a successor or delete the board.
+func LeaveBoard(origin auditService.Origin, payload views.LeaveBoardPayload) views.LeaveBoardResponse {
+	var deletedBoard *models.Board
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var member models.Member
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("board_id = ? AND user_id = ?", payload.BoardID, origin.ActorID).
+			First(&member).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNotMember
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(payload.SuccessorID) > 0 && member.Role == roleTypes.Owner {
+			var successor models.Member
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND board_id = ?", payload.SuccessorID, payload.BoardID).
+				First(&successor).
+				Error
+			if err != nil {
+				return err
+			}
+			if successor.ID == member.ID {
+				return errSameMember
+			}
+
+			err = tx.Model(&successor).Update("role", roleTypes.Owner).Error
+			if err != nil {
+				return err
+			}
+			err = auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.OwnershipTransferred,
+				TargetType: "member",
+				TargetID:   successor.ID,
+				BoardID:    payload.BoardID,
+				Details:    map[string]interface{}{"from": member.UserID, "to": successor.UserID},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if member.Role == roleTypes.Owner {
+			err = ensureOtherOwner(tx, member.BoardID, member.ID)
+			if errors.Is(err, errLastOwner) && payload.DeleteBoard {
+				// The board is deleted together with its members instead
+				board, err := boardService.DeleteBoardTx(tx, origin, payload.BoardID)
+				deletedBoard = &board
+				return err
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Delete(&member).Error
+		if err != nil {
+			return err
+		}
+		err = taskService.UnassignInaccessibleTx(tx, []string{member.BoardID})
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.MemberLeft,
+			TargetType: "member",
+			TargetID:   member.ID,
+			BoardID:    member.BoardID,
+			Details:    map[string]interface{}{"userId": member.UserID, "role": member.Role},
+		})
+	})
+
+	if err == nil && deletedBoard != nil {
+		return views.LeaveBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(successfullyLeftAndDeletedBoardMessage, deletedBoard.Name),
+				Code:    http.StatusOK,
+			},
+		}
+	}
+	if errors.Is(err, errNotMember) {
+		return views.LeaveBoardResponse{
+			Response: views.Response{
+				Message: notMemberMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.LeaveBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(memberNotFoundMessage, payload.SuccessorID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errSameMember) {
+		return views.LeaveBoardResponse{
+			Response: views.Response{
+				Message: transferToSelfMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errLastOwner) {
+		return views.LeaveBoardResponse{
+			Response: views.Response{
+				Message: leaveAsLastOwnerMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.LeaveBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToLeaveBoardMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.LeaveBoardResponse{
+		Response: views.Response{
+			Message: successfullyLeftBoardMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
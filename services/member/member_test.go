@@ -0,0 +1,211 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+)
+
+var ownerPermissions = permissionTypes.NewSet(roleTypes.Owner.Permissions()...)
+
+func TestOwnerIsKept(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, ownerMember := dbTestUtils.CreateBoard(t, owner)
+	origin := auditService.Origin{ActorID: owner.ID}
+
+	demoted := UpdateMember(origin, ownerPermissions, views.UpdateMemberPayload{ID: ownerMember.ID, Role: roleTypes.Editor})
+	if demoted.Code != http.StatusUnprocessableEntity || demoted.Message != lastOwnerMessage {
+		t.Errorf("demoting the last owner = %d %s, want %d", demoted.Code, demoted.Message, http.StatusUnprocessableEntity)
+	}
+	deleted := DeleteMember(origin, ownerPermissions, views.DeleteMemberPayload{ID: ownerMember.ID})
+	if deleted.Code != http.StatusUnprocessableEntity || deleted.Message != lastOwnerMessage {
+		t.Errorf("deleting the last owner = %d %s, want %d", deleted.Code, deleted.Message, http.StatusUnprocessableEntity)
+	}
+	if member := dbTestUtils.FindMember(t, ownerMember.ID); member.Role != roleTypes.Owner {
+		t.Fatalf("the last owner is now %q, want %q", member.Role, roleTypes.Owner)
+	}
+
+	// Once there is another owner, either of them can step down
+	otherOwner := dbTestUtils.AddMember(t, board.ID, dbTestUtils.CreateUser(t), roleTypes.Owner)
+	demoted = UpdateMember(origin, ownerPermissions, views.UpdateMemberPayload{ID: ownerMember.ID, Role: roleTypes.Editor})
+	if demoted.Code != http.StatusOK {
+		t.Errorf("demoting an owner besides another = %d %s, want %d", demoted.Code, demoted.Message, http.StatusOK)
+	}
+	deleted = DeleteMember(origin, ownerPermissions, views.DeleteMemberPayload{ID: otherOwner.ID})
+	if deleted.Code != http.StatusUnprocessableEntity {
+		t.Errorf("deleting the owner that remains = %d %s, want %d", deleted.Code, deleted.Message, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestGrantsAreLimitedToTheGrantor(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, ownerMember := dbTestUtils.CreateBoard(t, owner)
+	origin := auditService.Origin{ActorID: owner.ID}
+
+	// Manages the members without holding the permissions of an owner
+	manager := permissionTypes.NewSet(roleTypes.Editor.Permissions()...)
+	manager.Add(permissionTypes.ManageMembers)
+
+	auditor := models.CustomRole{Name: "Auditor", Permissions: string(permissionTypes.ViewAudit), BoardID: &board.ID}
+	if err := db.DB.Create(&auditor).Error; err != nil {
+		t.Fatalf("unable to create the custom role: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		role         roleTypes.Role
+		newRole      roleTypes.Role
+		customRoleID *string
+		code         int
+	}{
+		{name: "promotes a viewer to an editor", role: roleTypes.Viewer, newRole: roleTypes.Editor, code: http.StatusOK},
+		{name: "demotes an editor to a viewer", role: roleTypes.Editor, newRole: roleTypes.Viewer, code: http.StatusOK},
+		{name: "promotes a viewer to an owner", role: roleTypes.Viewer, newRole: roleTypes.Owner, code: http.StatusForbidden},
+		{name: "demotes an owner", role: roleTypes.Owner, newRole: roleTypes.Viewer, code: http.StatusForbidden},
+		{name: "grants a custom role with a permission that is not held", role: roleTypes.Viewer, newRole: roleTypes.Viewer, customRoleID: &auditor.ID, code: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		member := dbTestUtils.AddMember(t, board.ID, dbTestUtils.CreateUser(t), test.role)
+		response := UpdateMember(origin, manager, views.UpdateMemberPayload{ID: member.ID, Role: test.newRole, CustomRoleID: test.customRoleID})
+		if response.Code != test.code {
+			t.Errorf("%s: UpdateMember() = %d %s, want %d", test.name, response.Code, response.Message, test.code)
+		}
+		want := test.role
+		if test.code == http.StatusOK {
+			want = test.newRole
+		}
+		if member = dbTestUtils.FindMember(t, member.ID); member.Role != want {
+			t.Errorf("%s: the member is now %q, want %q", test.name, member.Role, want)
+		}
+	}
+
+	// Owners can only be removed by those holding every permission of an owner
+	deleted := DeleteMember(origin, manager, views.DeleteMemberPayload{ID: ownerMember.ID})
+	if deleted.Code != http.StatusForbidden {
+		t.Errorf("deleting an owner without the permissions of an owner = %d %s, want %d", deleted.Code, deleted.Message, http.StatusForbidden)
+	}
+	viewer := dbTestUtils.AddMember(t, board.ID, dbTestUtils.CreateUser(t), roleTypes.Viewer)
+	deleted = DeleteMember(origin, manager, views.DeleteMemberPayload{ID: viewer.ID})
+	if deleted.Code != http.StatusOK {
+		t.Errorf("deleting a viewer = %d %s, want %d", deleted.Code, deleted.Message, http.StatusOK)
+	}
+	if member := dbTestUtils.FindMember(t, viewer.ID); len(member.ID) > 0 {
+		t.Errorf("the deleted viewer is still a member")
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, ownerMember := dbTestUtils.CreateBoard(t, owner)
+	editor := dbTestUtils.CreateUser(t)
+	editorMember := dbTestUtils.AddMember(t, board.ID, editor, roleTypes.Editor)
+
+	byEditor := TransferOwnership(auditService.Origin{ActorID: editor.ID}, views.TransferOwnershipPayload{BoardID: board.ID, MemberID: editorMember.ID})
+	if byEditor.Code != http.StatusForbidden {
+		t.Errorf("transferring the ownership as an editor = %d %s, want %d", byEditor.Code, byEditor.Message, http.StatusForbidden)
+	}
+	toSelf := TransferOwnership(auditService.Origin{ActorID: owner.ID}, views.TransferOwnershipPayload{BoardID: board.ID, MemberID: ownerMember.ID})
+	if toSelf.Code != http.StatusUnprocessableEntity || toSelf.Message != transferToSelfMessage {
+		t.Errorf("transferring the ownership to the owner = %d %s, want %d", toSelf.Code, toSelf.Message, http.StatusUnprocessableEntity)
+	}
+
+	transferred := TransferOwnership(auditService.Origin{ActorID: owner.ID}, views.TransferOwnershipPayload{BoardID: board.ID, MemberID: editorMember.ID})
+	if transferred.Code != http.StatusOK {
+		t.Fatalf("transferring the ownership = %d %s, want %d", transferred.Code, transferred.Message, http.StatusOK)
+	}
+	if member := dbTestUtils.FindMember(t, editorMember.ID); member.Role != roleTypes.Owner {
+		t.Errorf("the successor is now %q, want %q", member.Role, roleTypes.Owner)
+	}
+	if member := dbTestUtils.FindMember(t, ownerMember.ID); member.Role != roleTypes.Editor {
+		t.Errorf("the previous owner is now %q, want %q", member.Role, roleTypes.Editor)
+	}
+}
+
+func TestLeaveBoard(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, ownerMember := dbTestUtils.CreateBoard(t, owner)
+	editorMember := dbTestUtils.AddMember(t, board.ID, dbTestUtils.CreateUser(t), roleTypes.Editor)
+	origin := auditService.Origin{ActorID: owner.ID}
+
+	tests := []struct {
+		name    string
+		origin  auditService.Origin
+		payload views.LeaveBoardPayload
+		message string
+	}{
+		{
+			name:    "leaves as the last owner",
+			origin:  origin,
+			payload: views.LeaveBoardPayload{BoardID: board.ID},
+			message: leaveAsLastOwnerMessage,
+		},
+		{
+			name:    "leaves without being a member",
+			origin:  auditService.Origin{ActorID: dbTestUtils.CreateUser(t).ID},
+			payload: views.LeaveBoardPayload{BoardID: board.ID},
+			message: notMemberMessage,
+		},
+		{
+			name:    "nominates an unknown successor",
+			origin:  origin,
+			payload: views.LeaveBoardPayload{BoardID: board.ID, SuccessorID: "unknown"},
+			message: fmt.Sprintf(memberNotFoundMessage, "unknown"),
+		},
+		{
+			name:    "nominates themselves",
+			origin:  origin,
+			payload: views.LeaveBoardPayload{BoardID: board.ID, SuccessorID: ownerMember.ID},
+			message: transferToSelfMessage,
+		},
+	}
+	for _, test := range tests {
+		response := LeaveBoard(test.origin, test.payload)
+		if response.Code != http.StatusUnprocessableEntity || response.Message != test.message {
+			t.Errorf("%s: LeaveBoard() = %d %s, want %d %s", test.name, response.Code, response.Message, http.StatusUnprocessableEntity, test.message)
+		}
+	}
+	if member := dbTestUtils.FindMember(t, ownerMember.ID); member.Role != roleTypes.Owner {
+		t.Fatalf("the owner is now %q after failing to leave, want %q", member.Role, roleTypes.Owner)
+	}
+
+	left := LeaveBoard(origin, views.LeaveBoardPayload{BoardID: board.ID, SuccessorID: editorMember.ID})
+	if left.Code != http.StatusOK {
+		t.Fatalf("leaving with a successor = %d %s, want %d", left.Code, left.Message, http.StatusOK)
+	}
+	if member := dbTestUtils.FindMember(t, ownerMember.ID); len(member.ID) > 0 {
+		t.Errorf("the owner is still a member after leaving")
+	}
+	if member := dbTestUtils.FindMember(t, editorMember.ID); member.Role != roleTypes.Owner {
+		t.Errorf("the successor is now %q, want %q", member.Role, roleTypes.Owner)
+	}
+}
+
+func TestLeaveBoardDeletesTheBoardOfTheLastOwner(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+
+	response := LeaveBoard(auditService.Origin{ActorID: owner.ID}, views.LeaveBoardPayload{BoardID: board.ID, DeleteBoard: true})
+	if response.Code != http.StatusOK {
+		t.Fatalf("leaving and deleting the board = %d %s, want %d", response.Code, response.Message, http.StatusOK)
+	}
+	var trashed models.Board
+	if err := db.DB.Unscoped().Where("id = ?", board.ID).First(&trashed).Error; err != nil {
+		t.Fatalf("unable to find the board: %v", err)
+	}
+	if !trashed.DeletedAt.Valid {
+		t.Errorf("the board of the last owner is not in the trash after leaving")
+	}
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	accountService "github.com/EmilyOng/tusk-manager/backend/services/account"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	invitationService "github.com/EmilyOng/tusk-manager/backend/services/invitation"
+	sessionService "github.com/EmilyOng/tusk-manager/backend/services/session"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	oidcUtils "github.com/EmilyOng/tusk-manager/backend/utils/oidc"
+	seedUtils "github.com/EmilyOng/tusk-manager/backend/utils/seed"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	AuthRequestDuration = 10 * time.Minute
+
+	oidcNotConfiguredMessage          = "Single sign-on is not configured."
+	unableToStartOIDCLoginMessage     = "Unable to start single sign-on."
+	invalidOIDCStateMessage           = "The single sign-on request is invalid or has expired, please try again."
+	unableToAuthenticateOIDCMessage   = "Unable to authenticate with the identity provider."
+	unverifiedOIDCEmailMessage        = "The email '%s' has not been verified by the identity provider."
+	emailNotVerifiedMessage           = "Please verify your email '%s' before logging in."
+	unableToLinkIdentityMessage       = "Unable to sign in with the identity provider."
+	unableToGenerateTokenMessage      = "Unable to generate authentication token."
+	unableToGenerateSeedDataMessage   = "Unable to generate seed data."
+	successfullyLoginWithOIDCMessage  = "Welcome back %s!"
+	twoFactorRequiredMessage          = "Please enter the code from your authenticator app."
+	successfullySignUpWithOIDCMessage = "Welcome %s! Setting things up..."
+)
+
+var errUnverifiedEmail = errors.New("email has not been verified by the identity provider")
+
+// Starts the authorization code flow, returning the URL that the user should be redirected to
+func StartLogin() views.OIDCLoginResponse {
+	provider := oidcUtils.Default
+	if provider == nil {
+		return views.OIDCLoginResponse{
+			Response: views.Response{
+				Message: oidcNotConfiguredMessage,
+				Code:    http.StatusNotFound,
+			},
+		}
+	}
+
+	authRequest := models.OIDCAuthRequest{ExpiresAt: time.Now().Add(AuthRequestDuration)}
+	var err error
+	for _, value := range []*string{&authRequest.State, &authRequest.Nonce, &authRequest.CodeVerifier} {
+		if err == nil {
+			*value, err = oidcUtils.GenerateRandomString()
+		}
+	}
+
+	var authorizationURL string
+	if err == nil {
+		authorizationURL, err = provider.AuthCodeURL(authRequest.State, authRequest.Nonce, authRequest.CodeVerifier)
+	}
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			// Clean up abandoned requests
+			err := tx.Where("expires_at < ?", time.Now()).Delete(&models.OIDCAuthRequest{}).Error
+			if err != nil {
+				return err
+			}
+			return tx.Create(&authRequest).Error
+		})
+	}
+	if err != nil {
+		log.Println("Unable to start OpenID Connect login", err)
+		return views.OIDCLoginResponse{
+			Response: views.Response{
+				Message: unableToStartOIDCLoginMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.OIDCLoginResponse{
+		Response:         views.Response{Code: http.StatusOK},
+		AuthorizationURL: authorizationURL,
+		State:            authRequest.State,
+	}
+}
+
+// Finds the user linked to the external subject, linking an existing user by verified email or creating a new user
+func linkUser(issuer string, claims oidcUtils.IDTokenClaims) (user models.User, created bool, err error) {
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		var identity models.UserIdentity
+		err := tx.Where("issuer = ? AND subject = ?", issuer, claims.Subject).First(&identity).Error
+		if err == nil {
+			return tx.Where("id = ?", identity.UserID).First(&user).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if len(claims.Email) == 0 || !claims.EmailVerified {
+			return errUnverifiedEmail
+		}
+
+		err = tx.Where("email = ?", claims.Email).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			name := claims.Name
+			if len(name) == 0 {
+				name = claims.Email
+			}
+			user = models.User{Name: name, Email: claims.Email}
+			err = tx.Create(&user).Error
+			created = true
+		}
+		if err != nil {
+			return err
+		}
+
+		if !user.EmailVerified {
+			// The identity provider has verified the email
+			now := time.Now()
+			user.EmailVerified = true
+			user.EmailVerifiedAt = &now
+			err = tx.Model(&user).Select("email_verified", "email_verified_at").Updates(&user).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.UserIdentity{
+			Issuer:  issuer,
+			Subject: claims.Subject,
+			Email:   claims.Email,
+			UserID:  user.ID,
+		}).Error
+	})
+	return
+}
+
+// Completes the authorization code flow, starting a session for the linked user. The state must match the state
+// kept in the browser that started the login, so that a user cannot be logged in to another account (login CSRF).
+func HandleCallback(origin auditService.Origin, payload views.OIDCCallbackPayload) views.OIDCCallbackResponse {
+	provider := oidcUtils.Default
+	if provider == nil {
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: oidcNotConfiguredMessage,
+				Code:    http.StatusNotFound,
+			},
+		}
+	}
+	if len(payload.CookieState) == 0 || subtle.ConstantTimeCompare([]byte(payload.CookieState), []byte(payload.State)) != 1 {
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: invalidOIDCStateMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	// The request is consumed, so that the state cannot be replayed
+	var authRequest models.OIDCAuthRequest
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state = ?", payload.State).
+			First(&authRequest).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&authRequest).Error
+	})
+	if err != nil || authRequest.ExpiresAt.Before(time.Now()) {
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: invalidOIDCStateMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	rawIDToken, err := provider.Exchange(payload.Code, authRequest.CodeVerifier)
+	var claims oidcUtils.IDTokenClaims
+	if err == nil {
+		claims, err = provider.VerifyIDToken(rawIDToken, authRequest.Nonce)
+	}
+	if err != nil {
+		log.Println("Unable to authenticate with OpenID Connect", err)
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: unableToAuthenticateOIDCMessage,
+				Code:    http.StatusUnauthorized,
+			},
+		}
+	}
+
+	user, created, err := linkUser(provider.Issuer, claims)
+	if errors.Is(err, errUnverifiedEmail) {
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unverifiedOIDCEmailMessage, claims.Email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: unableToLinkIdentityMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	origin.ActorID = user.ID
+	details := map[string]interface{}{"method": "oidc", "issuer": provider.Issuer}
+
+	// The email has been verified by the identity provider
+	invitationService.AcceptPendingInvitations(origin, user)
+
+	message := fmt.Sprintf(successfullyLoginWithOIDCMessage, user.Name)
+	if created {
+		auditService.Record(origin, auditService.Event{
+			Action:     auditTypes.UserSignedUp,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Details:    details,
+		})
+
+		// Generate seed data
+		err = seedUtils.SeedData(&user)
+		if err != nil {
+			return views.OIDCCallbackResponse{
+				Response: views.Response{
+					Message: unableToGenerateSeedDataMessage,
+					Code:    http.StatusInternalServerError,
+				},
+			}
+		}
+		message = fmt.Sprintf(successfullySignUpWithOIDCMessage, user.Name)
+	}
+
+	if !user.EmailVerified && accountService.GetVerificationPolicy().RequiresForLogin() {
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(emailNotVerifiedMessage, user.Email),
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
+	if user.TOTPEnabled {
+		// The identity provider does not replace the second factor, so the token is only issued after it
+		challengeToken, err := authUtils.GenerateChallengeToken(user)
+		if err != nil {
+			return views.OIDCCallbackResponse{
+				Response: views.Response{
+					Message: unableToGenerateTokenMessage,
+					Code:    http.StatusInternalServerError,
+				},
+			}
+		}
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: twoFactorRequiredMessage,
+				Code:    http.StatusOK,
+			},
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+		}
+	}
+
+	signedToken, refreshToken, err := sessionService.CreateSession(user)
+	if err != nil {
+		return views.OIDCCallbackResponse{
+			Response: views.Response{
+				Message: unableToGenerateTokenMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	auditService.Record(origin, auditService.Event{
+		Action:     auditTypes.LoginSucceeded,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Details:    details,
+	})
+	return views.OIDCCallbackResponse{
+		Response: views.Response{
+			Message: message,
+			Code:    http.StatusOK,
+		},
+		User: views.AuthUserView{
+			ID:           user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Token:        signedToken,
+			RefreshToken: refreshToken,
+		},
+	}
+}
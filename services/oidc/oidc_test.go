@@ -0,0 +1,147 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	keyUtils "github.com/EmilyOng/tusk-manager/backend/utils/keys"
+	oidcUtils "github.com/EmilyOng/tusk-manager/backend/utils/oidc"
+	oidcTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/oidctest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const testClientID = "tusk-manager"
+
+// Runs the single sign-on flow against an in-process identity provider. The flow stores users, so the tests
+// need a disposable database at 'TEST_DATABASE_URL'.
+func setupTestProvider(t *testing.T) *oidcTestUtils.Server {
+	dbTestUtils.Setup(t)
+	t.Setenv("AUTH_SECRET_KEY", "test-secret")
+	if err := keyUtils.Setup(); err != nil {
+		t.Fatalf("unable to setup the signing keys: %v", err)
+	}
+
+	server := oidcTestUtils.NewServer(testClientID)
+	previous := oidcUtils.Default
+	oidcUtils.Default = &oidcUtils.Provider{
+		Issuer:      server.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost:3000/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+		HTTPClient:  &http.Client{Timeout: 5 * time.Second},
+	}
+	t.Cleanup(func() {
+		oidcUtils.Default = previous
+		server.Close()
+	})
+	return server
+}
+
+// Signs in at the identity provider with the claims, and completes the login from the same browser
+func login(t *testing.T, server *oidcTestUtils.Server, subject string, claims jwt.MapClaims) views.OIDCCallbackResponse {
+	startLoginResponse := StartLogin()
+	if startLoginResponse.Code != http.StatusOK {
+		t.Fatalf("StartLogin() = %d %s", startLoginResponse.Code, startLoginResponse.Message)
+	}
+	code, state, err := server.Authorize(startLoginResponse.AuthorizationURL, subject, claims)
+	if err != nil {
+		t.Fatalf("Authorize() returned an error: %v", err)
+	}
+	if state != startLoginResponse.State {
+		t.Fatalf("the identity provider returned the state %q, want %q", state, startLoginResponse.State)
+	}
+	return HandleCallback(auditService.Origin{}, views.OIDCCallbackPayload{Code: code, State: state, CookieState: startLoginResponse.State})
+}
+
+func TestCallbackCreatesAndLinksUsers(t *testing.T) {
+	server := setupTestProvider(t)
+	subject := uuid.NewString()
+	email := subject + "@example.com"
+
+	created := login(t, server, subject, jwt.MapClaims{"email": email, "email_verified": true, "name": "Ada"})
+	if created.Code != http.StatusOK || len(created.User.Token) == 0 {
+		t.Fatalf("first login = %d %s, want a session", created.Code, created.Message)
+	}
+	if created.User.Email != email || created.User.Name != "Ada" {
+		t.Errorf("first login created %+v, want the user from the ID token", created.User)
+	}
+
+	// The identity is linked by its subject, even once the email changes at the provider
+	again := login(t, server, subject, jwt.MapClaims{"email": "changed-" + email, "email_verified": true})
+	if again.Code != http.StatusOK || again.User.ID != created.User.ID {
+		t.Errorf("second login = %d %s for %s, want the same user %s", again.Code, again.Message, again.User.ID, created.User.ID)
+	}
+}
+
+func TestCallbackLinksExistingUsersByVerifiedEmail(t *testing.T) {
+	server := setupTestProvider(t)
+	email := uuid.NewString() + "@example.com"
+	user := models.User{Name: "Grace", Email: email, Password: "hash"}
+	if err := db.DB.Create(&user).Error; err != nil {
+		t.Fatalf("unable to create the user: %v", err)
+	}
+
+	unverified := login(t, server, uuid.NewString(), jwt.MapClaims{"email": email, "email_verified": false})
+	if unverified.Code != http.StatusUnprocessableEntity {
+		t.Errorf("login with an unverified email = %d %s, want %d", unverified.Code, unverified.Message, http.StatusUnprocessableEntity)
+	}
+
+	linked := login(t, server, uuid.NewString(), jwt.MapClaims{"email": email, "email_verified": true})
+	if linked.Code != http.StatusOK || linked.User.ID != user.ID {
+		t.Errorf("login with a verified email = %d %s for %s, want the existing user %s", linked.Code, linked.Message, linked.User.ID, user.ID)
+	}
+}
+
+func TestCallbackRequiresTheStateOfTheBrowser(t *testing.T) {
+	server := setupTestProvider(t)
+	subject := uuid.NewString()
+	claims := jwt.MapClaims{"email": subject + "@example.com", "email_verified": true}
+
+	tests := []struct {
+		name        string
+		cookieState func(state string) string
+	}{
+		{name: "missing cookie", cookieState: func(state string) string { return "" }},
+		{name: "cookie of another login", cookieState: func(state string) string { return state + "-other" }},
+	}
+	for _, test := range tests {
+		startLoginResponse := StartLogin()
+		code, state, err := server.Authorize(startLoginResponse.AuthorizationURL, subject, claims)
+		if err != nil {
+			t.Fatalf("Authorize() returned an error: %v", err)
+		}
+
+		response := HandleCallback(auditService.Origin{}, views.OIDCCallbackPayload{
+			Code:        code,
+			State:       state,
+			CookieState: test.cookieState(state),
+		})
+		if response.Code != http.StatusUnprocessableEntity || len(response.User.Token) > 0 {
+			t.Errorf("%s: HandleCallback() = %d %s, want %d", test.name, response.Code, response.Message, http.StatusUnprocessableEntity)
+		}
+	}
+}
+
+func TestCallbackRequiresTheSecondFactor(t *testing.T) {
+	server := setupTestProvider(t)
+	email := uuid.NewString() + "@example.com"
+	user := models.User{Name: "Alan", Email: email, Password: "hash", TOTPEnabled: true, TOTPSecret: "JBSWY3DPEHPK3PXP"}
+	if err := db.DB.Create(&user).Error; err != nil {
+		t.Fatalf("unable to create the user: %v", err)
+	}
+
+	response := login(t, server, uuid.NewString(), jwt.MapClaims{"email": email, "email_verified": true})
+	if response.Code != http.StatusOK || !response.TwoFactorRequired || len(response.ChallengeToken) == 0 {
+		t.Errorf("HandleCallback() = %d %s, want a two-factor challenge", response.Code, response.Message)
+	}
+	if len(response.User.Token) > 0 || len(response.User.RefreshToken) > 0 {
+		t.Error("HandleCallback() issued tokens before the second factor")
+	}
+}
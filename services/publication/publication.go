@@ -0,0 +1,313 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+)
+
+const (
+	unableToGetPublicationMessage  = "Unable to retrieve the publication of the board (%s)."
+	unableToPublishBoardMessage    = "Unable to publish the board (%s)."
+	unableToUnpublishBoardMessage  = "Unable to unpublish the board (%s)."
+	unableToGetPublicBoardMessage  = "Unable to retrieve the board."
+	boardNotPublishedMessage       = "The board is not published (%s)."
+	publicBoardNotFoundMessage     = "The board cannot be found, or is no longer shared."
+	successfullyPublishedMessage   = "The board is published! Copy the link now, as it will not be shown again."
+	successfullyUpdatedMessage     = "Successfully updated the published board!"
+	successfullyUnpublishedMessage = "The board is no longer published."
+)
+
+func toView(boardPublication models.BoardPublication) views.BoardPublicationView {
+	return views.BoardPublicationView{
+		BoardID:       boardPublication.BoardID,
+		HiddenTagIDs:  strings.Fields(boardPublication.HiddenTagIDs),
+		HiddenTaskIDs: strings.Fields(boardPublication.HiddenTaskIDs),
+		PublishedAt:   boardPublication.CreatedAt,
+	}
+}
+
+// Keeps the identifiers of resources that belong to the board
+func filterBoardIDs(tx *gorm.DB, model interface{}, boardID string, ids []string) (string, error) {
+	if len(ids) == 0 {
+		return "", nil
+	}
+	var boardIDs []string
+	err := tx.Model(model).Where("board_id = ? AND id IN ?", boardID, ids).Pluck("id", &boardIDs).Error
+	return strings.Join(boardIDs, " "), err
+}
+
+func setHidden(tx *gorm.DB, boardPublication *models.BoardPublication, hiddenTagIDs []string, hiddenTaskIDs []string) (err error) {
+	boardPublication.HiddenTagIDs, err = filterBoardIDs(tx, &models.Tag{}, boardPublication.BoardID, hiddenTagIDs)
+	if err != nil {
+		return
+	}
+	boardPublication.HiddenTaskIDs, err = filterBoardIDs(tx, &models.Task{}, boardPublication.BoardID, hiddenTaskIDs)
+	return
+}
+
+func GetBoardPublication(payload views.GetBoardPublicationPayload) views.GetBoardPublicationResponse {
+	var boardPublication models.BoardPublication
+	err := db.DB.Where("board_id = ?", payload.BoardID).First(&boardPublication).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.GetBoardPublicationResponse{Response: views.Response{Code: http.StatusOK}}
+	}
+	if err != nil {
+		return views.GetBoardPublicationResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToGetPublicationMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	publicationView := toView(boardPublication)
+	return views.GetBoardPublicationResponse{
+		Response:    views.Response{Code: http.StatusOK},
+		Publication: &publicationView,
+	}
+}
+
+// Publishes the board with a new token, so that republishing revokes the previous link
+func PublishBoard(origin auditService.Origin, payload views.PublishBoardPayload) views.PublishBoardResponse {
+	token, hash, err := authUtils.GenerateOpaqueToken()
+	boardPublication := models.BoardPublication{TokenHash: hash, BoardID: payload.BoardID}
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Where("board_id = ?", payload.BoardID).Delete(&models.BoardPublication{}).Error
+			if err != nil {
+				return err
+			}
+
+			err = setHidden(tx, &boardPublication, payload.HiddenTagIDs, payload.HiddenTaskIDs)
+			if err != nil {
+				return err
+			}
+			err = tx.Create(&boardPublication).Error
+			if err != nil {
+				return err
+			}
+
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.BoardPublished,
+				TargetType: "board",
+				TargetID:   payload.BoardID,
+				BoardID:    payload.BoardID,
+			})
+		})
+	}
+	if err != nil {
+		return views.PublishBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToPublishBoardMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	publicationView := toView(boardPublication)
+	publicationView.URL = fmt.Sprintf("%s/public?token=%s", commonUtils.GetFrontendUrl(), token)
+	return views.PublishBoardResponse{
+		Response: views.Response{
+			Message: successfullyPublishedMessage,
+			Code:    http.StatusOK,
+		},
+		Publication: publicationView,
+	}
+}
+
+// Updates the hidden tags and tasks, keeping the link
+func UpdateBoardPublication(payload views.UpdateBoardPublicationPayload) views.UpdateBoardPublicationResponse {
+	var boardPublication models.BoardPublication
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("board_id = ?", payload.BoardID).First(&boardPublication).Error
+		if err != nil {
+			return err
+		}
+
+		err = setHidden(tx, &boardPublication, payload.HiddenTagIDs, payload.HiddenTaskIDs)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&boardPublication).
+			Select("hidden_tag_ids", "hidden_task_ids").
+			Updates(&boardPublication).
+			Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UpdateBoardPublicationResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardNotPublishedMessage, payload.BoardID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.UpdateBoardPublicationResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToPublishBoardMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UpdateBoardPublicationResponse{
+		Response: views.Response{
+			Message: successfullyUpdatedMessage,
+			Code:    http.StatusOK,
+		},
+		Publication: toView(boardPublication),
+	}
+}
+
+func UnpublishBoard(origin auditService.Origin, payload views.UnpublishBoardPayload) views.UnpublishBoardResponse {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("board_id = ?", payload.BoardID).Delete(&models.BoardPublication{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.BoardUnpublished,
+			TargetType: "board",
+			TargetID:   payload.BoardID,
+			BoardID:    payload.BoardID,
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UnpublishBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardNotPublishedMessage, payload.BoardID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.UnpublishBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToUnpublishBoardMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UnpublishBoardResponse{
+		Response: views.Response{
+			Message: successfullyUnpublishedMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+func toSet(ids string) map[string]bool {
+	set := map[string]bool{}
+	for _, id := range strings.Fields(ids) {
+		set[id] = true
+	}
+	return set
+}
+
+// Retrieves the published board, leaving out the hidden tags and tasks
+func GetPublicBoard(payload views.GetPublicBoardPayload) views.GetPublicBoardResponse {
+	var boardPublication models.BoardPublication
+	err := db.DB.Where("token_hash = ?", authUtils.HashOpaqueToken(payload.Token)).First(&boardPublication).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.GetPublicBoardResponse{
+			Response: views.Response{
+				Message: publicBoardNotFoundMessage,
+				Code:    http.StatusNotFound,
+			},
+		}
+	}
+
+	var board models.Board
+	var states []views.StateMinimalView
+	var tags []views.TagMinimalView
+	var tasks []models.Task
+	if err == nil {
+		err = db.DB.Where("id = ?", boardPublication.BoardID).First(&board).Error
+	}
+	if err == nil {
+		err = db.DB.Model(&models.State{}).
+			Where("board_id = ?", board.ID).
+			Order("current_position").
+			Find(&states).
+			Error
+	}
+	if err == nil {
+		err = db.DB.Model(&models.Tag{}).Where("board_id = ?", board.ID).Order("name").Find(&tags).Error
+	}
+	if err == nil {
+		err = db.DB.Model(&models.Task{}).
+			Where("board_id = ?", board.ID).
+			Order("position").
+			Preload("Tags").
+			Find(&tasks).
+			Error
+	}
+	if err != nil {
+		return views.GetPublicBoardResponse{
+			Response: views.Response{
+				Message: unableToGetPublicBoardMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	hiddenTags := toSet(boardPublication.HiddenTagIDs)
+	hiddenTasks := toSet(boardPublication.HiddenTaskIDs)
+
+	publicBoard := views.PublicBoardView{
+		Name:   board.Name,
+		Color:  board.Color,
+		States: states,
+		Tasks:  []views.PublicTaskView{},
+		Tags:   []views.TagMinimalView{},
+	}
+	for _, tag := range tags {
+		if !hiddenTags[tag.ID] {
+			publicBoard.Tags = append(publicBoard.Tags, tag)
+		}
+	}
+	for _, task := range tasks {
+		if hiddenTasks[task.ID] {
+			continue
+		}
+		publicTask := views.PublicTaskView{
+			TaskMinimalView: views.TaskMinimalView{
+				ID:          task.ID,
+				Name:        task.Name,
+				Description: task.Description,
+				DueAt:       task.DueAt,
+			},
+			StateID: task.StateID,
+			TagIDs:  []string{},
+		}
+		for _, tag := range task.Tags {
+			if !hiddenTags[tag.ID] {
+				publicTask.TagIDs = append(publicTask.TagIDs, tag.ID)
+			}
+		}
+		publicBoard.Tasks = append(publicBoard.Tasks, publicTask)
+	}
+
+	return views.GetPublicBoardResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Board:    publicBoard,
+	}
+}
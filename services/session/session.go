@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	invalidRefreshTokenMessage          = "The session has expired, please log in again."
+	unableToRefreshSessionMessage       = "Unable to refresh the session."
+	refreshTokenReuseDetectedMessage    = "The session has been revoked, please log in again."
+	unableToGenerateAuthenticationToken = "Unable to generate authentication token."
+)
+
+// Issues a new refresh token within the session
+func issueRefreshToken(tx *gorm.DB, sessionID string) (string, error) {
+	token, hash, err := authUtils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	refreshToken := models.RefreshToken{
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(authUtils.RefreshTokenDuration),
+		SessionID: sessionID,
+	}
+	err = tx.Create(&refreshToken).Error
+	return token, err
+}
+
+// Starts a new session for the user, returning its access and refresh tokens
+func CreateSession(user models.User) (accessToken string, refreshToken string, err error) {
+	session := models.Session{UserID: user.ID}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&session).Error
+		if err != nil {
+			return err
+		}
+
+		refreshToken, err = issueRefreshToken(tx, session.ID)
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	accessToken, err = authUtils.GenerateToken(user, session.ID)
+	return
+}
+
+// Rotates the refresh token. Reusing a rotated refresh token revokes the whole session.
+func RefreshSession(origin auditService.Origin, payload views.RefreshPayload) views.RefreshResponse {
+	var user models.User
+	var session models.Session
+	var newRefreshToken string
+	reused := false
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var refreshToken models.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", authUtils.HashOpaqueToken(payload.RefreshToken)).
+			First(&refreshToken).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("id = ? AND revoked_at IS NULL", refreshToken.SessionID).First(&session).Error
+		if err != nil {
+			return err
+		}
+
+		if refreshToken.UsedAt != nil {
+			// The token has been rotated before, so the token family is possibly compromised.
+			// The transaction succeeds here so that the revocation is committed.
+			reused = true
+			err = tx.Model(&session).Update("revoked_at", time.Now()).Error
+			if err != nil {
+				return err
+			}
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.RefreshTokenReused,
+				TargetType: "user",
+				TargetID:   session.UserID,
+				Details:    map[string]interface{}{"sessionId": session.ID},
+			})
+		}
+
+		if refreshToken.ExpiresAt.Before(time.Now()) {
+			return gorm.ErrRecordNotFound
+		}
+
+		err = tx.Model(&refreshToken).Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("id = ?", session.UserID).First(&user).Error
+		if err != nil {
+			return err
+		}
+
+		newRefreshToken, err = issueRefreshToken(tx, session.ID)
+		if err != nil {
+			return err
+		}
+
+		origin.ActorID = user.ID
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.SessionRefreshed,
+			TargetType: "user",
+			TargetID:   user.ID,
+			Details:    map[string]interface{}{"sessionId": session.ID},
+		})
+	})
+
+	if err == nil && reused {
+		return views.RefreshResponse{
+			Response: views.Response{
+				Message: refreshTokenReuseDetectedMessage,
+				Code:    http.StatusUnauthorized,
+			},
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.RefreshResponse{
+			Response: views.Response{
+				Message: invalidRefreshTokenMessage,
+				Code:    http.StatusUnauthorized,
+			},
+		}
+	}
+	if err != nil {
+		return views.RefreshResponse{
+			Response: views.Response{
+				Message: unableToRefreshSessionMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	accessToken, err := authUtils.GenerateToken(user, session.ID)
+	if err != nil {
+		return views.RefreshResponse{
+			Response: views.Response{
+				Message: unableToGenerateAuthenticationToken,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.RefreshResponse{
+		Response: views.Response{Code: http.StatusOK},
+		User: views.AuthUserView{
+			ID:           user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Token:        accessToken,
+			RefreshToken: newRefreshToken,
+		},
+	}
+}
+
+func RevokeSession(sessionID string) error {
+	return db.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).
+		Error
+}
+
+// Revokes every session of the user, logging the user out everywhere
+func RevokeUserSessions(tx *gorm.DB, userID string) error {
+	return tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).
+		Error
+}
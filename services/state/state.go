@@ -7,8 +7,10 @@ import (
 
 	"github.com/EmilyOng/tusk-manager/backend/db"
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	taskService "github.com/EmilyOng/tusk-manager/backend/services/task"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -17,15 +19,93 @@ const (
 	unableToGetStateMessage    = "Unable to retrieve state (%s)."
 	unableToDeleteStateMessage = "Unable to delete state (%s)."
 	stateNotFoundMessage       = "The state cannot be found (%s)."
+	targetStateNotFoundMessage = "The state to move the tasks to cannot be found on the board (%s)."
+	lastStateMessage           = "The last state of a board cannot be deleted."
+	boardNotFoundMessage       = "The board cannot be found (%s)."
+	unableToReorderMessage     = "Unable to reorder the states of the board (%s)."
+	stateListMismatchMessage   = "The states must list every state of the board exactly once."
 
 	successfullyCreatedStateMessage = "Successfully created state '%s'!"
 	successfullyUpdatedStateMessage = "Successfully updated state '%s'!"
 	successfullyDeletedStateMessage = "Successfully deleted state '%s'!"
+	successfullyReorderedMessage    = "Successfully reordered the states!"
 )
 
+var (
+	errLastState           = errors.New("the state is the last state of the board")
+	errTargetStateNotFound = errors.New("the target state does not belong to the board")
+	errStateListMismatch   = errors.New("the states do not match the states of the board")
+)
+
+// Locks the board until the end of the transaction, so that the states of the board are
+// positioned by one transaction at a time. The board is locked before its states and tasks.
+func lockBoard(tx *gorm.DB, boardID string) error {
+	var board models.Board
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", boardID).
+		First(&board).
+		Error
+}
+
+// Lists the states of the board by position, leaving out the given state
+func getBoardStateIDs(tx *gorm.DB, boardID string, excludedStateID string) (stateIDs []string, err error) {
+	err = tx.Model(&models.State{}).
+		Where("board_id = ? AND id <> ?", boardID, excludedStateID).
+		Order("current_position, id").
+		Pluck("id", &stateIDs).
+		Error
+	return
+}
+
+// Numbers the states from zero in the given order
+func positionStates(tx *gorm.DB, stateIDs []string) error {
+	for position, stateID := range stateIDs {
+		err := tx.Model(&models.State{}).Where("id = ?", stateID).Update("current_position", position).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Places the state at the position among the other states of the board, shifting the states from
+// that position onwards, within the board lock. The position is clamped to the states of the board.
+func PlaceStateTx(tx *gorm.DB, boardID string, stateID string, position int) (int, error) {
+	err := lockBoard(tx, boardID)
+	if err != nil {
+		return 0, err
+	}
+	stateIDs, err := getBoardStateIDs(tx, boardID, stateID)
+	if err != nil {
+		return 0, err
+	}
+
+	if position < 0 {
+		position = 0
+	}
+	if position > len(stateIDs) {
+		position = len(stateIDs)
+	}
+	stateIDs = append(stateIDs[:position], append([]string{stateID}, stateIDs[position:]...)...)
+	return position, positionStates(tx, stateIDs)
+}
+
 func CreateState(payload views.CreateStatePayload) views.CreateStateResponse {
 	state := models.State{Name: payload.Name, BoardID: payload.BoardID, CurrentPosition: payload.CurrentPosition}
-	err := db.DB.Create(&state).Error
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Creating the state references the board, so the board is locked first
+		err := lockBoard(tx, state.BoardID)
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&state).Error
+		if err != nil {
+			return err
+		}
+		state.CurrentPosition, err = PlaceStateTx(tx, state.BoardID, state.ID, payload.CurrentPosition)
+		return err
+	})
 
 	if err != nil {
 		return views.CreateStateResponse{
@@ -45,13 +125,23 @@ func CreateState(payload views.CreateStatePayload) views.CreateStateResponse {
 }
 
 func UpdateState(payload views.UpdateStatePayload) views.UpdateStateResponse {
-	state := models.State{
-		ID:              payload.ID,
-		Name:            payload.Name,
-		BoardID:         payload.BoardID,
-		CurrentPosition: payload.CurrentPosition,
-	}
-	err := db.DB.Save(&state).Error
+	// The board of the state cannot be changed, and the other states of the board are shifted around its position
+	var state models.State
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("board_id").Where("id = ?", payload.ID).First(&state).Error
+		if err != nil {
+			return err
+		}
+		_, err = PlaceStateTx(tx, state.BoardID, payload.ID, payload.CurrentPosition)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.State{}).Where("id = ?", payload.ID).Update("name", payload.Name).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", payload.ID).First(&state).Error
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return views.UpdateStateResponse{
@@ -83,35 +173,91 @@ func UpdateState(payload views.UpdateStatePayload) views.UpdateStateResponse {
 	}
 }
 
+// Deletes the state after moving its tasks to the end of the target state, which defaults to the first
+// remaining state of the board. The last state of a board cannot be deleted.
 func DeleteState(payload views.DeleteStatePayload) views.DeleteStateResponse {
-	state := models.State{ID: payload.ID}
+	var state models.State
+	var result views.DeleteStateView
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("board_id").Where("id = ?", payload.ID).First(&state).Error
+		if err != nil {
+			return err
+		}
 
-	// Get tasks associated with the state
-	var tasksView []views.TaskMinimalView
-	err := db.DB.Model(&state).Association("Tasks").Find(&tasksView)
+		// Locks the board, so that concurrent deletions cannot remove the last state
+		err = lockBoard(tx, state.BoardID)
+		if err != nil {
+			return err
+		}
+		var states []models.State
+		err = tx.Where("board_id = ?", state.BoardID).
+			Order("current_position, id").
+			Find(&states).
+			Error
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return views.DeleteStateResponse{
-				Response: views.Response{
-					Message: fmt.Sprintf(stateNotFoundMessage, payload.ID),
-					Code:    http.StatusUnprocessableEntity,
-				},
+		found := false
+		for _, boardState := range states {
+			if boardState.ID == payload.ID {
+				state = boardState
+				found = true
+			} else if (len(payload.TargetStateID) == 0 && len(result.TargetStateID) == 0) ||
+				boardState.ID == payload.TargetStateID {
+				result.TargetStateID = boardState.ID
 			}
 		}
+		if !found {
+			return gorm.ErrRecordNotFound
+		}
+		if len(states) == 1 {
+			return errLastState
+		}
+		if len(result.TargetStateID) == 0 {
+			return errTargetStateNotFound
+		}
+
+		result.MovedTasks, err = taskService.MoveStateTasksTx(tx, state.BoardID, state.ID, result.TargetStateID)
+		if err != nil {
+			return err
+		}
+		// Moves the state to the trash, from where it can be restored without its tasks
+		err = tx.Delete(&state).Error
+		if err != nil {
+			return err
+		}
+		stateIDs, err := getBoardStateIDs(tx, state.BoardID, state.ID)
+		if err != nil {
+			return err
+		}
+		return positionStates(tx, stateIDs)
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return views.DeleteStateResponse{
 			Response: views.Response{
-				Message: fmt.Sprintf(unableToGetStateMessage, payload.ID),
-				Code:    http.StatusInternalServerError,
+				Message: fmt.Sprintf(stateNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errLastState) {
+		return views.DeleteStateResponse{
+			Response: views.Response{
+				Message: lastStateMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errTargetStateNotFound) {
+		return views.DeleteStateResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(targetStateNotFoundMessage, payload.TargetStateID),
+				Code:    http.StatusUnprocessableEntity,
 			},
 		}
 	}
-
-	err = db.DB.Transaction(func(tx *gorm.DB) error {
-		// TODO: Delegate tasks to a default state
-		return tx.Delete(&state).Error
-	})
-
 	if err != nil {
 		return views.DeleteStateResponse{
 			Response: views.Response{
@@ -125,5 +271,77 @@ func DeleteState(payload views.DeleteStatePayload) views.DeleteStateResponse {
 			Message: fmt.Sprintf(successfullyDeletedStateMessage, state.Name),
 			Code:    http.StatusOK,
 		},
+		Result: result,
+	}
+}
+
+// Rewrites the positions of the states of the board in the given order, which must list
+// every state of the board exactly once
+func ReorderStates(payload views.ReorderStatesPayload) views.ReorderStatesResponse {
+	var statesView []views.StateMinimalView
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := lockBoard(tx, payload.BoardID)
+		if err != nil {
+			return err
+		}
+		stateIDs, err := getBoardStateIDs(tx, payload.BoardID, "")
+		if err != nil {
+			return err
+		}
+
+		listed := make(map[string]bool)
+		for _, stateID := range payload.StateIDs {
+			listed[stateID] = true
+		}
+		if len(listed) != len(payload.StateIDs) || len(listed) != len(stateIDs) {
+			return errStateListMismatch
+		}
+		for _, stateID := range stateIDs {
+			if !listed[stateID] {
+				return errStateListMismatch
+			}
+		}
+
+		err = positionStates(tx, payload.StateIDs)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.State{}).
+			Where("board_id = ?", payload.BoardID).
+			Order("current_position").
+			Find(&statesView).
+			Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.ReorderStatesResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardNotFoundMessage, payload.BoardID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errStateListMismatch) {
+		return views.ReorderStatesResponse{
+			Response: views.Response{
+				Message: stateListMismatchMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.ReorderStatesResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToReorderMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	return views.ReorderStatesResponse{
+		Response: views.Response{
+			Message: successfullyReorderedMessage,
+			Code:    http.StatusOK,
+		},
+		States: statesView,
 	}
 }
@@ -46,7 +46,7 @@ func CreateTag(payload views.CreateTagPayload) views.CreateTagResponse {
 
 func DeleteTag(payload views.DeleteTagPayload) views.DeleteTagResponse {
 	tag := models.Tag{ID: payload.ID}
-	err := db.DB.Model(&tag).Preload("Tasks").Find(&tag).Error
+	err := db.DB.First(&tag).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -66,14 +66,8 @@ func DeleteTag(payload views.DeleteTagPayload) views.DeleteTagResponse {
 		}
 	}
 
-	err = db.DB.Transaction(func(tx *gorm.DB) error {
-		// Remove the association between the tag and its tasks
-		err = tx.Model(&tag).Association("Tasks").Delete(&tag.Tasks)
-		if err != nil {
-			return err
-		}
-		return tx.Delete(&tag).Error
-	})
+	// Moves the tag to the trash, keeping its links to tasks so that it can be restored
+	err = db.DB.Delete(&tag).Error
 
 	if err != nil {
 		return views.DeleteTagResponse{
@@ -93,8 +87,16 @@ func DeleteTag(payload views.DeleteTagPayload) views.DeleteTagResponse {
 }
 
 func UpdateTag(payload views.UpdateTagPayload) views.UpdateTagResponse {
-	tag := models.Tag{ID: payload.ID, Name: payload.Name, BoardID: payload.BoardID, Color: payload.Color}
-	err := db.DB.Save(&tag).Error
+	// The board of the tag cannot be changed
+	tag := models.Tag{ID: payload.ID, Name: payload.Name, Color: payload.Color}
+	result := db.DB.Model(&tag).Select("name", "color").Updates(&tag)
+	err := result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+	if err == nil {
+		err = db.DB.First(&tag).Error
+	}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return views.UpdateTagResponse{
@@ -4,13 +4,20 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/EmilyOng/tusk-manager/backend/db"
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
 	datetime "github.com/EmilyOng/tusk-manager/backend/utils/datetime"
+	rankUtils "github.com/EmilyOng/tusk-manager/backend/utils/rank"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -18,42 +25,300 @@ const (
 	unableToUpdateTaskMessage = "Unable to update task (%s)."
 	unableToGetTaskMessage    = "Unable to retrieve task (%s)."
 	unableToDeleteTaskMessage = "Unable to delete task (%s)."
+	unableToMoveTaskMessage   = "Unable to move task (%s)."
+	unableToAssignMessage     = "Unable to assign task (%s)."
+	unableToUnassignMessage   = "Unable to unassign task (%s)."
 	taskNotFoundMessage       = "The task cannot be found (%s)."
+	stateNotFoundMessage      = "The state cannot be found on the board of the task (%s)."
+	anchorNotFoundMessage     = "The task to place it after cannot be found in the state (%s)."
+	userMismatchMessage       = "The user (%s) does not match the creator of the task."
+	notBoardMemberMessage     = "The user (%s) cannot access the board of the task."
+	notAssignedMessage        = "The user (%s) is not assigned to the task."
+	tagNotFoundMessage        = "The tags of the task must be existing tags of its board."
+	manageTagsMessage         = "You can only add existing tags to the task."
+	emptyTagNameMessage       = "The name of a new tag cannot be empty."
+	moveOnlyMessage           = "You can only move the task to another state."
 
 	successfullyCreatedTaskMessage = "Successfully created task '%s'!"
 	successfullyUpdatedTaskMessage = "Successfully updated task '%s'!"
 	successfullyDeletedTaskMessage = "Successfully deleted task '%s'!"
+	successfullyMovedTaskMessage   = "Successfully moved task '%s'!"
+	successfullyAssignedMessage    = "Successfully assigned task '%s'!"
+	successfullyUnassignedMessage  = "Successfully unassigned task '%s'!"
 )
 
+var (
+	// Positioning a task in a state that does not belong to the board
+	ErrStateNotFound  = errors.New("the state does not belong to the board")
+	errAnchorNotFound = errors.New("the task to place it after is not in the state")
+	errNotBoardMember = errors.New("the user cannot access the board")
+	errNotAssigned    = errors.New("the user is not assigned to the task")
+	errTagNotFound    = errors.New("the tag does not belong to the board")
+	errManageTags     = errors.New("the actor cannot create tags")
+	errEmptyTagName   = errors.New("the name of the tag is empty")
+)
+
+// Loads the tags of the board that the payload refers to by ID, so that tasks are only linked to
+// existing tags of their own board, without changing the tags. Tags without an ID are created on the board,
+// which requires the permission to manage tags.
+func getBoardTags(tx *gorm.DB, boardID string, permissions permissionTypes.Set, payloadTags []views.TagMinimalView) ([]*models.Tag, error) {
+	tags := []*models.Tag{}
+	var newTags []*models.Tag
+	var tagIDs []string
+	listed := map[string]bool{}
+	for _, tag := range payloadTags {
+		if len(tag.ID) == 0 {
+			newTags = append(newTags, &models.Tag{Name: strings.TrimSpace(tag.Name), Color: tag.Color, BoardID: boardID})
+			continue
+		}
+		if !listed[tag.ID] {
+			listed[tag.ID] = true
+			tagIDs = append(tagIDs, tag.ID)
+		}
+	}
+
+	if len(newTags) > 0 {
+		if !permissions.Has(permissionTypes.ManageTags) {
+			return nil, errManageTags
+		}
+		for _, tag := range newTags {
+			if len(tag.Name) == 0 {
+				return nil, errEmptyTagName
+			}
+		}
+		err := tx.Create(&newTags).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(tagIDs) == 0 {
+		return append(tags, newTags...), nil
+	}
+
+	err := tx.Where("id IN ? AND board_id = ?", tagIDs, boardID).Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != len(tagIDs) {
+		return nil, errTagNotFound
+	}
+	return append(tags, newTags...), nil
+}
+
+// Links the task to exactly the tags among the live tags of its board. The links to tags in the trash are kept,
+// so that the tags are restored together with them.
+func replaceTagsTx(tx *gorm.DB, task *models.Task, tags []*models.Tag) error {
+	liveTagIDs := tx.Model(&models.Tag{}).Select("id").Where("board_id = ?", task.BoardID)
+	var err error
+	if len(tags) == 0 {
+		err = tx.Exec("DELETE FROM task_tags WHERE task_id = ? AND tag_id IN (?)", task.ID, liveTagIDs).Error
+	} else {
+		var tagIDs []string
+		for _, tag := range tags {
+			tagIDs = append(tagIDs, tag.ID)
+		}
+		err = tx.Exec("DELETE FROM task_tags WHERE task_id = ? AND tag_id IN (?) AND tag_id NOT IN ?",
+			task.ID, liveTagIDs, tagIDs).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(tags) > 0 {
+		// Only the links are created, as the tags already exist. The loaded tags are cleared first, as they
+		// would be linked again otherwise.
+		task.Tags = nil
+		err = tx.Model(task).Omit("Tags.*").Association("Tags").Append(&tags)
+		if err != nil {
+			return err
+		}
+	}
+	task.Tags = tags
+	return nil
+}
+
+// Loads the tags and the assignees of tasks, with the assignees in the order that they were assigned
+func preloadTask(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Tags").Preload("Assignees", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("created_at")
+	})
+}
+
 func getTask(taskId string) (models.Task, error) {
 	task := models.Task{ID: taskId}
-	result := db.DB.Preload("Tags").Model(&task).Find(&task)
+	result := preloadTask(db.DB).Model(&task).Find(&task)
 	return task, result.Error
 }
 
-func CreateTask(payload views.CreateTaskPayload) views.CreateTaskResponse {
-	var tags []*models.Tag
-	for _, tag := range payload.Tags {
-		tags = append(tags, &models.Tag{
-			ID:      tag.ID,
-			Name:    tag.Name,
-			Color:   tag.Color,
-			BoardID: tag.BoardID,
-		})
+// Locks the state until the transaction ends, so that tasks are positioned in it one at a time
+func lockState(tx *gorm.DB, boardID string, stateID string) error {
+	var state models.State
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ? AND board_id = ?", stateID, boardID).
+		First(&state).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrStateNotFound
+	}
+	return err
+}
+
+// Lists the tasks in the state by position, leaving out the given task
+func getStateTaskIDs(tx *gorm.DB, stateID string, excludedTaskID string) (taskIDs []string, err error) {
+	err = tx.Model(&models.Task{}).
+		Where("state_id = ? AND id <> ?", stateID, excludedTaskID).
+		Order("position, id").
+		Pluck("id", &taskIDs).
+		Error
+	return
+}
+
+// Spreads out the positions of the tasks in order, where an empty ID holds a position for a task that is
+// yet to be created. Returns the positions given to the tasks. The state must be locked.
+func spreadTx(tx *gorm.DB, taskIDs []string) ([]string, error) {
+	positions := rankUtils.Spread(len(taskIDs))
+	for i, taskID := range taskIDs {
+		if len(taskID) == 0 {
+			continue
+		}
+		err := tx.Model(&models.Task{ID: taskID}).UpdateColumn("position", positions[i]).Error
+		if err != nil {
+			return positions, err
+		}
+	}
+	return positions, nil
+}
+
+// Locks the state of the board and returns the position after its last task, within the caller's transaction
+func AppendPositionTx(tx *gorm.DB, boardID string, stateID string) (string, error) {
+	err := lockState(tx, boardID, stateID)
+	if err != nil {
+		return "", err
+	}
+
+	var last []string
+	err = tx.Model(&models.Task{}).
+		Where("state_id = ?", stateID).
+		Order("position DESC").
+		Limit(1).
+		Pluck("position", &last).
+		Error
+	if err != nil {
+		return "", err
+	}
+	position := rankUtils.Between("", "")
+	if len(last) > 0 {
+		position = rankUtils.Between(last[0], "")
+	}
+	if len(position) <= rankUtils.MaxLength {
+		return position, nil
+	}
+
+	taskIDs, err := getStateTaskIDs(tx, stateID, "")
+	if err != nil {
+		return "", err
+	}
+	positions, err := spreadTx(tx, append(taskIDs, ""))
+	return positions[len(taskIDs)], err
+}
+
+// Moves the tasks of the state to the end of the other state, keeping their order, within the caller's transaction.
+// Tasks in the trash are moved as well, so that they are restored into the other state. Both states are locked in
+// the order of their IDs, so that no task is added to either state meanwhile. The board must be locked beforehand.
+func MoveStateTasksTx(tx *gorm.DB, boardID string, fromStateID string, toStateID string) (moved int, err error) {
+	stateIDs := []string{fromStateID, toStateID}
+	sort.Strings(stateIDs)
+	for _, stateID := range stateIDs {
+		err = lockState(tx, boardID, stateID)
+		if err != nil {
+			return
+		}
+	}
+
+	taskIDs, err := getStateTaskIDs(tx, toStateID, "")
+	if err != nil {
+		return
+	}
+	movedTaskIDs, err := getStateTaskIDs(tx, fromStateID, "")
+	if err != nil {
+		return
+	}
+
+	err = tx.Unscoped().Model(&models.Task{}).Where("state_id = ?", fromStateID).UpdateColumn("state_id", toStateID).Error
+	if err != nil {
+		return
+	}
+	_, err = spreadTx(tx, append(taskIDs, movedTaskIDs...))
+	return len(movedTaskIDs), err
+}
+
+// Creates a task created by the actor, at the end of its state
+func CreateTask(actor views.AuthUserView, permissions permissionTypes.Set, payload views.CreateTaskPayload) views.CreateTaskResponse {
+	if len(payload.UserID) > 0 && payload.UserID != actor.ID {
+		return views.CreateTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(userMismatchMessage, payload.UserID),
+				Code:    http.StatusForbidden,
+			},
+		}
 	}
+
 	task := models.Task{
 		Name:        payload.Name,
 		Description: payload.Description,
 		StateID:     payload.StateID,
-		Tags:        tags,
 		BoardID:     payload.BoardID,
-		UserID:      payload.UserID,
+		UserID:      actor.ID,
 	}
 	if len(payload.DueAt) > 0 {
 		dueAt, _ := time.Parse(datetime.DatetimeLayout, payload.DueAt)
 		task.DueAt = &dueAt
 	}
-	err := db.DB.Create(&task).Error
+	err := db.DB.Transaction(func(tx *gorm.DB) (err error) {
+		task.Tags, err = getBoardTags(tx, task.BoardID, permissions, payload.Tags)
+		if err != nil {
+			return
+		}
+		task.Position, err = AppendPositionTx(tx, task.BoardID, task.StateID)
+		if err != nil {
+			return
+		}
+		return tx.Create(&task).Error
+	})
+	task.Assignees = []*models.TaskAssignee{}
+	if errors.Is(err, ErrStateNotFound) {
+		return views.CreateTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(stateNotFoundMessage, payload.StateID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errTagNotFound) {
+		return views.CreateTaskResponse{
+			Response: views.Response{
+				Message: tagNotFoundMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errManageTags) {
+		return views.CreateTaskResponse{
+			Response: views.Response{
+				Message: manageTagsMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if errors.Is(err, errEmptyTagName) {
+		return views.CreateTaskResponse{
+			Response: views.Response{
+				Message: emptyTagNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
 	if err != nil {
 		return views.CreateTaskResponse{
 			Response: views.Response{
@@ -71,7 +336,37 @@ func CreateTask(payload views.CreateTaskPayload) views.CreateTaskResponse {
 	}
 }
 
-func UpdateTask(payload views.UpdateTaskPayload) views.UpdateTaskResponse {
+// Returns whether the update changes anything besides the state of the task
+func editsTask(task models.Task, payload views.UpdateTaskPayload) bool {
+	if task.Name != payload.Name || task.Description != payload.Description {
+		return true
+	}
+	if len(payload.DueAt) > 0 {
+		dueAt, _ := time.Parse(datetime.DatetimeLayout, payload.DueAt)
+		if task.DueAt == nil || !task.DueAt.Equal(dueAt) {
+			return true
+		}
+	}
+	// The tags are compared as sets, as the payload may list a tag more than once
+	tagIDs := map[string]bool{}
+	for _, tag := range task.Tags {
+		tagIDs[tag.ID] = true
+	}
+	payloadTagIDs := map[string]bool{}
+	for _, tag := range payload.Tags {
+		// Tags without an ID are created
+		if len(tag.ID) == 0 || !tagIDs[tag.ID] {
+			return true
+		}
+		payloadTagIDs[tag.ID] = true
+	}
+	return len(payloadTagIDs) != len(tagIDs)
+}
+
+// Updates the task on behalf of the actor, while preserving the board, the creator and the assignees of the task.
+// A task that changes state is placed at the end of the state. Without the permission to edit tasks, the actor can only move the task
+// to another state.
+func UpdateTask(actor views.AuthUserView, permissions permissionTypes.Set, payload views.UpdateTaskPayload) views.UpdateTaskResponse {
 	task, err := getTask(payload.ID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -90,37 +385,86 @@ func UpdateTask(payload views.UpdateTaskPayload) views.UpdateTaskResponse {
 		}
 	}
 
+	if len(payload.UserID) > 0 && payload.UserID != task.UserID {
+		return views.UpdateTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(userMismatchMessage, payload.UserID),
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if !permissions.Has(permissionTypes.EditTasks) && editsTask(task, payload) {
+		return views.UpdateTaskResponse{
+			Response: views.Response{
+				Message: moveOnlyMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
 	err = db.DB.Transaction(func(tx *gorm.DB) error {
-		var tags []*models.Tag
-		for _, tag := range payload.Tags {
-			tags = append(tags, &models.Tag{
-				ID:      tag.ID,
-				Name:    tag.Name,
-				Color:   tag.Color,
-				BoardID: tag.BoardID,
-			})
+		tags, err := getBoardTags(tx, task.BoardID, permissions, payload.Tags)
+		if err != nil {
+			return err
+		}
+
+		if task.StateID != payload.StateID {
+			position, err := AppendPositionTx(tx, task.BoardID, payload.StateID)
+			if err != nil {
+				return err
+			}
+			task.Position = position
 		}
 
 		task.Name = payload.Name
 		task.Description = payload.Description
 		task.StateID = payload.StateID
-		task.BoardID = payload.BoardID
-		task.UserID = payload.UserID
 
 		if len(payload.DueAt) > 0 {
 			dueAt, _ := time.Parse(datetime.DatetimeLayout, payload.DueAt)
 			task.DueAt = &dueAt
 		}
 
-		err := tx.Model(&models.Task{ID: task.ID}).Save(&task).Error
+		err = tx.Model(&models.Task{ID: task.ID}).Omit("user_id", "board_id", "Tags", "Assignees").Save(&task).Error
 		if err != nil {
 			return err
 		}
 
-		err = tx.Model(&task).Association("Tags").Replace(&tags)
-		return err
+		return replaceTagsTx(tx, &task, tags)
 	})
 
+	if errors.Is(err, ErrStateNotFound) {
+		return views.UpdateTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(stateNotFoundMessage, payload.StateID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errTagNotFound) {
+		return views.UpdateTaskResponse{
+			Response: views.Response{
+				Message: tagNotFoundMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errManageTags) {
+		return views.UpdateTaskResponse{
+			Response: views.Response{
+				Message: manageTagsMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if errors.Is(err, errEmptyTagName) {
+		return views.UpdateTaskResponse{
+			Response: views.Response{
+				Message: emptyTagNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
 	if err != nil {
 		return views.UpdateTaskResponse{
 			Response: views.Response{
@@ -157,14 +501,8 @@ func DeleteTask(payload views.DeleteTaskPayload) views.DeleteTaskResponse {
 		}
 	}
 
-	err = db.DB.Transaction(func(tx *gorm.DB) error {
-		// Remove the association between the task and its tags
-		err := tx.Model(&task).Association("Tags").Delete(&task.Tags)
-		if err != nil {
-			return err
-		}
-		return tx.Delete(&task).Error
-	})
+	// Moves the task to the trash, keeping its links to tags so that it can be restored
+	err = db.DB.Delete(&task).Error
 
 	if err != nil {
 		return views.DeleteTaskResponse{
@@ -181,3 +519,279 @@ func DeleteTask(payload views.DeleteTaskPayload) views.DeleteTaskResponse {
 		},
 	}
 }
+
+// Moves the task into the state, right after another task in the state or first without one.
+// Positions are spread out again once they become too long to insert between.
+func MoveTask(payload views.MoveTaskPayload) views.MoveTaskResponse {
+	var task models.Task
+	err := preloadTask(db.DB).Where("id = ?", payload.ID).First(&task).Error
+	if err == nil && payload.AfterTaskID == task.ID {
+		err = errAnchorNotFound
+	}
+
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			// The state is locked before the task, as when creating and updating tasks
+			err := lockState(tx, task.BoardID, payload.StateID)
+			if err != nil {
+				return err
+			}
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", task.ID).First(&models.Task{}).Error
+			if err != nil {
+				return err
+			}
+
+			before := ""
+			if len(payload.AfterTaskID) > 0 {
+				var anchor models.Task
+				err = tx.Select("position").
+					Where("id = ? AND state_id = ?", payload.AfterTaskID, payload.StateID).
+					First(&anchor).
+					Error
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errAnchorNotFound
+				}
+				if err != nil {
+					return err
+				}
+				before = anchor.Position
+			}
+
+			var next []string
+			err = tx.Model(&models.Task{}).
+				Where("state_id = ? AND id <> ? AND position > ?", payload.StateID, task.ID, before).
+				Order("position").
+				Limit(1).
+				Pluck("position", &next).
+				Error
+			if err != nil {
+				return err
+			}
+
+			task.StateID = payload.StateID
+			after := ""
+			if len(next) > 0 {
+				after = next[0]
+			}
+			task.Position = rankUtils.Between(before, after)
+			if len(task.Position) > rankUtils.MaxLength {
+				taskIDs, err := getStateTaskIDs(tx, payload.StateID, task.ID)
+				if err != nil {
+					return err
+				}
+				index := 0
+				for i, taskID := range taskIDs {
+					if taskID == payload.AfterTaskID {
+						index = i + 1
+					}
+				}
+				taskIDs = append(taskIDs[:index], append([]string{task.ID}, taskIDs[index:]...)...)
+				positions, err := spreadTx(tx, taskIDs)
+				if err != nil {
+					return err
+				}
+				task.Position = positions[index]
+			}
+			return tx.Model(&models.Task{ID: task.ID}).
+				Select("state_id", "position").
+				Updates(&models.Task{StateID: task.StateID, Position: task.Position}).
+				Error
+		})
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.MoveTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(taskNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, ErrStateNotFound) {
+		return views.MoveTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(stateNotFoundMessage, payload.StateID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errAnchorNotFound) {
+		return views.MoveTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(anchorNotFoundMessage, payload.AfterTaskID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.MoveTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToMoveTaskMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	return views.MoveTaskResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyMovedTaskMessage, task.Name),
+			Code:    http.StatusOK,
+		},
+		Task: task,
+	}
+}
+
+// Assigns the task to a user who can access its board, whether as a member, through a team or as an admin
+// of the board's shared workspace. Assigning a user again keeps the first assignment.
+func AssignTask(payload views.AssignTaskPayload) views.AssignTaskResponse {
+	var task models.Task
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("id", "board_id").Where("id = ?", payload.ID).First(&task).Error
+		if err != nil {
+			return err
+		}
+
+		// Holds the board until the end of the transaction, so that a user who is losing access to it
+		// cannot be assigned after being unassigned from the tasks of the board
+		var board models.Board
+		err = tx.Unscoped().
+			Clauses(clause.Locking{Strength: "SHARE"}).
+			Select("id").
+			Where("id = ?", task.BoardID).
+			First(&board).
+			Error
+		if err != nil {
+			return err
+		}
+		accessible, err := authorizationService.HasBoardAccessTx(tx, payload.UserID, board.ID)
+		if err != nil {
+			return err
+		}
+		if !accessible {
+			return errNotBoardMember
+		}
+
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.TaskAssignee{TaskID: task.ID, UserID: payload.UserID}).
+			Error
+		if err != nil {
+			return err
+		}
+		return preloadTask(tx).Where("id = ?", task.ID).First(&task).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.AssignTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(taskNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errNotBoardMember) {
+		return views.AssignTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(notBoardMemberMessage, payload.UserID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.AssignTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToAssignMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	return views.AssignTaskResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyAssignedMessage, task.Name),
+			Code:    http.StatusOK,
+		},
+		Task: task,
+	}
+}
+
+func UnassignTask(payload views.UnassignTaskPayload) views.UnassignTaskResponse {
+	var task models.Task
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("id").Where("id = ?", payload.ID).First(&task).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("task_id = ? AND user_id = ?", task.ID, payload.UserID).Delete(&models.TaskAssignee{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNotAssigned
+		}
+		return preloadTask(tx).Where("id = ?", task.ID).First(&task).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UnassignTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(taskNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errNotAssigned) {
+		return views.UnassignTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(notAssignedMessage, payload.UserID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.UnassignTaskResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToUnassignMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	return views.UnassignTaskResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyUnassignedMessage, task.Name),
+			Code:    http.StatusOK,
+		},
+		Task: task,
+	}
+}
+
+// Unassigns the users who can no longer access the boards from their tasks, including the tasks in the trash,
+// within the caller's transaction that removed the access. The boards are locked first, so that the removal
+// waits for assignments in progress and no such user can be assigned afterwards.
+func UnassignInaccessibleTx(tx *gorm.DB, boardIDs []string) error {
+	if len(boardIDs) == 0 {
+		return nil
+	}
+	var boards []models.Board
+	err := tx.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id IN ?", boardIDs).
+		Order("id").
+		Find(&boards).
+		Error
+	if err != nil {
+		return err
+	}
+	return tx.Exec(`DELETE FROM task_assignees USING tasks
+		WHERE task_assignees.task_id = tasks.id AND tasks.board_id IN ?
+		AND NOT EXISTS (SELECT 1 FROM members
+			WHERE members.board_id = tasks.board_id AND members.user_id = task_assignees.user_id)
+		AND NOT EXISTS (SELECT 1 FROM board_teams JOIN team_members ON team_members.team_id = board_teams.team_id
+			WHERE board_teams.board_id = tasks.board_id AND team_members.user_id = task_assignees.user_id)
+		AND NOT EXISTS (SELECT 1 FROM boards
+			JOIN workspaces ON workspaces.id = boards.workspace_id
+			JOIN workspace_members ON workspace_members.workspace_id = workspaces.id
+			WHERE boards.id = tasks.board_id AND workspaces.user_id IS NULL
+			AND workspace_members.user_id = task_assignees.user_id AND workspace_members.role = ?)`,
+		boardIDs, workspaceTypes.Admin,
+	).Error
+}
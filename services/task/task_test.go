@@ -0,0 +1,158 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	datetime "github.com/EmilyOng/tusk-manager/backend/utils/datetime"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+)
+
+func tagViews(tagIDs ...string) []views.TagMinimalView {
+	tags := []views.TagMinimalView{}
+	for _, tagID := range tagIDs {
+		tags = append(tags, views.TagMinimalView{ID: tagID, Name: "Tag " + tagID})
+	}
+	return tags
+}
+
+func TestEditsTask(t *testing.T) {
+	dueAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	task := models.Task{
+		Name:        "Task",
+		Description: "Description",
+		DueAt:       &dueAt,
+		Tags:        []*models.Tag{{ID: "a"}, {ID: "b"}},
+	}
+	payload := func(tags []views.TagMinimalView) views.UpdateTaskPayload {
+		return views.UpdateTaskPayload{
+			Name:        task.Name,
+			Description: task.Description,
+			DueAt:       dueAt.Format(datetime.DatetimeLayout),
+			StateID:     "another-state",
+			Tags:        tags,
+		}
+	}
+
+	renamed := payload(tagViews("a", "b"))
+	renamed.Name = "Renamed"
+	rescheduled := payload(tagViews("a", "b"))
+	rescheduled.DueAt = dueAt.Add(time.Hour).Format(datetime.DatetimeLayout)
+
+	tests := []struct {
+		name    string
+		payload views.UpdateTaskPayload
+		edits   bool
+	}{
+		{name: "moves only", payload: payload(tagViews("a", "b")), edits: false},
+		{name: "reorders the tags", payload: payload(tagViews("b", "a")), edits: false},
+		{name: "repeats a tag", payload: payload(tagViews("a", "b", "b")), edits: false},
+		{name: "renames", payload: renamed, edits: true},
+		{name: "reschedules", payload: rescheduled, edits: true},
+		{name: "removes a tag", payload: payload(tagViews("a")), edits: true},
+		{name: "removes a tag behind a duplicate", payload: payload(tagViews("a", "a")), edits: true},
+		{name: "replaces a tag", payload: payload(tagViews("a", "c")), edits: true},
+		{name: "adds a tag", payload: payload(tagViews("a", "b", "c")), edits: true},
+		{name: "creates a tag", payload: payload(append(tagViews("a", "b"), views.TagMinimalView{Name: "New"})), edits: true},
+		{name: "clears the tags", payload: payload(tagViews()), edits: true},
+	}
+
+	for _, test := range tests {
+		if edits := editsTask(task, test.payload); edits != test.edits {
+			t.Errorf("%s: editsTask() = %v, want %v", test.name, edits, test.edits)
+		}
+	}
+}
+
+func getTaskTagIDs(t *testing.T, taskID string) []string {
+	t.Helper()
+	task, err := getTask(taskID)
+	if err != nil {
+		t.Fatalf("unable to get the task: %v", err)
+	}
+	tagIDs := []string{}
+	for _, tag := range task.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	sort.Strings(tagIDs)
+	return tagIDs
+}
+
+func TestMoveOnlyUpdatesKeepTheTags(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	state := dbTestUtils.CreateState(t, board.ID)
+	otherState := dbTestUtils.CreateState(t, board.ID)
+	a := dbTestUtils.CreateTag(t, board.ID)
+	b := dbTestUtils.CreateTag(t, board.ID)
+	actor := views.AuthUserView{ID: owner.ID}
+
+	created := CreateTask(actor, permissionTypes.NewSet(roleTypes.Owner.Permissions()...), views.CreateTaskPayload{
+		Name:    "Task",
+		StateID: state.ID,
+		BoardID: board.ID,
+		Tags:    tagViews(a.ID, b.ID),
+	})
+	if created.Code != http.StatusOK {
+		t.Fatalf("CreateTask() = %d %s, want %d", created.Code, created.Message, http.StatusOK)
+	}
+	tagIDs := getTaskTagIDs(t, created.Task.ID)
+	if len(tagIDs) != 2 {
+		t.Fatalf("the task was created with the tags %v, want both tags", tagIDs)
+	}
+
+	mover := permissionTypes.NewSet(permissionTypes.ViewBoard, permissionTypes.MoveTasks)
+	payload := func(tags []views.TagMinimalView) views.UpdateTaskPayload {
+		return views.UpdateTaskPayload{ID: created.Task.ID, Name: "Task", StateID: otherState.ID, Tags: tags}
+	}
+
+	// The duplicate hides that the other tag is left out
+	dropped := UpdateTask(actor, mover, payload(tagViews(a.ID, a.ID)))
+	if dropped.Code != http.StatusForbidden || dropped.Message != moveOnlyMessage {
+		t.Errorf("dropping a tag behind a duplicate while moving = %d %s, want %d", dropped.Code, dropped.Message, http.StatusForbidden)
+	}
+	if after := getTaskTagIDs(t, created.Task.ID); len(after) != len(tagIDs) {
+		t.Fatalf("the task has the tags %v after a refused update, want %v", after, tagIDs)
+	}
+
+	moved := UpdateTask(actor, mover, payload(tagViews(b.ID, a.ID, b.ID)))
+	if moved.Code != http.StatusOK {
+		t.Fatalf("moving the task with the same tags = %d %s, want %d", moved.Code, moved.Message, http.StatusOK)
+	}
+	if after := getTaskTagIDs(t, created.Task.ID); len(after) != len(tagIDs) || after[0] != tagIDs[0] || after[1] != tagIDs[1] {
+		t.Errorf("the task has the tags %v after being moved, want %v", after, tagIDs)
+	}
+}
+
+func TestNewTagsRequireTheManageTagsPermission(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	state := dbTestUtils.CreateState(t, board.ID)
+	actor := views.AuthUserView{ID: owner.ID}
+	payload := views.CreateTaskPayload{
+		Name:    "Task",
+		StateID: state.ID,
+		BoardID: board.ID,
+		Tags:    []views.TagMinimalView{{Name: "New"}},
+	}
+
+	creator := permissionTypes.NewSet(permissionTypes.ViewBoard, permissionTypes.CreateTasks)
+	refused := CreateTask(actor, creator, payload)
+	if refused.Code != http.StatusForbidden || refused.Message != manageTagsMessage {
+		t.Errorf("creating a tag without the permission to manage tags = %d %s, want %d", refused.Code, refused.Message, http.StatusForbidden)
+	}
+
+	creator.Add(permissionTypes.ManageTags)
+	created := CreateTask(actor, creator, payload)
+	if created.Code != http.StatusOK || len(created.Task.Tags) != 1 || created.Task.Tags[0].BoardID != board.ID {
+		t.Errorf("creating a tag with the permission to manage tags = %d %s, want %d with the new tag", created.Code, created.Message, http.StatusOK)
+	}
+}
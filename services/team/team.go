@@ -0,0 +1,733 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	taskService "github.com/EmilyOng/tusk-manager/backend/services/task"
+	userService "github.com/EmilyOng/tusk-manager/backend/services/user"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+)
+
+const (
+	unableToGetTeamsMessage         = "Unable to retrieve the teams."
+	unableToCreateTeamMessage       = "Unable to create the team '%s'."
+	unableToUpdateTeamMessage       = "Unable to update the team (%s)."
+	unableToDeleteTeamMessage       = "Unable to delete the team (%s)."
+	unableToCreateTeamMemberMessage = "Unable to add '%s' to the team."
+	unableToDeleteTeamMemberMessage = "Unable to remove the team member (%s)."
+	unableToGetBoardTeamsMessage    = "Unable to retrieve the teams of the board (%s)."
+	unableToCreateBoardTeamMessage  = "Unable to share the board with the team (%s)."
+	unableToUpdateBoardTeamMessage  = "Unable to update the access of the team (%s)."
+	unableToDeleteBoardTeamMessage  = "Unable to revoke the access of the team (%s)."
+	emptyTeamNameMessage            = "The name of the team cannot be empty."
+	teamNotFoundMessage             = "The team cannot be found (%s)."
+	teamMemberNotFoundMessage       = "The user is not a member of the team (%s)."
+	teamMemberExistsMessage         = "'%s' is already a member of the team."
+	notWorkspaceMemberMessage       = "'%s' must be a member of the workspace to join its teams."
+	personalWorkspaceMessage        = "Personal workspaces cannot have teams."
+	boardTeamNotFoundMessage        = "The team does not have access to the board (%s)."
+	boardTeamExistsMessage          = "The board is already shared with the team '%s'."
+	invalidRoleMessage              = "The role '%s' is not valid."
+	customRoleNotFoundMessage       = "The custom role cannot be found on the board (%s)."
+	permissionNotHeldMessage        = "You cannot grant or revoke permissions that you do not hold."
+	unableToVerifyGrantMessage      = "Unable to verify the permissions to grant."
+
+	successfullyCreatedTeamMessage       = "Successfully created the team '%s'!"
+	successfullyUpdatedTeamMessage       = "Successfully updated the team '%s'!"
+	successfullyDeletedTeamMessage       = "Successfully deleted the team '%s'!"
+	successfullyCreatedTeamMemberMessage = "'%s' has been added to the team!"
+	successfullyDeletedTeamMemberMessage = "Successfully removed the team member!"
+	successfullyCreatedBoardTeamMessage  = "Board has been shared with the team '%s'!"
+	successfullyUpdatedBoardTeamMessage  = "Successfully updated the access of the team '%s'!"
+	successfullyDeletedBoardTeamMessage  = "Successfully revoked the access of the team!"
+)
+
+var (
+	errPersonalWorkspace  = errors.New("the workspace is personal")
+	errNotWorkspaceMember = errors.New("the user is not a member of the workspace")
+	errTeamMemberExists   = errors.New("the user is already a member of the team")
+	errBoardTeamExists    = errors.New("the board is already shared with the team")
+)
+
+func toView(team models.Team) views.TeamView {
+	teamView := views.TeamView{
+		TeamMinimalView: views.TeamMinimalView{ID: team.ID, Name: team.Name},
+		Members:         []views.UserMinimalView{},
+	}
+	for _, teamMember := range team.Members {
+		teamView.Members = append(teamView.Members, views.UserMinimalView{
+			ID:    teamMember.User.ID,
+			Name:  teamMember.User.Name,
+			Email: teamMember.User.Email,
+		})
+	}
+	return teamView
+}
+
+func toBoardTeamView(boardTeam models.BoardTeam, team models.Team) views.BoardTeamView {
+	return views.BoardTeamView{
+		ID:           boardTeam.ID,
+		Role:         boardTeam.Role,
+		CustomRoleID: boardTeam.CustomRoleID,
+		Team:         views.TeamMinimalView{ID: team.ID, Name: team.Name},
+	}
+}
+
+// Ensures that the grantor holds the permissions of the grant, describing why not otherwise
+func checkGrant(boardID string, grantor permissionTypes.Set, role roleTypes.Role, customRoleID *string) *views.Response {
+	err := authorizationService.CheckGrant(db.DB, boardID, grantor, role, customRoleID)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &views.Response{
+			Message: fmt.Sprintf(customRoleNotFoundMessage, *customRoleID),
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+	if errors.Is(err, authorizationService.ErrPermissionNotHeld) {
+		return &views.Response{
+			Message: permissionNotHeldMessage,
+			Code:    http.StatusForbidden,
+		}
+	}
+	return &views.Response{
+		Message: unableToVerifyGrantMessage,
+		Code:    http.StatusInternalServerError,
+	}
+}
+
+// Retrieves the team of the workspace, together with its members
+func findTeam(tx *gorm.DB, teamID string, workspaceID string) (team models.Team, err error) {
+	err = tx.Where("id = ? AND workspace_id = ?", teamID, workspaceID).
+		Preload("Members.User").
+		First(&team).
+		Error
+	return
+}
+
+func GetWorkspaceTeams(payload views.GetWorkspaceTeamsPayload) views.GetWorkspaceTeamsResponse {
+	var teams []models.Team
+	err := db.DB.Where("workspace_id = ?", payload.WorkspaceID).
+		Preload("Members.User").
+		Order("name").
+		Find(&teams).
+		Error
+	if err != nil {
+		return views.GetWorkspaceTeamsResponse{
+			Response: views.Response{
+				Message: unableToGetTeamsMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	teamsView := []views.TeamView{}
+	for _, team := range teams {
+		teamsView = append(teamsView, toView(team))
+	}
+	return views.GetWorkspaceTeamsResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Teams:    teamsView,
+	}
+}
+
+// Creates a team in a shared workspace
+func CreateTeam(payload views.CreateTeamPayload) views.CreateTeamResponse {
+	name := strings.TrimSpace(payload.Name)
+	if len(name) == 0 {
+		return views.CreateTeamResponse{
+			Response: views.Response{
+				Message: emptyTeamNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	team := models.Team{Name: name, WorkspaceID: payload.WorkspaceID}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var workspace models.Workspace
+		err := tx.Where("id = ?", payload.WorkspaceID).First(&workspace).Error
+		if err != nil {
+			return err
+		}
+		if workspace.UserID != nil {
+			return errPersonalWorkspace
+		}
+		return tx.Create(&team).Error
+	})
+
+	if errors.Is(err, errPersonalWorkspace) {
+		return views.CreateTeamResponse{
+			Response: views.Response{
+				Message: personalWorkspaceMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.CreateTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateTeamMessage, name),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.CreateTeamResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedTeamMessage, team.Name),
+			Code:    http.StatusOK,
+		},
+		Team: toView(team),
+	}
+}
+
+func UpdateTeam(payload views.UpdateTeamPayload) views.UpdateTeamResponse {
+	name := strings.TrimSpace(payload.Name)
+	if len(name) == 0 {
+		return views.UpdateTeamResponse{
+			Response: views.Response{
+				Message: emptyTeamNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	team, err := findTeam(db.DB, payload.ID, payload.WorkspaceID)
+	if err == nil {
+		team.Name = name
+		err = db.DB.Model(&team).Update("name", team.Name).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UpdateTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(teamNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.UpdateTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToUpdateTeamMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UpdateTeamResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyUpdatedTeamMessage, team.Name),
+			Code:    http.StatusOK,
+		},
+		Team: toView(team),
+	}
+}
+
+// Deletes the team, together with its access to the boards of the workspace
+func DeleteTeam(origin auditService.Origin, payload views.DeleteTeamPayload) views.DeleteTeamResponse {
+	var team models.Team
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ? AND workspace_id = ?", payload.ID, payload.WorkspaceID).First(&team).Error
+		if err != nil {
+			return err
+		}
+
+		boardIDs, err := getTeamBoardIDs(tx, team.ID)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("team_id = ?", team.ID).Delete(&models.BoardTeam{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("team_id = ?", team.ID).Delete(&models.TeamMember{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&team).Error
+		if err != nil {
+			return err
+		}
+		err = taskService.UnassignInaccessibleTx(tx, boardIDs)
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.TeamDeleted,
+			TargetType: "team",
+			TargetID:   team.ID,
+			Details:    map[string]interface{}{"workspaceId": team.WorkspaceID, "name": team.Name},
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.DeleteTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(teamNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.DeleteTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToDeleteTeamMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeleteTeamResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyDeletedTeamMessage, team.Name),
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Adds a member of the workspace to the team
+func CreateTeamMember(origin auditService.Origin, payload views.CreateTeamMemberPayload) views.CreateTeamMemberResponse {
+	user, err := userService.FindUser(payload.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.CreateTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(notWorkspaceMemberMessage, payload.Email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var team models.Team
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			var workspaceMembers int64
+			err := tx.Model(&models.WorkspaceMember{}).
+				Where("user_id = ? AND workspace_id = ?", user.ID, payload.WorkspaceID).
+				Count(&workspaceMembers).
+				Error
+			if err != nil {
+				return err
+			}
+			if workspaceMembers == 0 {
+				return errNotWorkspaceMember
+			}
+
+			team, err = findTeam(tx, payload.TeamID, payload.WorkspaceID)
+			if err != nil {
+				return err
+			}
+			for _, teamMember := range team.Members {
+				if teamMember.UserID == user.ID {
+					return errTeamMemberExists
+				}
+			}
+
+			teamMember := models.TeamMember{UserID: user.ID, TeamID: team.ID}
+			err = tx.Create(&teamMember).Error
+			if err != nil {
+				return err
+			}
+			teamMember.User = &user
+			team.Members = append(team.Members, &teamMember)
+
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.TeamMemberAdded,
+				TargetType: "team",
+				TargetID:   team.ID,
+				Details:    map[string]interface{}{"workspaceId": team.WorkspaceID, "userId": user.ID},
+			})
+		})
+	}
+
+	if errors.Is(err, errNotWorkspaceMember) {
+		return views.CreateTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(notWorkspaceMemberMessage, payload.Email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.CreateTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(teamNotFoundMessage, payload.TeamID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errTeamMemberExists) {
+		return views.CreateTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(teamMemberExistsMessage, payload.Email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.CreateTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateTeamMemberMessage, payload.Email),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.CreateTeamMemberResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedTeamMemberMessage, user.Email),
+			Code:    http.StatusOK,
+		},
+		Team: toView(team),
+	}
+}
+
+// Removes the user from the team, which revokes the access granted through the team
+func DeleteTeamMember(origin auditService.Origin, payload views.DeleteTeamMemberPayload) views.DeleteTeamMemberResponse {
+	var team models.Team
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		team, err = findTeam(tx, payload.TeamID, payload.WorkspaceID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("team_id = ? AND user_id = ?", team.ID, payload.UserID).Delete(&models.TeamMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		boardIDs, err := getTeamBoardIDs(tx, team.ID)
+		if err != nil {
+			return err
+		}
+		err = taskService.UnassignInaccessibleTx(tx, boardIDs)
+		if err != nil {
+			return err
+		}
+
+		members := []*models.TeamMember{}
+		for _, teamMember := range team.Members {
+			if teamMember.UserID != payload.UserID {
+				members = append(members, teamMember)
+			}
+		}
+		team.Members = members
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.TeamMemberRemoved,
+			TargetType: "team",
+			TargetID:   team.ID,
+			Details:    map[string]interface{}{"workspaceId": team.WorkspaceID, "userId": payload.UserID},
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) && len(team.ID) == 0 {
+		return views.DeleteTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(teamNotFoundMessage, payload.TeamID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.DeleteTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(teamMemberNotFoundMessage, payload.UserID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.DeleteTeamMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToDeleteTeamMemberMessage, payload.UserID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeleteTeamMemberResponse{
+		Response: views.Response{
+			Message: successfullyDeletedTeamMemberMessage,
+			Code:    http.StatusOK,
+		},
+		Team: toView(team),
+	}
+}
+
+func GetBoardTeams(payload views.GetBoardTeamsPayload) views.GetBoardTeamsResponse {
+	var boardTeams []models.BoardTeam
+	err := db.DB.Where("board_id = ?", payload.BoardID).Preload("Team").Find(&boardTeams).Error
+	if err != nil {
+		return views.GetBoardTeamsResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToGetBoardTeamsMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	teamsView := []views.BoardTeamView{}
+	for _, boardTeam := range boardTeams {
+		teamsView = append(teamsView, toBoardTeamView(boardTeam, *boardTeam.Team))
+	}
+	return views.GetBoardTeamsResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Teams:    teamsView,
+	}
+}
+
+// Gives every member of a team in the board's workspace the role on the board.
+// The grantor must hold the permissions of the role.
+func CreateBoardTeam(origin auditService.Origin, grantor permissionTypes.Set, payload views.CreateBoardTeamPayload) views.CreateBoardTeamResponse {
+	if !payload.Role.IsValid() {
+		return views.CreateBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	response := checkGrant(payload.BoardID, grantor, payload.Role, payload.CustomRoleID)
+	if response != nil {
+		return views.CreateBoardTeamResponse{Response: *response}
+	}
+
+	var team models.Team
+	boardTeam := models.BoardTeam{
+		Role:         payload.Role,
+		TeamID:       payload.TeamID,
+		BoardID:      payload.BoardID,
+		CustomRoleID: payload.CustomRoleID,
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ? AND workspace_id IN (?)",
+			payload.TeamID,
+			tx.Model(&models.Board{}).Select("workspace_id").Where("id = ?", payload.BoardID),
+		).First(&team).Error
+		if err != nil {
+			return err
+		}
+
+		var existingTeams int64
+		err = tx.Model(&models.BoardTeam{}).
+			Where("team_id = ? AND board_id = ?", team.ID, payload.BoardID).
+			Count(&existingTeams).
+			Error
+		if err != nil {
+			return err
+		}
+		if existingTeams > 0 {
+			return errBoardTeamExists
+		}
+
+		err = tx.Create(&boardTeam).Error
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.BoardTeamAdded,
+			TargetType: "board_team",
+			TargetID:   boardTeam.ID,
+			BoardID:    boardTeam.BoardID,
+			Details:    map[string]interface{}{"teamId": team.ID, "role": boardTeam.Role, "customRole": boardTeam.CustomRoleID},
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.CreateBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(teamNotFoundMessage, payload.TeamID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errBoardTeamExists) {
+		return views.CreateBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardTeamExistsMessage, team.Name),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.CreateBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateBoardTeamMessage, payload.TeamID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.CreateBoardTeamResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedBoardTeamMessage, team.Name),
+			Code:    http.StatusOK,
+		},
+		Team: toBoardTeamView(boardTeam, team),
+	}
+}
+
+// Changes the role of the team. The grantor must hold the permissions of both the previous and the new role.
+func UpdateBoardTeam(origin auditService.Origin, grantor permissionTypes.Set, payload views.UpdateBoardTeamPayload) views.UpdateBoardTeamResponse {
+	if !payload.Role.IsValid() {
+		return views.UpdateBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var boardTeam models.BoardTeam
+	err := db.DB.Where("id = ? AND board_id = ?", payload.ID, payload.BoardID).Preload("Team").First(&boardTeam).Error
+	if err == nil {
+		response := checkGrant(boardTeam.BoardID, grantor, boardTeam.Role, boardTeam.CustomRoleID)
+		if response == nil {
+			response = checkGrant(boardTeam.BoardID, grantor, payload.Role, payload.CustomRoleID)
+		}
+		if response != nil {
+			return views.UpdateBoardTeamResponse{Response: *response}
+		}
+
+		previousRole := boardTeam.Role
+		previousCustomRoleID := boardTeam.CustomRoleID
+		boardTeam.Role = payload.Role
+		boardTeam.CustomRoleID = payload.CustomRoleID
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			if previousRole == boardTeam.Role && sameCustomRole(previousCustomRoleID, boardTeam.CustomRoleID) {
+				return nil
+			}
+			err := tx.Model(&boardTeam).Select("role", "custom_role_id").Updates(&boardTeam).Error
+			if err != nil {
+				return err
+			}
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.BoardTeamRoleChanged,
+				TargetType: "board_team",
+				TargetID:   boardTeam.ID,
+				BoardID:    boardTeam.BoardID,
+				Details: map[string]interface{}{
+					"teamId":         boardTeam.TeamID,
+					"from":           previousRole,
+					"to":             boardTeam.Role,
+					"fromCustomRole": previousCustomRoleID,
+					"toCustomRole":   boardTeam.CustomRoleID,
+				},
+			})
+		})
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UpdateBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardTeamNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.UpdateBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToUpdateBoardTeamMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UpdateBoardTeamResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyUpdatedBoardTeamMessage, boardTeam.Team.Name),
+			Code:    http.StatusOK,
+		},
+		Team: toBoardTeamView(boardTeam, *boardTeam.Team),
+	}
+}
+
+func sameCustomRole(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Revokes the access of the team. The grantor must hold the permissions of the team's role.
+func DeleteBoardTeam(origin auditService.Origin, grantor permissionTypes.Set, payload views.DeleteBoardTeamPayload) views.DeleteBoardTeamResponse {
+	var response *views.Response
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var boardTeam models.BoardTeam
+		err := tx.Where("id = ? AND board_id = ?", payload.ID, payload.BoardID).First(&boardTeam).Error
+		if err != nil {
+			return err
+		}
+		response = checkGrant(boardTeam.BoardID, grantor, boardTeam.Role, boardTeam.CustomRoleID)
+		if response != nil {
+			return nil
+		}
+
+		err = tx.Delete(&boardTeam).Error
+		if err != nil {
+			return err
+		}
+		err = taskService.UnassignInaccessibleTx(tx, []string{boardTeam.BoardID})
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.BoardTeamRemoved,
+			TargetType: "board_team",
+			TargetID:   boardTeam.ID,
+			BoardID:    boardTeam.BoardID,
+			Details:    map[string]interface{}{"teamId": boardTeam.TeamID, "role": boardTeam.Role},
+		})
+	})
+
+	if err == nil && response != nil {
+		return views.DeleteBoardTeamResponse{Response: *response}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.DeleteBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardTeamNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.DeleteBoardTeamResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToDeleteBoardTeamMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeleteBoardTeamResponse{
+		Response: views.Response{
+			Message: successfullyDeletedBoardTeamMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Lists the boards that the team has access to
+func getTeamBoardIDs(tx *gorm.DB, teamID string) ([]string, error) {
+	var boardIDs []string
+	err := tx.Model(&models.BoardTeam{}).Where("team_id = ?", teamID).Pluck("board_id", &boardIDs).Error
+	return boardIDs, err
+}
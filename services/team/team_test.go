@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+)
+
+var ownerPermissions = permissionTypes.NewSet(roleTypes.Owner.Permissions()...)
+
+func createTestTeam(t *testing.T, workspaceID string) views.TeamView {
+	t.Helper()
+	response := CreateTeam(views.CreateTeamPayload{WorkspaceID: workspaceID, Name: "Team"})
+	if response.Code != http.StatusOK {
+		t.Fatalf("CreateTeam() = %d %s, want %d", response.Code, response.Message, http.StatusOK)
+	}
+	return response.Team
+}
+
+func TestTeamsBelongToSharedWorkspaces(t *testing.T) {
+	dbTestUtils.Setup(t)
+	user := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, user)
+
+	response := CreateTeam(views.CreateTeamPayload{WorkspaceID: board.WorkspaceID, Name: "Team"})
+	if response.Code != http.StatusUnprocessableEntity || response.Message != personalWorkspaceMessage {
+		t.Errorf("creating a team in a personal workspace = %d %s, want %d", response.Code, response.Message, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestTeamMembersBelongToTheWorkspace(t *testing.T) {
+	dbTestUtils.Setup(t)
+	admin := dbTestUtils.CreateUser(t)
+	workspace := dbTestUtils.CreateWorkspace(t, admin)
+	team := createTestTeam(t, workspace.ID)
+	origin := auditService.Origin{ActorID: admin.ID}
+
+	outsider := dbTestUtils.CreateUser(t)
+	response := CreateTeamMember(origin, views.CreateTeamMemberPayload{TeamID: team.ID, WorkspaceID: workspace.ID, Email: outsider.Email})
+	if response.Code != http.StatusUnprocessableEntity || response.Message != fmt.Sprintf(notWorkspaceMemberMessage, outsider.Email) {
+		t.Errorf("adding a user outside of the workspace = %d %s, want %d", response.Code, response.Message, http.StatusUnprocessableEntity)
+	}
+
+	dbTestUtils.AddWorkspaceMember(t, workspace.ID, outsider, workspaceTypes.Member)
+	response = CreateTeamMember(origin, views.CreateTeamMemberPayload{TeamID: team.ID, WorkspaceID: workspace.ID, Email: outsider.Email})
+	if response.Code != http.StatusOK {
+		t.Errorf("adding a member of the workspace = %d %s, want %d", response.Code, response.Message, http.StatusOK)
+	}
+	response = CreateTeamMember(origin, views.CreateTeamMemberPayload{TeamID: team.ID, WorkspaceID: workspace.ID, Email: outsider.Email})
+	if response.Code != http.StatusUnprocessableEntity || response.Message != fmt.Sprintf(teamMemberExistsMessage, outsider.Email) {
+		t.Errorf("adding a member of the team again = %d %s, want %d", response.Code, response.Message, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestBoardTeamGrantsAreLimitedToTheGrantor(t *testing.T) {
+	dbTestUtils.Setup(t)
+	admin := dbTestUtils.CreateUser(t)
+	workspace := dbTestUtils.CreateWorkspace(t, admin)
+	board, _ := dbTestUtils.CreateWorkspaceBoard(t, workspace.ID, admin)
+	team := createTestTeam(t, workspace.ID)
+	origin := auditService.Origin{ActorID: admin.ID}
+
+	// Manages the members without holding the permissions of an owner
+	manager := permissionTypes.NewSet(roleTypes.Editor.Permissions()...)
+	manager.Add(permissionTypes.ManageMembers)
+
+	created := CreateBoardTeam(origin, manager, views.CreateBoardTeamPayload{BoardID: board.ID, TeamID: team.ID, Role: roleTypes.Owner})
+	if created.Code != http.StatusForbidden {
+		t.Errorf("giving the team a role that is not held = %d %s, want %d", created.Code, created.Message, http.StatusForbidden)
+	}
+	created = CreateBoardTeam(origin, manager, views.CreateBoardTeamPayload{BoardID: board.ID, TeamID: team.ID, Role: roleTypes.Editor})
+	if created.Code != http.StatusOK {
+		t.Fatalf("giving the team a role that is held = %d %s, want %d", created.Code, created.Message, http.StatusOK)
+	}
+	updated := UpdateBoardTeam(origin, manager, views.UpdateBoardTeamPayload{ID: created.Team.ID, BoardID: board.ID, Role: roleTypes.Owner})
+	if updated.Code != http.StatusForbidden {
+		t.Errorf("promoting the team to a role that is not held = %d %s, want %d", updated.Code, updated.Message, http.StatusForbidden)
+	}
+
+	// Teams with the owner role can only be revoked by those holding every permission of an owner
+	updated = UpdateBoardTeam(origin, ownerPermissions, views.UpdateBoardTeamPayload{ID: created.Team.ID, BoardID: board.ID, Role: roleTypes.Owner})
+	if updated.Code != http.StatusOK {
+		t.Fatalf("promoting the team as an owner = %d %s, want %d", updated.Code, updated.Message, http.StatusOK)
+	}
+	deleted := DeleteBoardTeam(origin, manager, views.DeleteBoardTeamPayload{ID: created.Team.ID, BoardID: board.ID})
+	if deleted.Code != http.StatusForbidden {
+		t.Errorf("revoking an owner team without the permissions of an owner = %d %s, want %d", deleted.Code, deleted.Message, http.StatusForbidden)
+	}
+
+	// Teams of other workspaces cannot be given access
+	otherTeam := createTestTeam(t, dbTestUtils.CreateWorkspace(t, admin).ID)
+	created = CreateBoardTeam(origin, ownerPermissions, views.CreateBoardTeamPayload{BoardID: board.ID, TeamID: otherTeam.ID, Role: roleTypes.Viewer})
+	if created.Code != http.StatusUnprocessableEntity || created.Message != fmt.Sprintf(teamNotFoundMessage, otherTeam.ID) {
+		t.Errorf("giving a team of another workspace access = %d %s, want %d", created.Code, created.Message, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestTeamAccessEndsWithTheMembership(t *testing.T) {
+	dbTestUtils.Setup(t)
+	admin := dbTestUtils.CreateUser(t)
+	workspace := dbTestUtils.CreateWorkspace(t, admin)
+	board, _ := dbTestUtils.CreateWorkspaceBoard(t, workspace.ID, admin)
+	team := createTestTeam(t, workspace.ID)
+	origin := auditService.Origin{ActorID: admin.ID}
+
+	user := dbTestUtils.CreateUser(t)
+	dbTestUtils.AddWorkspaceMember(t, workspace.ID, user, workspaceTypes.Member)
+	added := CreateTeamMember(origin, views.CreateTeamMemberPayload{TeamID: team.ID, WorkspaceID: workspace.ID, Email: user.Email})
+	if added.Code != http.StatusOK {
+		t.Fatalf("adding the user to the team = %d %s, want %d", added.Code, added.Message, http.StatusOK)
+	}
+	created := CreateBoardTeam(origin, ownerPermissions, views.CreateBoardTeamPayload{BoardID: board.ID, TeamID: team.ID, Role: roleTypes.Editor})
+	if created.Code != http.StatusOK {
+		t.Fatalf("giving the team access = %d %s, want %d", created.Code, created.Message, http.StatusOK)
+	}
+
+	role, err := authorizationService.GetBoardRole(user.ID, board.ID)
+	if err != nil || role != roleTypes.Editor {
+		t.Fatalf("the member of the team holds %q (%v), want %q", role, err, roleTypes.Editor)
+	}
+
+	removed := DeleteTeamMember(origin, views.DeleteTeamMemberPayload{TeamID: team.ID, WorkspaceID: workspace.ID, UserID: user.ID})
+	if removed.Code != http.StatusOK {
+		t.Fatalf("removing the user from the team = %d %s, want %d", removed.Code, removed.Message, http.StatusOK)
+	}
+	_, err = authorizationService.GetBoardPermissions(user.ID, board.ID)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("the user can still access the board after leaving the team (%v)", err)
+	}
+}
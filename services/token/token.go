@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	scopeTypes "github.com/EmilyOng/tusk-manager/backend/types/scope"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	datetime "github.com/EmilyOng/tusk-manager/backend/utils/datetime"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+)
+
+const (
+	unableToCreateTokenMessage = "Unable to create token '%s'."
+	unableToGetTokensMessage   = "Unable to retrieve tokens."
+	unableToRevokeTokenMessage = "Unable to revoke token (%s)."
+	tokenNotFoundMessage       = "The token cannot be found (%s)."
+	emptyTokenNameMessage      = "The token name cannot be empty."
+	invalidScopesMessage       = "The scopes are not valid, at least one scope (e.g. 'tasks:write') is required."
+	invalidExpiryMessage       = "The expiry '%s' is not valid."
+	tokenBoardNotFoundMessage  = "The board cannot be found (%s)."
+
+	successfullyCreatedTokenMessage = "Successfully created token '%s'! Copy it now, as it will not be shown again."
+	successfullyRevokedTokenMessage = "Successfully revoked token '%s'!"
+)
+
+var errInvalidToken = errors.New("invalid personal access token")
+
+func GetScopes(token models.PersonalAccessToken) (scopes []scopeTypes.Scope) {
+	for _, scope := range strings.Fields(token.Scopes) {
+		scopes = append(scopes, scopeTypes.Scope(scope))
+	}
+	return
+}
+
+func toView(token models.PersonalAccessToken) views.PersonalAccessTokenView {
+	return views.PersonalAccessTokenView{
+		ID:         token.ID,
+		Name:       token.Name,
+		Scopes:     GetScopes(token),
+		BoardID:    token.BoardID,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func CreatePersonalAccessToken(
+	actor views.AuthUserView,
+	payload views.CreatePersonalAccessTokenPayload,
+) views.CreatePersonalAccessTokenResponse {
+	if len(strings.TrimSpace(payload.Name)) == 0 {
+		return views.CreatePersonalAccessTokenResponse{
+			Response: views.Response{
+				Message: emptyTokenNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var scopes []string
+	for _, scope := range payload.Scopes {
+		if !scope.IsValid() {
+			scopes = nil
+			break
+		}
+		scopes = append(scopes, string(scope))
+	}
+	if len(scopes) == 0 {
+		return views.CreatePersonalAccessTokenResponse{
+			Response: views.Response{
+				Message: invalidScopesMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	token := models.PersonalAccessToken{
+		Name:    payload.Name,
+		Scopes:  strings.Join(scopes, " "),
+		UserID:  actor.ID,
+		BoardID: payload.BoardID,
+	}
+
+	if len(payload.ExpiresAt) > 0 {
+		expiresAt, err := time.Parse(datetime.DatetimeLayout, payload.ExpiresAt)
+		if err != nil || expiresAt.Before(time.Now()) {
+			return views.CreatePersonalAccessTokenResponse{
+				Response: views.Response{
+					Message: fmt.Sprintf(invalidExpiryMessage, payload.ExpiresAt),
+					Code:    http.StatusUnprocessableEntity,
+				},
+			}
+		}
+		token.ExpiresAt = &expiresAt
+	}
+
+	if payload.BoardID != nil {
+		// The token can only be restricted to a board that the user is a member of
+		_, err := authorizationService.GetBoardRole(actor.ID, *payload.BoardID)
+		if err != nil {
+			return views.CreatePersonalAccessTokenResponse{
+				Response: views.Response{
+					Message: fmt.Sprintf(tokenBoardNotFoundMessage, *payload.BoardID),
+					Code:    http.StatusUnprocessableEntity,
+				},
+			}
+		}
+	}
+
+	secret, hash, err := authUtils.GenerateOpaqueToken()
+	if err == nil {
+		token.TokenHash = hash
+		err = db.DB.Create(&token).Error
+	}
+	if err != nil {
+		return views.CreatePersonalAccessTokenResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateTokenMessage, payload.Name),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	tokenView := toView(token)
+	tokenView.Token = authUtils.PersonalAccessTokenPrefix + secret
+	return views.CreatePersonalAccessTokenResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedTokenMessage, token.Name),
+			Code:    http.StatusOK,
+		},
+		Token: tokenView,
+	}
+}
+
+func GetPersonalAccessTokens(actor views.AuthUserView) views.GetPersonalAccessTokensResponse {
+	var tokens []models.PersonalAccessToken
+	err := db.DB.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", actor.ID).
+		Order("created_at").
+		Find(&tokens).
+		Error
+	if err != nil {
+		return views.GetPersonalAccessTokensResponse{
+			Response: views.Response{
+				Message: unableToGetTokensMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	var tokensView []views.PersonalAccessTokenView
+	for _, token := range tokens {
+		tokensView = append(tokensView, toView(token))
+	}
+	return views.GetPersonalAccessTokensResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Tokens:   tokensView,
+	}
+}
+
+func RevokePersonalAccessToken(
+	actor views.AuthUserView,
+	payload views.RevokePersonalAccessTokenPayload,
+) views.RevokePersonalAccessTokenResponse {
+	var token models.PersonalAccessToken
+	err := db.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", payload.ID, actor.ID).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return views.RevokePersonalAccessTokenResponse{
+				Response: views.Response{
+					Message: fmt.Sprintf(tokenNotFoundMessage, payload.ID),
+					Code:    http.StatusUnprocessableEntity,
+				},
+			}
+		}
+		return views.RevokePersonalAccessTokenResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToRevokeTokenMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	err = db.DB.Model(&token).Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return views.RevokePersonalAccessTokenResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToRevokeTokenMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	return views.RevokePersonalAccessTokenResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyRevokedTokenMessage, token.Name),
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Retrieves the active token and the user it acts on behalf of, recording when it was last used
+func ValidatePersonalAccessToken(signedToken string) (token models.PersonalAccessToken, user models.User, err error) {
+	secret := strings.TrimPrefix(signedToken, authUtils.PersonalAccessTokenPrefix)
+	err = db.DB.Where("token_hash = ? AND revoked_at IS NULL", authUtils.HashOpaqueToken(secret)).First(&token).Error
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && token.ExpiresAt.Before(now) {
+		err = errInvalidToken
+		return
+	}
+
+	err = db.DB.Where("id = ?", token.UserID).First(&user).Error
+	if err != nil {
+		return
+	}
+
+	err = db.DB.Model(&token).Update("last_used_at", now).Error
+	return
+}
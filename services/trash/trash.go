@@ -0,0 +1,452 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	boardService "github.com/EmilyOng/tusk-manager/backend/services/board"
+	stateService "github.com/EmilyOng/tusk-manager/backend/services/state"
+	taskService "github.com/EmilyOng/tusk-manager/backend/services/task"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	trashTypes "github.com/EmilyOng/tusk-manager/backend/types/trash"
+	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	unableToGetTrashMessage         = "Unable to retrieve the trash of the board (%s)."
+	unableToGetTrashedBoardsMessage = "Unable to retrieve the boards in the trash."
+	unableToRestoreItemMessage      = "Unable to restore the %s (%s)."
+	unableToRestoreBoardMessage     = "Unable to restore the board (%s)."
+	unableToPurgeBoardMessage       = "Unable to permanently delete the board (%s)."
+	invalidItemMessage              = "The item type '%s' is not valid."
+	itemNotFoundMessage             = "The %s cannot be found in the trash (%s)."
+	boardNotFoundMessage            = "The board cannot be found in the trash (%s)."
+	restoreNotPermittedMessage      = "You do not have permission to restore the %s."
+
+	successfullyRestoredItemMessage  = "Successfully restored the %s '%s'!"
+	successfullyRestoredBoardMessage = "Successfully restored the board '%s'!"
+	successfullyPurgedBoardMessage   = "Successfully deleted the board '%s' permanently!"
+)
+
+// Items listed in the trash of a board, together with the permission needed to restore them,
+// which is the one needed to delete them
+var items = []struct {
+	item       trashTypes.Item
+	model      func() interface{} // Queries are given a new model, since updates write to it
+	permission permissionTypes.Permission
+}{
+	{trashTypes.Task, func() interface{} { return &models.Task{} }, permissionTypes.DeleteTasks},
+	{trashTypes.Tag, func() interface{} { return &models.Tag{} }, permissionTypes.ManageTags},
+	{trashTypes.State, func() interface{} { return &models.State{} }, permissionTypes.ManageStates},
+}
+
+type trashedRow struct {
+	ID        string
+	Name      string
+	DeletedAt time.Time
+}
+
+// Purges the trash in the background every 'TRASH_PURGE_INTERVAL' (defaults to an hour), removing what has
+// been in the trash for longer than 'TRASH_RETENTION' (defaults to 30 days)
+func Setup() {
+	retention := commonUtils.GetEnvDuration("TRASH_RETENTION", 30*24*time.Hour)
+	interval := commonUtils.GetEnvDuration("TRASH_PURGE_INTERVAL", time.Hour)
+	go func() {
+		for {
+			err := PurgeExpired(time.Now().Add(-retention))
+			if err != nil {
+				log.Println("Unable to purge the trash", err)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Permanently deletes the boards, tasks, tags and states that were moved to the trash before the cutoff
+func PurgeExpired(cutoff time.Time) error {
+	var boardIDs []string
+	err := db.DB.Unscoped().Model(&models.Board{}).Where("deleted_at < ?", cutoff).Pluck("id", &boardIDs).Error
+	if err != nil {
+		return err
+	}
+	for _, boardID := range boardIDs {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			_, err := boardService.PurgeBoardTx(tx, auditService.Origin{}, boardID)
+			return err
+		})
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM task_tags WHERE task_id IN (?) OR tag_id IN (?)",
+			tx.Unscoped().Model(&models.Task{}).Select("id").Where("deleted_at < ?", cutoff),
+			tx.Unscoped().Model(&models.Tag{}).Select("id").Where("deleted_at < ?", cutoff),
+		).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("task_id IN (?)",
+			tx.Unscoped().Model(&models.Task{}).Select("id").Where("deleted_at < ?", cutoff),
+		).Delete(&models.TaskAssignee{}).Error
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			err = tx.Unscoped().Where("deleted_at < ?", cutoff).Delete(item.model()).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Lists the tasks, tags and states in the trash of the board
+func GetBoardTrash(payload views.GetBoardTrashPayload) views.GetBoardTrashResponse {
+	itemsView := []views.TrashItemView{}
+	for _, item := range items {
+		var rows []trashedRow
+		err := db.DB.Unscoped().
+			Model(item.model()).
+			Select("id", "name", "deleted_at").
+			Where("board_id = ? AND deleted_at IS NOT NULL", payload.BoardID).
+			Find(&rows).
+			Error
+		if err != nil {
+			return views.GetBoardTrashResponse{
+				Response: views.Response{
+					Message: fmt.Sprintf(unableToGetTrashMessage, payload.BoardID),
+					Code:    http.StatusInternalServerError,
+				},
+			}
+		}
+		for _, row := range rows {
+			itemsView = append(itemsView, views.TrashItemView{
+				ID:        row.ID,
+				Type:      item.item,
+				Name:      row.Name,
+				DeletedAt: row.DeletedAt,
+			})
+		}
+	}
+	sort.SliceStable(itemsView, func(i, j int) bool {
+		return itemsView[i].DeletedAt.After(itemsView[j].DeletedAt)
+	})
+
+	return views.GetBoardTrashResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Items:    itemsView,
+	}
+}
+
+// Places the restored state back at its previous position, shifting the states of the board after it
+func placeRestoredState(tx *gorm.DB, boardID string, stateID string) error {
+	var state models.State
+	err := tx.Select("current_position").Where("id = ?", stateID).First(&state).Error
+	if err != nil {
+		return err
+	}
+	_, err = stateService.PlaceStateTx(tx, boardID, stateID, state.CurrentPosition)
+	return err
+}
+
+// Positions the task at the end of its state, restoring the state if it is in the trash,
+// or at the end of the first state of the board when the state was purged
+func restoreTaskState(tx *gorm.DB, boardID string, task *models.Task) (err error) {
+	result := tx.Unscoped().
+		Model(&models.State{}).
+		Where("id = ? AND board_id = ? AND deleted_at IS NOT NULL", task.StateID, boardID).
+		Update("deleted_at", nil)
+	err = result.Error
+	if err == nil && result.RowsAffected > 0 {
+		err = placeRestoredState(tx, boardID, task.StateID)
+	}
+	if err != nil {
+		return
+	}
+
+	task.Position, err = taskService.AppendPositionTx(tx, boardID, task.StateID)
+	if !errors.Is(err, taskService.ErrStateNotFound) {
+		return
+	}
+	var state models.State
+	err = tx.Where("board_id = ?", boardID).Order("current_position").First(&state).Error
+	if err != nil {
+		return
+	}
+	task.StateID = state.ID
+	task.Position, err = taskService.AppendPositionTx(tx, boardID, task.StateID)
+	return
+}
+
+// Restores the task, tag or state from the trash of the board, which requires the permission to delete it.
+// Links between tasks and tags are kept in the trash, so they are restored as well. A task is placed at the
+// end of its state, bringing back its state from the trash, or at the end of the first state of the board
+// when its state was purged.
+func RestoreTrashItem(permissions permissionTypes.Set, payload views.RestoreTrashItemPayload) views.RestoreTrashItemResponse {
+	index := -1
+	for i, item := range items {
+		if item.item == payload.Type {
+			index = i
+		}
+	}
+	if index < 0 {
+		return views.RestoreTrashItemResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidItemMessage, payload.Type),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	item := items[index]
+	if !permissions.Has(item.permission) {
+		return views.RestoreTrashItemResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(restoreNotPermittedMessage, item.item),
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+
+	var row trashedRow
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		restored := map[string]interface{}{"deleted_at": nil}
+		if item.item == trashTypes.Task {
+			// The state is locked before the task, as when moving tasks
+			var task models.Task
+			err := tx.Unscoped().
+				Select("state_id").
+				Where("id = ? AND board_id = ? AND deleted_at IS NOT NULL", payload.ID, payload.BoardID).
+				First(&task).
+				Error
+			if err != nil {
+				return err
+			}
+			err = restoreTaskState(tx, payload.BoardID, &task)
+			if err != nil {
+				return err
+			}
+			restored["state_id"] = task.StateID
+			restored["position"] = task.Position
+		}
+
+		err := tx.Unscoped().
+			Model(item.model()).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "name", "deleted_at").
+			Where("id = ? AND board_id = ? AND deleted_at IS NOT NULL", payload.ID, payload.BoardID).
+			Take(&row).
+			Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(item.model()).Where("id = ?", row.ID).Updates(restored).Error
+		if err != nil || item.item != trashTypes.State {
+			return err
+		}
+		return placeRestoredState(tx, payload.BoardID, row.ID)
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.RestoreTrashItemResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(itemNotFoundMessage, item.item, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.RestoreTrashItemResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToRestoreItemMessage, item.item, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.RestoreTrashItemResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyRestoredItemMessage, item.item, row.Name),
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Lists the boards in the trash that the user can restore, which are those that the user held the permission
+// to manage before they were deleted
+func GetTrashedBoards(payload views.GetTrashedBoardsPayload) views.GetTrashedBoardsResponse {
+	var boards []models.Board
+	err := db.DB.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Where("id IN (?) OR id IN (?) OR workspace_id IN (?)",
+			db.DB.Model(&models.Member{}).Select("board_id").Where("user_id = ?", payload.UserID),
+			db.DB.Model(&models.BoardTeam{}).
+				Select("board_teams.board_id").
+				Joins("JOIN team_members ON team_members.team_id = board_teams.team_id").
+				Where("team_members.user_id = ?", payload.UserID),
+			authorizationService.AdministeredWorkspaceIDs(db.DB, payload.UserID),
+		).
+		Order("deleted_at DESC").
+		Find(&boards).
+		Error
+
+	boardsView := []views.TrashedBoardView{}
+	for i := 0; err == nil && i < len(boards); i++ {
+		board := boards[i]
+		var permissions permissionTypes.Set
+		permissions, err = authorizationService.GetTrashedBoardPermissions(payload.UserID, board.ID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The board was restored or purged meanwhile
+			err = nil
+			continue
+		}
+		if err == nil && permissions.Has(permissionTypes.ManageBoard) {
+			boardsView = append(boardsView, views.TrashedBoardView{
+				BoardMinimalView: views.BoardMinimalView{
+					ID:          board.ID,
+					Name:        board.Name,
+					Color:       board.Color,
+					WorkspaceID: board.WorkspaceID,
+				},
+				DeletedAt: board.DeletedAt.Time,
+			})
+		}
+	}
+
+	if err != nil {
+		return views.GetTrashedBoardsResponse{
+			Response: views.Response{
+				Message: unableToGetTrashedBoardsMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+	return views.GetTrashedBoardsResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Boards:   boardsView,
+	}
+}
+
+// Restores the board from the trash, together with the tasks, tags and states that were deleted with it
+func RestoreBoard(origin auditService.Origin, payload views.RestoreBoardPayload) views.RestoreBoardResponse {
+	var board models.Board
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NOT NULL", payload.ID).
+			First(&board).
+			Error
+		if err != nil {
+			return err
+		}
+
+		// Items that were in the trash before the board was deleted stay in the trash
+		for _, item := range items {
+			err = tx.Unscoped().
+				Model(item.model()).
+				Where("board_id = ? AND deleted_at = ?", board.ID, board.DeletedAt).
+				Update("deleted_at", nil).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+		err = tx.Unscoped().Model(&board).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.BoardRestored,
+			TargetType: "board",
+			TargetID:   board.ID,
+			BoardID:    board.ID,
+			Details:    map[string]interface{}{"name": board.Name},
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.RestoreBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.RestoreBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToRestoreBoardMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.RestoreBoardResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyRestoredBoardMessage, board.Name),
+			Code:    http.StatusOK,
+		},
+		Board: views.BoardMinimalView{
+			ID:          board.ID,
+			Name:        board.Name,
+			Color:       board.Color,
+			WorkspaceID: board.WorkspaceID,
+		},
+	}
+}
+
+// Permanently deletes the board from the trash, without waiting for it to be purged
+func PurgeBoard(origin auditService.Origin, payload views.PurgeBoardPayload) views.PurgeBoardResponse {
+	var board models.Board
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NOT NULL", payload.ID).
+			First(&board).
+			Error
+		if err != nil {
+			return err
+		}
+		_, err = boardService.PurgeBoardTx(tx, origin, board.ID)
+		return err
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.PurgeBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(boardNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.PurgeBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToPurgeBoardMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.PurgeBoardResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyPurgedBoardMessage, board.Name),
+			Code:    http.StatusOK,
+		},
+	}
+}
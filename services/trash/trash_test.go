@@ -0,0 +1,205 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	boardService "github.com/EmilyOng/tusk-manager/backend/services/board"
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	trashTypes "github.com/EmilyOng/tusk-manager/backend/types/trash"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+)
+
+// Lists every permission of an owner, except for the one that is left out
+func permissionsWithout(left permissionTypes.Permission) permissionTypes.Set {
+	permissions := permissionTypes.NewSet()
+	for _, permission := range roleTypes.Owner.Permissions() {
+		if permission != left {
+			permissions.Add(permission)
+		}
+	}
+	return permissions
+}
+
+func createTestTask(t *testing.T, boardID string, stateID string, userID string) models.Task {
+	t.Helper()
+	task := models.Task{Name: "Task", BoardID: boardID, StateID: stateID, UserID: userID}
+	if err := db.DB.Create(&task).Error; err != nil {
+		t.Fatalf("unable to create the task: %v", err)
+	}
+	return task
+}
+
+func trash(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := db.DB.Delete(value).Error; err != nil {
+		t.Fatalf("unable to move %T to the trash: %v", value, err)
+	}
+}
+
+func isTrashed(t *testing.T, model interface{}, id string) bool {
+	t.Helper()
+	var trashed int64
+	err := db.DB.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&trashed).Error
+	if err != nil {
+		t.Fatalf("unable to find %T: %v", model, err)
+	}
+	return trashed > 0
+}
+
+func TestRestoringRequiresThePermissionToDelete(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	task := createTestTask(t, board.ID, dbTestUtils.CreateState(t, board.ID).ID, owner.ID)
+	tag := dbTestUtils.CreateTag(t, board.ID)
+	state := dbTestUtils.CreateState(t, board.ID)
+	trash(t, &task)
+	trash(t, &tag)
+	trash(t, &state)
+
+	tests := []struct {
+		item       trashTypes.Item
+		id         string
+		model      interface{}
+		permission permissionTypes.Permission
+	}{
+		{item: trashTypes.Task, id: task.ID, model: &models.Task{}, permission: permissionTypes.DeleteTasks},
+		{item: trashTypes.Tag, id: tag.ID, model: &models.Tag{}, permission: permissionTypes.ManageTags},
+		{item: trashTypes.State, id: state.ID, model: &models.State{}, permission: permissionTypes.ManageStates},
+	}
+
+	for _, test := range tests {
+		payload := views.RestoreTrashItemPayload{BoardID: board.ID, Type: test.item, ID: test.id}
+		refused := RestoreTrashItem(permissionsWithout(test.permission), payload)
+		if refused.Code != http.StatusForbidden || refused.Message != fmt.Sprintf(restoreNotPermittedMessage, test.item) {
+			t.Errorf("%s: RestoreTrashItem() without %s = %d %s, want %d", test.item, test.permission, refused.Code, refused.Message, http.StatusForbidden)
+		}
+		if !isTrashed(t, test.model, test.id) {
+			t.Fatalf("%s: restored without %s", test.item, test.permission)
+		}
+
+		restored := RestoreTrashItem(permissionTypes.NewSet(permissionTypes.ViewBoard, test.permission), payload)
+		if restored.Code != http.StatusOK {
+			t.Errorf("%s: RestoreTrashItem() with %s = %d %s, want %d", test.item, test.permission, restored.Code, restored.Message, http.StatusOK)
+		}
+		if isTrashed(t, test.model, test.id) {
+			t.Errorf("%s: still in the trash after being restored", test.item)
+		}
+	}
+}
+
+func TestRestoreOnlyItemsInTheTrashOfTheBoard(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	otherBoard, _ := dbTestUtils.CreateBoard(t, owner)
+	trashedTag := dbTestUtils.CreateTag(t, board.ID)
+	trash(t, &trashedTag)
+	tag := dbTestUtils.CreateTag(t, board.ID)
+	permissions := permissionTypes.NewSet(roleTypes.Owner.Permissions()...)
+
+	tests := []struct {
+		name    string
+		payload views.RestoreTrashItemPayload
+		message string
+	}{
+		{
+			name:    "item of another board",
+			payload: views.RestoreTrashItemPayload{BoardID: otherBoard.ID, Type: trashTypes.Tag, ID: trashedTag.ID},
+			message: fmt.Sprintf(itemNotFoundMessage, trashTypes.Tag, trashedTag.ID),
+		},
+		{
+			name:    "item outside of the trash",
+			payload: views.RestoreTrashItemPayload{BoardID: board.ID, Type: trashTypes.Tag, ID: tag.ID},
+			message: fmt.Sprintf(itemNotFoundMessage, trashTypes.Tag, tag.ID),
+		},
+		{
+			name:    "unknown item type",
+			payload: views.RestoreTrashItemPayload{BoardID: board.ID, Type: "board", ID: board.ID},
+			message: fmt.Sprintf(invalidItemMessage, "board"),
+		},
+	}
+
+	for _, test := range tests {
+		response := RestoreTrashItem(permissions, test.payload)
+		if response.Code != http.StatusUnprocessableEntity || response.Message != test.message {
+			t.Errorf("%s: RestoreTrashItem() = %d %s, want %d %s", test.name, response.Code, response.Message, http.StatusUnprocessableEntity, test.message)
+		}
+	}
+	if !isTrashed(t, &models.Tag{}, trashedTag.ID) {
+		t.Errorf("the tag was restored through another board")
+	}
+}
+
+func TestTrashedBoardsAreListedForTheirManagers(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	editor := dbTestUtils.CreateUser(t)
+	dbTestUtils.AddMember(t, board.ID, editor, roleTypes.Editor)
+	origin := auditService.Origin{ActorID: owner.ID}
+	if _, err := boardService.DeleteBoardTx(db.DB, origin, board.ID); err != nil {
+		t.Fatalf("unable to move the board to the trash: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		listed bool
+	}{
+		{name: "owner", userID: owner.ID, listed: true},
+		{name: "editor", userID: editor.ID, listed: false},
+		{name: "outsider", userID: dbTestUtils.CreateUser(t).ID, listed: false},
+	}
+
+	for _, test := range tests {
+		response := GetTrashedBoards(views.GetTrashedBoardsPayload{UserID: test.userID})
+		if response.Code != http.StatusOK {
+			t.Fatalf("%s: GetTrashedBoards() = %d %s, want %d", test.name, response.Code, response.Message, http.StatusOK)
+		}
+		listed := false
+		for _, trashedBoard := range response.Boards {
+			listed = listed || trashedBoard.ID == board.ID
+		}
+		if listed != test.listed {
+			t.Errorf("%s: GetTrashedBoards() lists the board = %v, want %v", test.name, listed, test.listed)
+		}
+	}
+}
+
+func TestRestoreBoardKeepsEarlierTrash(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	state := dbTestUtils.CreateState(t, board.ID)
+	task := createTestTask(t, board.ID, state.ID, owner.ID)
+	trashedTask := createTestTask(t, board.ID, state.ID, owner.ID)
+	trash(t, &trashedTask)
+	origin := auditService.Origin{ActorID: owner.ID}
+	if _, err := boardService.DeleteBoardTx(db.DB, origin, board.ID); err != nil {
+		t.Fatalf("unable to move the board to the trash: %v", err)
+	}
+
+	restored := RestoreBoard(origin, views.RestoreBoardPayload{ID: board.ID})
+	if restored.Code != http.StatusOK {
+		t.Fatalf("RestoreBoard() = %d %s, want %d", restored.Code, restored.Message, http.StatusOK)
+	}
+	if isTrashed(t, &models.Board{}, board.ID) || isTrashed(t, &models.State{}, state.ID) || isTrashed(t, &models.Task{}, task.ID) {
+		t.Errorf("the board is restored without everything that was deleted with it")
+	}
+	if !isTrashed(t, &models.Task{}, trashedTask.ID) {
+		t.Errorf("the task that was in the trash before the board was deleted is restored")
+	}
+
+	restored = RestoreBoard(origin, views.RestoreBoardPayload{ID: board.ID})
+	if restored.Code != http.StatusUnprocessableEntity || restored.Message != fmt.Sprintf(boardNotFoundMessage, board.ID) {
+		t.Errorf("restoring a board outside of the trash = %d %s, want %d", restored.Code, restored.Message, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	sessionService "github.com/EmilyOng/tusk-manager/backend/services/session"
+	authUtils "github.com/EmilyOng/tusk-manager/backend/utils/auth"
+	totpUtils "github.com/EmilyOng/tusk-manager/backend/utils/totp"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	totpIssuer        = "Tusk"
+	recoveryCodeCount = 10
+
+	twoFactorAlreadyEnabledMessage = "Two-factor authentication is already enabled."
+	twoFactorNotEnabledMessage     = "Two-factor authentication is not enabled."
+	twoFactorNotEnrolledMessage    = "Please enroll in two-factor authentication first."
+	invalidTwoFactorCodeMessage    = "The code is invalid, please try again."
+	invalidPasswordMessage         = "The password is incorrect, please try again."
+	unableToEnrollTwoFactorMessage = "Unable to enroll in two-factor authentication."
+	unableToUpdateTwoFactorMessage = "Unable to update two-factor authentication."
+	unableToGenerateTokenMessage   = "Unable to generate authentication token."
+
+	successfullyEnrolledTwoFactorMessage  = "Scan the QR code with your authenticator app, then confirm with a code."
+	successfullyEnabledTwoFactorMessage   = "Two-factor authentication is enabled! Store the recovery codes somewhere safe."
+	successfullyDisabledTwoFactorMessage  = "Two-factor authentication is disabled."
+	successfullyLoginWithTwoFactorMessage = "Welcome back %s!"
+)
+
+var errInvalidCode = errors.New("invalid two-factor code")
+
+func generateRecoveryCodes(tx *gorm.DB, userID string) (codes []string, err error) {
+	err = tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+	if err != nil {
+		return
+	}
+
+	var recoveryCodes []models.RecoveryCode
+	for i := 0; i < recoveryCodeCount; i++ {
+		bytes := make([]byte, 5)
+		_, err = rand.Read(bytes)
+		if err != nil {
+			return
+		}
+		code := hex.EncodeToString(bytes)
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		recoveryCodes = append(recoveryCodes, models.RecoveryCode{
+			CodeHash: authUtils.HashOpaqueToken(code),
+			UserID:   userID,
+		})
+	}
+	err = tx.Create(&recoveryCodes).Error
+	return
+}
+
+// Verifies a TOTP code (which cannot be replayed) or consumes a recovery code
+func verifyCode(tx *gorm.DB, userID string, code string) error {
+	var user models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return errInvalidCode
+	}
+
+	step, ok := totpUtils.Validate(user.TOTPSecret, code, time.Now())
+	if ok && step > user.TOTPLastStep {
+		return tx.Model(&user).Update("totp_last_step", step).Error
+	}
+
+	var recoveryCode models.RecoveryCode
+	err = tx.Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, authUtils.HashOpaqueToken(strings.TrimSpace(code))).
+		First(&recoveryCode).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errInvalidCode
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Model(&recoveryCode).Update("used_at", time.Now()).Error
+}
+
+// Generates a pending secret, which is only enabled once confirmed with a code
+func Enroll(actor views.AuthUserView) views.EnrollTwoFactorResponse {
+	var user models.User
+	err := db.DB.Where("id = ?", actor.ID).First(&user).Error
+	if err == nil && user.TOTPEnabled {
+		return views.EnrollTwoFactorResponse{
+			Response: views.Response{
+				Message: twoFactorAlreadyEnabledMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var secret string
+	if err == nil {
+		secret, err = totpUtils.GenerateSecret()
+	}
+	if err == nil {
+		err = db.DB.Model(&user).Update("totp_secret", secret).Error
+	}
+	if err != nil {
+		return views.EnrollTwoFactorResponse{
+			Response: views.Response{
+				Message: unableToEnrollTwoFactorMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.EnrollTwoFactorResponse{
+		Response: views.Response{
+			Message: successfullyEnrolledTwoFactorMessage,
+			Code:    http.StatusOK,
+		},
+		Enrollment: views.TwoFactorEnrollmentView{
+			Secret: secret,
+			URI:    totpUtils.URI(totpIssuer, user.Email, secret),
+		},
+	}
+}
+
+// Enables two-factor authentication with the first code, returning the recovery codes
+func Confirm(actor views.AuthUserView, payload views.ConfirmTwoFactorPayload) views.ConfirmTwoFactorResponse {
+	var recoveryCodes []string
+	var user models.User
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", actor.ID).First(&user).Error
+		if err != nil {
+			return err
+		}
+		if user.TOTPEnabled || len(user.TOTPSecret) == 0 {
+			return nil
+		}
+
+		step, ok := totpUtils.Validate(user.TOTPSecret, payload.Code, time.Now())
+		if !ok {
+			return errInvalidCode
+		}
+
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+		err = tx.Model(&user).Select("totp_enabled", "totp_last_step").Updates(&user).Error
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = generateRecoveryCodes(tx, user.ID)
+		return err
+	})
+
+	if err == nil && recoveryCodes == nil {
+		message := twoFactorNotEnrolledMessage
+		if user.TOTPEnabled {
+			message = twoFactorAlreadyEnabledMessage
+		}
+		return views.ConfirmTwoFactorResponse{
+			Response: views.Response{
+				Message: message,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errInvalidCode) {
+		return views.ConfirmTwoFactorResponse{
+			Response: views.Response{
+				Message: invalidTwoFactorCodeMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.ConfirmTwoFactorResponse{
+			Response: views.Response{
+				Message: unableToUpdateTwoFactorMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.ConfirmTwoFactorResponse{
+		Response: views.Response{
+			Message: successfullyEnabledTwoFactorMessage,
+			Code:    http.StatusOK,
+		},
+		RecoveryCodes: recoveryCodes,
+	}
+}
+
+// Disables two-factor authentication, which requires both the password and a code
+func Disable(actor views.AuthUserView, payload views.DisableTwoFactorPayload) views.DisableTwoFactorResponse {
+	var user models.User
+	err := db.DB.Where("id = ?", actor.ID).First(&user).Error
+	if err != nil {
+		return views.DisableTwoFactorResponse{
+			Response: views.Response{
+				Message: unableToUpdateTwoFactorMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	if !user.TOTPEnabled {
+		return views.DisableTwoFactorResponse{
+			Response: views.Response{
+				Message: twoFactorNotEnabledMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	if authUtils.ComparePassword(user.Password, payload.Password) != nil {
+		return views.DisableTwoFactorResponse{
+			Response: views.Response{
+				Message: invalidPasswordMessage,
+				Code:    http.StatusUnauthorized,
+			},
+		}
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		err := verifyCode(tx, user.ID, payload.Code)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+
+	if errors.Is(err, errInvalidCode) {
+		return views.DisableTwoFactorResponse{
+			Response: views.Response{
+				Message: invalidTwoFactorCodeMessage,
+				Code:    http.StatusUnauthorized,
+			},
+		}
+	}
+	if err != nil {
+		return views.DisableTwoFactorResponse{
+			Response: views.Response{
+				Message: unableToUpdateTwoFactorMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DisableTwoFactorResponse{
+		Response: views.Response{
+			Message: successfullyDisabledTwoFactorMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Completes the second step of the login for the user that passed the password step
+func CompleteLogin(userID string, payload views.LoginTwoFactorPayload) views.LoginResponse {
+	var user models.User
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := verifyCode(tx, userID, payload.Code)
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", userID).First(&user).Error
+	})
+
+	if errors.Is(err, errInvalidCode) {
+		return views.LoginResponse{
+			Response: views.Response{
+				Message: invalidTwoFactorCodeMessage,
+				Code:    http.StatusUnauthorized,
+			},
+		}
+	}
+	if err != nil {
+		return views.LoginResponse{
+			Response: views.Response{
+				Message: unableToUpdateTwoFactorMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	signedToken, refreshToken, err := sessionService.CreateSession(user)
+	if err != nil {
+		return views.LoginResponse{
+			Response: views.Response{
+				Message: unableToGenerateTokenMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.LoginResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyLoginWithTwoFactorMessage, user.Name),
+			Code:    http.StatusOK,
+		},
+		User: views.AuthUserView{
+			ID:           user.ID,
+			Name:         user.Name,
+			Email:        user.Email,
+			Token:        signedToken,
+			RefreshToken: refreshToken,
+		},
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/EmilyOng/tusk-manager/backend/db"
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
 	"github.com/EmilyOng/tusk-manager/backend/views"
 	"gorm.io/gorm"
 )
@@ -26,22 +27,76 @@ func FindUser(email string) (models.User, error) {
 	return user, err
 }
 
+func FindUserByID(id string) (models.User, error) {
+	var user models.User
+	err := db.DB.Model(&models.User{}).Where("id = ?", id).First(&user).Error
+
+	return user, err
+}
+
+// Lists the boards that the user can access: the boards that the user is a member of, directly or
+// through a team, and every board in the shared workspaces that the user administers
 func GetUserBoards(payload views.GetUserBoardsPayload) views.GetUserBoardsResponse {
-	var boardsView []views.BoardMinimalView
-	var boardIds []string
+	boardsView := []views.BoardMinimalView{}
+	var workspacesView []views.WorkspaceBoardsView
 
 	err := db.DB.Transaction(func(tx *gorm.DB) error {
-		err := tx.Model(&models.Member{}).
-			Where("user_id = ?", payload.UserID).
+		memberBoardIDs := tx.Model(&models.Member{}).
 			Select("board_id").
-			Find(&boardIds).
-			Error
+			Where("user_id = ?", payload.UserID)
+		teamBoardIDs := tx.Model(&models.BoardTeam{}).
+			Select("board_teams.board_id").
+			Joins("JOIN team_members ON team_members.team_id = board_teams.team_id").
+			Where("team_members.user_id = ?", payload.UserID)
+		adminWorkspaceIDs := authorizationService.AdministeredWorkspaceIDs(tx, payload.UserID)
+
+		query := tx.Model(&models.Board{}).
+			Where("id IN (?) OR id IN (?) OR workspace_id IN (?)", memberBoardIDs, teamBoardIDs, adminWorkspaceIDs)
+		if len(payload.WorkspaceID) > 0 {
+			query = query.Where("workspace_id = ?", payload.WorkspaceID)
+		}
+		err := query.Order("name").Find(&boardsView).Error
+		if err != nil || !payload.GroupByWorkspace {
+			return err
+		}
+
+		// Groups the boards by workspace, including the workspaces of the user that do not have any boards yet
+		var workspaceIDs []string
+		for _, board := range boardsView {
+			workspaceIDs = append(workspaceIDs, board.WorkspaceID)
+		}
+		userWorkspaceIDs := tx.Model(&models.WorkspaceMember{}).
+			Select("workspace_id").
+			Where("user_id = ?", payload.UserID)
+
+		var workspaces []models.Workspace
+		query = tx.Model(&models.Workspace{}).Where("id IN ? OR id IN (?)", workspaceIDs, userWorkspaceIDs)
+		if len(payload.WorkspaceID) > 0 {
+			query = query.Where("id = ?", payload.WorkspaceID)
+		}
+		err = query.Order("user_id IS NULL, name").Find(&workspaces).Error
 		if err != nil {
 			return err
 		}
 
-		err = tx.Model(&models.Board{}).Where("id IN ?", boardIds).Find(&boardsView).Error
-		return err
+		workspacesView = []views.WorkspaceBoardsView{}
+		for _, workspace := range workspaces {
+			workspaceView := views.WorkspaceBoardsView{
+				WorkspaceMinimalView: views.WorkspaceMinimalView{
+					ID:       workspace.ID,
+					Name:     workspace.Name,
+					Personal: workspace.UserID != nil,
+				},
+				Boards: []views.BoardMinimalView{},
+			}
+			for _, board := range boardsView {
+				if board.WorkspaceID == workspace.ID {
+					workspaceView.Boards = append(workspaceView.Boards, board)
+				}
+			}
+			workspacesView = append(workspacesView, workspaceView)
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -53,7 +108,8 @@ func GetUserBoards(payload views.GetUserBoardsPayload) views.GetUserBoardsRespon
 		}
 	}
 	return views.GetUserBoardsResponse{
-		Response: views.Response{Code: http.StatusOK},
-		Boards:   boardsView,
+		Response:   views.Response{Code: http.StatusOK},
+		Boards:     boardsView,
+		Workspaces: workspacesView,
 	}
 }
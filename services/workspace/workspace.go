@@ -0,0 +1,783 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	taskService "github.com/EmilyOng/tusk-manager/backend/services/task"
+	userService "github.com/EmilyOng/tusk-manager/backend/services/user"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	unableToGetWorkspacesMessage         = "Unable to retrieve the workspaces."
+	unableToCreateWorkspaceMessage       = "Unable to create the workspace '%s'."
+	unableToUpdateWorkspaceMessage       = "Unable to update the workspace (%s)."
+	unableToDeleteWorkspaceMessage       = "Unable to delete the workspace (%s)."
+	unableToGetWorkspaceMembersMessage   = "Unable to retrieve the members of the workspace (%s)."
+	unableToCreateWorkspaceMemberMessage = "Unable to add '%s' to the workspace."
+	unableToUpdateWorkspaceMemberMessage = "Unable to update the workspace member (%s)."
+	unableToDeleteWorkspaceMemberMessage = "Unable to remove the workspace member (%s)."
+	unableToLeaveWorkspaceMessage        = "Unable to leave the workspace (%s)."
+	unableToMoveBoardMessage             = "Unable to move the board (%s)."
+	emptyWorkspaceNameMessage            = "The name of the workspace cannot be empty."
+	workspaceNotFoundMessage             = "The workspace cannot be found (%s)."
+	workspaceMemberNotFoundMessage       = "The workspace member cannot be found (%s)."
+	workspaceMemberExistsMessage         = "'%s' is already a member of the workspace."
+	userNotFoundMessage                  = "There is no user with the email '%s'."
+	invalidWorkspaceRoleMessage          = "The role '%s' is not valid."
+	personalWorkspaceMessage             = "Personal workspaces cannot be shared, left or deleted."
+	workspaceNotEmptyMessage             = "The workspace still has boards, including in the trash, please move or delete them first."
+	lastAdminMessage                     = "The workspace must have at least one admin, please promote another member first."
+	notWorkspaceMemberMessage            = "You can only move boards into workspaces that you are a member of."
+
+	successfullyCreatedWorkspaceMessage       = "Successfully created the workspace '%s'!"
+	successfullyUpdatedWorkspaceMessage       = "Successfully updated the workspace '%s'!"
+	successfullyDeletedWorkspaceMessage       = "Successfully deleted the workspace '%s'!"
+	successfullyCreatedWorkspaceMemberMessage = "'%s' has been added to the workspace!"
+	successfullyUpdatedWorkspaceMemberMessage = "Successfully updated the workspace member '%s'!"
+	successfullyDeletedWorkspaceMemberMessage = "Successfully removed the workspace member!"
+	successfullyLeftWorkspaceMessage          = "Successfully left the workspace!"
+	successfullyMovedBoardMessage             = "Successfully moved the board '%s'!"
+)
+
+var (
+	errLastAdmin          = errors.New("the workspace must have at least one admin")
+	errPersonalWorkspace  = errors.New("the workspace is personal")
+	errWorkspaceNotEmpty  = errors.New("the workspace has boards")
+	errNotWorkspaceMember = errors.New("the actor is not a member of the workspace")
+)
+
+func toView(workspace models.Workspace) views.WorkspaceMinimalView {
+	return views.WorkspaceMinimalView{
+		ID:       workspace.ID,
+		Name:     workspace.Name,
+		Personal: workspace.UserID != nil,
+	}
+}
+
+// Retrieves the personal workspace of the user, creating it if the user does not have one yet
+func GetPersonalWorkspaceTx(tx *gorm.DB, userID string) (workspace models.Workspace, err error) {
+	err = tx.Where("user_id = ?", userID).First(&workspace).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		workspace = models.NewPersonalWorkspace(userID)
+		err = tx.Create(&workspace).Error
+	}
+	return
+}
+
+// Ensures that another admin remains in the workspace once the member is no longer an admin.
+// The admins are locked, so that concurrent changes cannot remove every admin.
+func ensureOtherAdmin(tx *gorm.DB, workspaceID string, memberID string) error {
+	var admins []models.WorkspaceMember
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("workspace_id = ? AND role = ?", workspaceID, workspaceTypes.Admin).
+		Find(&admins).
+		Error
+	if err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		if admin.ID != memberID {
+			return nil
+		}
+	}
+	return errLastAdmin
+}
+
+// Lists the workspaces that the user is a member of, starting with the personal workspace
+func GetUserWorkspaces(payload views.GetUserWorkspacesPayload) views.GetUserWorkspacesResponse {
+	var workspaceMembers []models.WorkspaceMember
+	var workspaces []models.Workspace
+	err := db.DB.Where("user_id = ?", payload.UserID).Find(&workspaceMembers).Error
+	if err == nil {
+		var workspaceIDs []string
+		for _, workspaceMember := range workspaceMembers {
+			workspaceIDs = append(workspaceIDs, workspaceMember.WorkspaceID)
+		}
+		err = db.DB.Where("id IN ?", workspaceIDs).Order("user_id IS NULL, name").Find(&workspaces).Error
+	}
+	if err != nil {
+		return views.GetUserWorkspacesResponse{
+			Response: views.Response{
+				Message: unableToGetWorkspacesMessage,
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	roles := map[string]workspaceTypes.Role{}
+	for _, workspaceMember := range workspaceMembers {
+		roles[workspaceMember.WorkspaceID] = workspaceMember.Role
+	}
+	workspacesView := []views.UserWorkspaceView{}
+	for _, workspace := range workspaces {
+		workspacesView = append(workspacesView, views.UserWorkspaceView{
+			WorkspaceMinimalView: toView(workspace),
+			Role:                 roles[workspace.ID],
+		})
+	}
+	return views.GetUserWorkspacesResponse{
+		Response:   views.Response{Code: http.StatusOK},
+		Workspaces: workspacesView,
+	}
+}
+
+// Creates a shared workspace administered by the actor
+func CreateWorkspace(actor views.AuthUserView, payload views.CreateWorkspacePayload) views.CreateWorkspaceResponse {
+	name := strings.TrimSpace(payload.Name)
+	if len(name) == 0 {
+		return views.CreateWorkspaceResponse{
+			Response: views.Response{
+				Message: emptyWorkspaceNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	workspace := models.Workspace{
+		Name: name,
+		Members: []*models.WorkspaceMember{{
+			Role:   workspaceTypes.Admin,
+			UserID: actor.ID,
+		}},
+	}
+	err := db.DB.Create(&workspace).Error
+	if err != nil {
+		return views.CreateWorkspaceResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateWorkspaceMessage, name),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.CreateWorkspaceResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedWorkspaceMessage, workspace.Name),
+			Code:    http.StatusOK,
+		},
+		Workspace: toView(workspace),
+	}
+}
+
+func UpdateWorkspace(payload views.UpdateWorkspacePayload) views.UpdateWorkspaceResponse {
+	name := strings.TrimSpace(payload.Name)
+	if len(name) == 0 {
+		return views.UpdateWorkspaceResponse{
+			Response: views.Response{
+				Message: emptyWorkspaceNameMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var workspace models.Workspace
+	err := db.DB.Where("id = ?", payload.ID).First(&workspace).Error
+	if err == nil {
+		workspace.Name = name
+		err = db.DB.Model(&workspace).Update("name", workspace.Name).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UpdateWorkspaceResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(workspaceNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.UpdateWorkspaceResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToUpdateWorkspaceMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UpdateWorkspaceResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyUpdatedWorkspaceMessage, workspace.Name),
+			Code:    http.StatusOK,
+		},
+		Workspace: toView(workspace),
+	}
+}
+
+// Deletes a shared workspace, once its boards have been moved or deleted
+func DeleteWorkspace(origin auditService.Origin, payload views.DeleteWorkspacePayload) views.DeleteWorkspaceResponse {
+	var workspace models.Workspace
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payload.ID).First(&workspace).Error
+		if err != nil {
+			return err
+		}
+		if workspace.UserID != nil {
+			return errPersonalWorkspace
+		}
+
+		var boards int64
+		err = tx.Unscoped().Model(&models.Board{}).Where("workspace_id = ?", workspace.ID).Count(&boards).Error
+		if err != nil {
+			return err
+		}
+		if boards > 0 {
+			return errWorkspaceNotEmpty
+		}
+
+		teamIDs := tx.Model(&models.Team{}).Select("id").Where("workspace_id = ?", workspace.ID)
+		err = tx.Where("team_id IN (?)", teamIDs).Delete(&models.TeamMember{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("workspace_id = ?", workspace.ID).Delete(&models.Team{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("workspace_id = ?", workspace.ID).Delete(&models.CustomRole{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("workspace_id = ?", workspace.ID).Delete(&models.WorkspaceMember{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&workspace).Error
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.WorkspaceDeleted,
+			TargetType: "workspace",
+			TargetID:   workspace.ID,
+			Details:    map[string]interface{}{"name": workspace.Name},
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.DeleteWorkspaceResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(workspaceNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errPersonalWorkspace) {
+		return views.DeleteWorkspaceResponse{
+			Response: views.Response{
+				Message: personalWorkspaceMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errWorkspaceNotEmpty) {
+		return views.DeleteWorkspaceResponse{
+			Response: views.Response{
+				Message: workspaceNotEmptyMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.DeleteWorkspaceResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToDeleteWorkspaceMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeleteWorkspaceResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyDeletedWorkspaceMessage, workspace.Name),
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+func GetWorkspaceMembers(payload views.GetWorkspaceMembersPayload) views.GetWorkspaceMembersResponse {
+	var workspaceMembers []models.WorkspaceMember
+	err := db.DB.Where("workspace_id = ?", payload.WorkspaceID).Preload("User").Find(&workspaceMembers).Error
+	if err != nil {
+		return views.GetWorkspaceMembersResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToGetWorkspaceMembersMessage, payload.WorkspaceID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	membersView := []views.WorkspaceMemberView{}
+	for _, workspaceMember := range workspaceMembers {
+		membersView = append(membersView, toMemberView(workspaceMember, *workspaceMember.User))
+	}
+	return views.GetWorkspaceMembersResponse{
+		Response: views.Response{Code: http.StatusOK},
+		Members:  membersView,
+	}
+}
+
+func toMemberView(workspaceMember models.WorkspaceMember, user models.User) views.WorkspaceMemberView {
+	return views.WorkspaceMemberView{
+		ID:   workspaceMember.ID,
+		Role: workspaceMember.Role,
+		User: views.UserMinimalView{
+			ID:    user.ID,
+			Name:  user.Name,
+			Email: user.Email,
+		},
+	}
+}
+
+// Adds a registered user to a shared workspace
+func CreateWorkspaceMember(origin auditService.Origin, payload views.CreateWorkspaceMemberPayload) views.CreateWorkspaceMemberResponse {
+	if !payload.Role.IsValid() {
+		return views.CreateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidWorkspaceRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	user, err := userService.FindUser(payload.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.CreateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(userNotFoundMessage, payload.Email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	workspaceMember := models.WorkspaceMember{
+		Role:        payload.Role,
+		UserID:      user.ID,
+		WorkspaceID: payload.WorkspaceID,
+	}
+	exists := false
+	if err == nil {
+		err = db.DB.Transaction(func(tx *gorm.DB) error {
+			var workspace models.Workspace
+			err := tx.Where("id = ?", payload.WorkspaceID).First(&workspace).Error
+			if err != nil {
+				return err
+			}
+			if workspace.UserID != nil {
+				return errPersonalWorkspace
+			}
+
+			var existingMembers int64
+			err = tx.Model(&models.WorkspaceMember{}).
+				Where("user_id = ? AND workspace_id = ?", user.ID, workspace.ID).
+				Count(&existingMembers).
+				Error
+			if err != nil || existingMembers > 0 {
+				exists = existingMembers > 0
+				return err
+			}
+
+			err = tx.Create(&workspaceMember).Error
+			if err != nil {
+				return err
+			}
+			return auditService.RecordTx(tx, origin, auditService.Event{
+				Action:     auditTypes.WorkspaceMemberAdded,
+				TargetType: "workspace_member",
+				TargetID:   workspaceMember.ID,
+				Details: map[string]interface{}{
+					"workspaceId": workspaceMember.WorkspaceID,
+					"userId":      workspaceMember.UserID,
+					"role":        workspaceMember.Role,
+				},
+			})
+		})
+	}
+
+	if exists {
+		return views.CreateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(workspaceMemberExistsMessage, payload.Email),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errPersonalWorkspace) {
+		return views.CreateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: personalWorkspaceMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.CreateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToCreateWorkspaceMemberMessage, payload.Email),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.CreateWorkspaceMemberResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyCreatedWorkspaceMemberMessage, user.Email),
+			Code:    http.StatusOK,
+		},
+		Member: toMemberView(workspaceMember, user),
+	}
+}
+
+func UpdateWorkspaceMember(origin auditService.Origin, payload views.UpdateWorkspaceMemberPayload) views.UpdateWorkspaceMemberResponse {
+	if !payload.Role.IsValid() {
+		return views.UpdateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(invalidWorkspaceRoleMessage, payload.Role),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+
+	var workspaceMember models.WorkspaceMember
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND workspace_id = ?", payload.ID, payload.WorkspaceID).
+			Preload("User").
+			First(&workspaceMember).
+			Error
+		if err != nil {
+			return err
+		}
+
+		previousRole := workspaceMember.Role
+		if previousRole == payload.Role {
+			return nil
+		}
+		if previousRole == workspaceTypes.Admin {
+			err = ensureOtherAdmin(tx, workspaceMember.WorkspaceID, workspaceMember.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		workspaceMember.Role = payload.Role
+		err = tx.Model(&workspaceMember).Update("role", workspaceMember.Role).Error
+		if err != nil {
+			return err
+		}
+		if previousRole == workspaceTypes.Admin {
+			err = unassignFromWorkspaceBoards(tx, workspaceMember.WorkspaceID)
+			if err != nil {
+				return err
+			}
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.WorkspaceRoleChanged,
+			TargetType: "workspace_member",
+			TargetID:   workspaceMember.ID,
+			Details: map[string]interface{}{
+				"workspaceId": workspaceMember.WorkspaceID,
+				"userId":      workspaceMember.UserID,
+				"from":        previousRole,
+				"to":          workspaceMember.Role,
+			},
+		})
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.UpdateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(workspaceMemberNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errLastAdmin) {
+		return views.UpdateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: lastAdminMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.UpdateWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToUpdateWorkspaceMemberMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.UpdateWorkspaceMemberResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyUpdatedWorkspaceMemberMessage, workspaceMember.User.Email),
+			Code:    http.StatusOK,
+		},
+		Member: toMemberView(workspaceMember, *workspaceMember.User),
+	}
+}
+
+// Unassigns the users who have lost their access to the boards of the workspace, including the boards in the trash
+func unassignFromWorkspaceBoards(tx *gorm.DB, workspaceID string) error {
+	var boardIDs []string
+	err := tx.Unscoped().Model(&models.Board{}).Where("workspace_id = ?", workspaceID).Pluck("id", &boardIDs).Error
+	if err != nil {
+		return err
+	}
+	return taskService.UnassignInaccessibleTx(tx, boardIDs)
+}
+
+// Removes the member from the workspace, keeping at least one admin
+func removeMember(tx *gorm.DB, origin auditService.Origin, workspaceMember models.WorkspaceMember, action auditTypes.Action) error {
+	var workspace models.Workspace
+	err := tx.Where("id = ?", workspaceMember.WorkspaceID).First(&workspace).Error
+	if err != nil {
+		return err
+	}
+	if workspace.UserID != nil {
+		return errPersonalWorkspace
+	}
+
+	if workspaceMember.Role == workspaceTypes.Admin {
+		err = ensureOtherAdmin(tx, workspaceMember.WorkspaceID, workspaceMember.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Delete(&workspaceMember).Error
+	if err != nil {
+		return err
+	}
+	// Teams only hold members of their workspace
+	err = tx.Where("user_id = ? AND team_id IN (?)",
+		workspaceMember.UserID,
+		tx.Model(&models.Team{}).Select("id").Where("workspace_id = ?", workspaceMember.WorkspaceID),
+	).Delete(&models.TeamMember{}).Error
+	if err != nil {
+		return err
+	}
+	err = unassignFromWorkspaceBoards(tx, workspaceMember.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	return auditService.RecordTx(tx, origin, auditService.Event{
+		Action:     action,
+		TargetType: "workspace_member",
+		TargetID:   workspaceMember.ID,
+		Details: map[string]interface{}{
+			"workspaceId": workspaceMember.WorkspaceID,
+			"userId":      workspaceMember.UserID,
+			"role":        workspaceMember.Role,
+		},
+	})
+}
+
+func DeleteWorkspaceMember(origin auditService.Origin, payload views.DeleteWorkspaceMemberPayload) views.DeleteWorkspaceMemberResponse {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var workspaceMember models.WorkspaceMember
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND workspace_id = ?", payload.ID, payload.WorkspaceID).
+			First(&workspaceMember).
+			Error
+		if err != nil {
+			return err
+		}
+		return removeMember(tx, origin, workspaceMember, auditTypes.WorkspaceMemberRemoved)
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.DeleteWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(workspaceMemberNotFoundMessage, payload.ID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errPersonalWorkspace) {
+		return views.DeleteWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: personalWorkspaceMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errLastAdmin) {
+		return views.DeleteWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: lastAdminMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.DeleteWorkspaceMemberResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToDeleteWorkspaceMemberMessage, payload.ID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.DeleteWorkspaceMemberResponse{
+		Response: views.Response{
+			Message: successfullyDeletedWorkspaceMemberMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Removes the actor from the workspace. The board memberships of the actor are kept.
+func LeaveWorkspace(origin auditService.Origin, payload views.LeaveWorkspacePayload) views.LeaveWorkspaceResponse {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var workspaceMember models.WorkspaceMember
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND workspace_id = ?", origin.ActorID, payload.WorkspaceID).
+			First(&workspaceMember).
+			Error
+		if err != nil {
+			return err
+		}
+		return removeMember(tx, origin, workspaceMember, auditTypes.WorkspaceMemberLeft)
+	})
+
+	if errors.Is(err, errPersonalWorkspace) {
+		return views.LeaveWorkspaceResponse{
+			Response: views.Response{
+				Message: personalWorkspaceMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if errors.Is(err, errLastAdmin) {
+		return views.LeaveWorkspaceResponse{
+			Response: views.Response{
+				Message: lastAdminMessage,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.LeaveWorkspaceResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToLeaveWorkspaceMessage, payload.WorkspaceID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.LeaveWorkspaceResponse{
+		Response: views.Response{
+			Message: successfullyLeftWorkspaceMessage,
+			Code:    http.StatusOK,
+		},
+	}
+}
+
+// Moves the board into another workspace that the actor is a member of
+func MoveBoard(origin auditService.Origin, payload views.MoveBoardPayload) views.MoveBoardResponse {
+	var board models.Board
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		var workspaceMembers int64
+		err := tx.Model(&models.WorkspaceMember{}).
+			Where("user_id = ? AND workspace_id = ?", origin.ActorID, payload.WorkspaceID).
+			Count(&workspaceMembers).
+			Error
+		if err != nil {
+			return err
+		}
+		if workspaceMembers == 0 {
+			return errNotWorkspaceMember
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payload.BoardID).First(&board).Error
+		if err != nil {
+			return err
+		}
+		previousWorkspaceID := board.WorkspaceID
+		if previousWorkspaceID == payload.WorkspaceID {
+			return nil
+		}
+
+		board.WorkspaceID = payload.WorkspaceID
+		err = tx.Model(&board).Update("workspace_id", board.WorkspaceID).Error
+		if err != nil {
+			return err
+		}
+		// Teams and custom roles of the previous workspace lose their access
+		err = tx.Where("board_id = ?", board.ID).Delete(&models.BoardTeam{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Member{}).
+			Where("board_id = ? AND custom_role_id IN (?)",
+				board.ID,
+				tx.Model(&models.CustomRole{}).Select("id").Where("workspace_id = ?", previousWorkspaceID),
+			).
+			Update("custom_role_id", nil).
+			Error
+		if err != nil {
+			return err
+		}
+		// So do the admins of the previous workspace
+		err = taskService.UnassignInaccessibleTx(tx, []string{board.ID})
+		if err != nil {
+			return err
+		}
+		return auditService.RecordTx(tx, origin, auditService.Event{
+			Action:     auditTypes.BoardMoved,
+			TargetType: "board",
+			TargetID:   board.ID,
+			BoardID:    board.ID,
+			Details:    map[string]interface{}{"from": previousWorkspaceID, "to": board.WorkspaceID},
+		})
+	})
+
+	if errors.Is(err, errNotWorkspaceMember) {
+		return views.MoveBoardResponse{
+			Response: views.Response{
+				Message: notWorkspaceMemberMessage,
+				Code:    http.StatusForbidden,
+			},
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return views.MoveBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(workspaceNotFoundMessage, payload.WorkspaceID),
+				Code:    http.StatusUnprocessableEntity,
+			},
+		}
+	}
+	if err != nil {
+		return views.MoveBoardResponse{
+			Response: views.Response{
+				Message: fmt.Sprintf(unableToMoveBoardMessage, payload.BoardID),
+				Code:    http.StatusInternalServerError,
+			},
+		}
+	}
+
+	return views.MoveBoardResponse{
+		Response: views.Response{
+			Message: fmt.Sprintf(successfullyMovedBoardMessage, board.Name),
+			Code:    http.StatusOK,
+		},
+		Board: views.BoardMinimalView{
+			ID:          board.ID,
+			Name:        board.Name,
+			Color:       board.Color,
+			WorkspaceID: board.WorkspaceID,
+		},
+	}
+}
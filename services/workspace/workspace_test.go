@@ -0,0 +1,139 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditService "github.com/EmilyOng/tusk-manager/backend/services/audit"
+	authorizationService "github.com/EmilyOng/tusk-manager/backend/services/authorization"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	dbTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/dbtest"
+	"github.com/EmilyOng/tusk-manager/backend/views"
+)
+
+func findAdmin(t *testing.T, workspace models.Workspace) models.WorkspaceMember {
+	t.Helper()
+	var workspaceMember models.WorkspaceMember
+	err := db.DB.Where("workspace_id = ? AND role = ?", workspace.ID, workspaceTypes.Admin).First(&workspaceMember).Error
+	if err != nil {
+		t.Fatalf("unable to find the admin: %v", err)
+	}
+	return workspaceMember
+}
+
+func TestWorkspaceAdminIsKept(t *testing.T) {
+	dbTestUtils.Setup(t)
+	admin := dbTestUtils.CreateUser(t)
+	workspace := dbTestUtils.CreateWorkspace(t, admin)
+	adminMember := findAdmin(t, workspace)
+	origin := auditService.Origin{ActorID: admin.ID}
+
+	demoted := UpdateWorkspaceMember(origin, views.UpdateWorkspaceMemberPayload{ID: adminMember.ID, WorkspaceID: workspace.ID, Role: workspaceTypes.Member})
+	if demoted.Code != http.StatusUnprocessableEntity || demoted.Message != lastAdminMessage {
+		t.Errorf("demoting the last admin = %d %s, want %d", demoted.Code, demoted.Message, http.StatusUnprocessableEntity)
+	}
+	removed := DeleteWorkspaceMember(origin, views.DeleteWorkspaceMemberPayload{ID: adminMember.ID, WorkspaceID: workspace.ID})
+	if removed.Code != http.StatusUnprocessableEntity || removed.Message != lastAdminMessage {
+		t.Errorf("removing the last admin = %d %s, want %d", removed.Code, removed.Message, http.StatusUnprocessableEntity)
+	}
+	left := LeaveWorkspace(origin, views.LeaveWorkspacePayload{WorkspaceID: workspace.ID})
+	if left.Code != http.StatusUnprocessableEntity || left.Message != lastAdminMessage {
+		t.Errorf("leaving as the last admin = %d %s, want %d", left.Code, left.Message, http.StatusUnprocessableEntity)
+	}
+
+	// Once there is another admin, the admin can leave
+	dbTestUtils.AddWorkspaceMember(t, workspace.ID, dbTestUtils.CreateUser(t), workspaceTypes.Admin)
+	left = LeaveWorkspace(origin, views.LeaveWorkspacePayload{WorkspaceID: workspace.ID})
+	if left.Code != http.StatusOK {
+		t.Errorf("leaving besides another admin = %d %s, want %d", left.Code, left.Message, http.StatusOK)
+	}
+}
+
+func TestPersonalWorkspaceIsNotShared(t *testing.T) {
+	dbTestUtils.Setup(t)
+	user := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, user)
+	origin := auditService.Origin{ActorID: user.ID}
+
+	added := CreateWorkspaceMember(origin, views.CreateWorkspaceMemberPayload{
+		WorkspaceID: board.WorkspaceID,
+		Email:       dbTestUtils.CreateUser(t).Email,
+		Role:        workspaceTypes.Member,
+	})
+	if added.Code != http.StatusUnprocessableEntity || added.Message != personalWorkspaceMessage {
+		t.Errorf("sharing a personal workspace = %d %s, want %d", added.Code, added.Message, http.StatusUnprocessableEntity)
+	}
+	left := LeaveWorkspace(origin, views.LeaveWorkspacePayload{WorkspaceID: board.WorkspaceID})
+	if left.Code != http.StatusUnprocessableEntity || left.Message != personalWorkspaceMessage {
+		t.Errorf("leaving a personal workspace = %d %s, want %d", left.Code, left.Message, http.StatusUnprocessableEntity)
+	}
+	deleted := DeleteWorkspace(origin, views.DeleteWorkspacePayload{ID: board.WorkspaceID})
+	if deleted.Code != http.StatusUnprocessableEntity || deleted.Message != personalWorkspaceMessage {
+		t.Errorf("deleting a personal workspace = %d %s, want %d", deleted.Code, deleted.Message, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestDeleteWorkspaceKeepsTrashedBoards(t *testing.T) {
+	dbTestUtils.Setup(t)
+	admin := dbTestUtils.CreateUser(t)
+	workspace := dbTestUtils.CreateWorkspace(t, admin)
+	board, _ := dbTestUtils.CreateWorkspaceBoard(t, workspace.ID, admin)
+	if err := db.DB.Delete(&board).Error; err != nil {
+		t.Fatalf("unable to move the board to the trash: %v", err)
+	}
+
+	deleted := DeleteWorkspace(auditService.Origin{ActorID: admin.ID}, views.DeleteWorkspacePayload{ID: workspace.ID})
+	if deleted.Code != http.StatusUnprocessableEntity || deleted.Message != workspaceNotEmptyMessage {
+		t.Errorf("deleting a workspace with a board in the trash = %d %s, want %d", deleted.Code, deleted.Message, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestMoveBoardOnlyIntoWorkspacesOfTheActor(t *testing.T) {
+	dbTestUtils.Setup(t)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateBoard(t, owner)
+	otherWorkspace := dbTestUtils.CreateWorkspace(t, dbTestUtils.CreateUser(t))
+	origin := auditService.Origin{ActorID: owner.ID}
+
+	outsider := MoveBoard(origin, views.MoveBoardPayload{BoardID: board.ID, WorkspaceID: otherWorkspace.ID})
+	if outsider.Code != http.StatusForbidden {
+		t.Errorf("moving the board into a workspace of others = %d %s, want %d", outsider.Code, outsider.Message, http.StatusForbidden)
+	}
+
+	dbTestUtils.AddWorkspaceMember(t, otherWorkspace.ID, owner, workspaceTypes.Member)
+	moved := MoveBoard(origin, views.MoveBoardPayload{BoardID: board.ID, WorkspaceID: otherWorkspace.ID})
+	if moved.Code != http.StatusOK || moved.Board.WorkspaceID != otherWorkspace.ID {
+		t.Errorf("moving the board into a workspace of the actor = %d %s, want %d", moved.Code, moved.Message, http.StatusOK)
+	}
+}
+
+func TestOnlyAdminsOfSharedWorkspacesAccessItsBoards(t *testing.T) {
+	dbTestUtils.Setup(t)
+	admin := dbTestUtils.CreateUser(t)
+	workspace := dbTestUtils.CreateWorkspace(t, admin)
+	owner := dbTestUtils.CreateUser(t)
+	board, _ := dbTestUtils.CreateWorkspaceBoard(t, workspace.ID, owner)
+	workspaceMember := dbTestUtils.CreateUser(t)
+	dbTestUtils.AddWorkspaceMember(t, workspace.ID, workspaceMember, workspaceTypes.Member)
+
+	role, err := authorizationService.GetBoardRole(admin.ID, board.ID)
+	if err != nil || role != roleTypes.Owner {
+		t.Errorf("the admin of the workspace holds %q (%v), want %q", role, err, roleTypes.Owner)
+	}
+	if _, err = authorizationService.GetBoardPermissions(workspaceMember.ID, board.ID); err == nil {
+		t.Errorf("a member of the workspace can access a board that was not shared with them")
+	}
+
+	// The creator of a board in a personal workspace loses access once another owner removes them
+	personalBoard, creator := dbTestUtils.CreateBoard(t, owner)
+	dbTestUtils.AddMember(t, personalBoard.ID, admin, roleTypes.Owner)
+	if err = db.DB.Delete(&creator).Error; err != nil {
+		t.Fatalf("unable to remove the creator: %v", err)
+	}
+	if _, err = authorizationService.GetBoardPermissions(owner.ID, personalBoard.ID); err == nil {
+		t.Errorf("the creator can still access the board in their personal workspace after being removed")
+	}
+}
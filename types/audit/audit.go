@@ -0,0 +1,49 @@
+package types
+
+// Security-relevant event recorded in the audit log
+type Action string
+
+const (
+	LoginSucceeded         Action = "login.succeeded"
+	LoginFailed            Action = "login.failed"
+	UserSignedUp           Action = "user.signed_up"
+	TokenCreated           Action = "token.created"
+	TokenRevoked           Action = "token.revoked"
+	MemberInvited          Action = "member.invited"
+	MemberRoleChanged      Action = "member.role_changed"
+	MemberRemoved          Action = "member.removed"
+	InvitationAccepted     Action = "invitation.accepted"
+	InvitationDeclined     Action = "invitation.declined"
+	InviteLinkCreated      Action = "invite_link.created"
+	InviteLinkRevoked      Action = "invite_link.revoked"
+	InviteLinkRedeemed     Action = "invite_link.redeemed"
+	BoardPublished         Action = "board.published"
+	BoardUnpublished       Action = "board.unpublished"
+	MemberLeft             Action = "member.left"
+	BoardDeleted           Action = "board.deleted"
+	BoardRestored          Action = "board.restored"
+	BoardPurged            Action = "board.purged"
+	OwnershipTransferred   Action = "board.ownership_transferred"
+	AccountExported        Action = "account.exported"
+	AccountDeleted         Action = "account.deleted"
+	PasswordChanged        Action = "account.password_changed"
+	EmailChangeRequested   Action = "account.email_change_requested"
+	EmailChanged           Action = "account.email_changed"
+	WorkspaceDeleted       Action = "workspace.deleted"
+	WorkspaceMemberAdded   Action = "workspace.member_added"
+	WorkspaceRoleChanged   Action = "workspace.member_role_changed"
+	WorkspaceMemberRemoved Action = "workspace.member_removed"
+	WorkspaceMemberLeft    Action = "workspace.member_left"
+	BoardMoved             Action = "board.moved"
+	TeamDeleted            Action = "team.deleted"
+	TeamMemberAdded        Action = "team.member_added"
+	TeamMemberRemoved      Action = "team.member_removed"
+	BoardTeamAdded         Action = "board_team.added"
+	BoardTeamRoleChanged   Action = "board_team.role_changed"
+	BoardTeamRemoved       Action = "board_team.removed"
+	CustomRoleCreated      Action = "custom_role.created"
+	CustomRoleUpdated      Action = "custom_role.updated"
+	CustomRoleDeleted      Action = "custom_role.deleted"
+	SessionRefreshed       Action = "session.refreshed"
+	RefreshTokenReused     Action = "session.refresh_token_reused"
+)
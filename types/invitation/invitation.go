@@ -0,0 +1,9 @@
+package types
+
+type Status string
+
+const (
+	Pending  Status = "Pending"
+	Accepted Status = "Accepted"
+	Declined Status = "Declined"
+)
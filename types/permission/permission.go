@@ -0,0 +1,79 @@
+package types
+
+// Action on a board that a role can allow
+type Permission string
+
+const (
+	ViewBoard     Permission = "board:view"
+	ManageBoard   Permission = "board:manage"
+	ViewAudit     Permission = "audit:view"
+	ManageMembers Permission = "members:manage"
+	ManageStates  Permission = "states:manage"
+	ManageTags    Permission = "tags:manage"
+	CreateTasks   Permission = "tasks:create"
+	EditTasks     Permission = "tasks:edit"
+	MoveTasks     Permission = "tasks:move" // Changing the state of a task, without editing it otherwise
+	DeleteTasks   Permission = "tasks:delete"
+)
+
+// Every permission, in the order that they are presented in
+var Catalogue = []Permission{
+	ViewBoard,
+	ManageBoard,
+	ViewAudit,
+	ManageMembers,
+	ManageStates,
+	ManageTags,
+	CreateTasks,
+	EditTasks,
+	MoveTasks,
+	DeleteTasks,
+}
+
+func (permission Permission) IsValid() bool {
+	for _, validPermission := range Catalogue {
+		if permission == validPermission {
+			return true
+		}
+	}
+	return false
+}
+
+type Set map[Permission]bool
+
+func NewSet(permissions ...Permission) Set {
+	set := Set{}
+	set.Add(permissions...)
+	return set
+}
+
+func (set Set) Add(permissions ...Permission) {
+	for _, permission := range permissions {
+		set[permission] = true
+	}
+}
+
+func (set Set) Has(permission Permission) bool {
+	return set[permission]
+}
+
+// Returns whether the set holds every permission of the other set
+func (set Set) Includes(other Set) bool {
+	for permission := range other {
+		if !set[permission] {
+			return false
+		}
+	}
+	return true
+}
+
+// Lists the permissions in the order of the catalogue
+func (set Set) List() []Permission {
+	permissions := []Permission{}
+	for _, permission := range Catalogue {
+		if set[permission] {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
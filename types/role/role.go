@@ -1,5 +1,10 @@
 package types
 
+import (
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+)
+
+// Built-in preset of permissions
 type Role string
 
 const (
@@ -7,3 +12,49 @@ const (
 	Editor Role = "Editor"
 	Viewer Role = "Viewer"
 )
+
+// Ranks the roles by privilege, where a higher rank includes the lower ones
+var ranks = map[Role]int{
+	Viewer: 1,
+	Editor: 2,
+	Owner:  3,
+}
+
+func (role Role) IsValid() bool {
+	_, ok := ranks[role]
+	return ok
+}
+
+// Returns the more privileged of the two roles
+func (role Role) Max(other Role) Role {
+	if ranks[other] > ranks[role] {
+		return other
+	}
+	return role
+}
+
+// Returns whether the role grants at least the privileges of the required role
+func (role Role) Includes(required Role) bool {
+	return role.IsValid() && ranks[role] >= ranks[required]
+}
+
+// Lists the permissions that the preset grants
+func (role Role) Permissions() []permissionTypes.Permission {
+	switch role {
+	case Owner:
+		return permissionTypes.Catalogue
+	case Editor:
+		return []permissionTypes.Permission{
+			permissionTypes.ViewBoard,
+			permissionTypes.ManageStates,
+			permissionTypes.ManageTags,
+			permissionTypes.CreateTasks,
+			permissionTypes.EditTasks,
+			permissionTypes.MoveTasks,
+			permissionTypes.DeleteTasks,
+		}
+	case Viewer:
+		return []permissionTypes.Permission{permissionTypes.ViewBoard}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package types
+
+import "strings"
+
+// Restricts what a personal access token can be used for
+type Scope string
+
+const (
+	BoardsRead   Scope = "boards:read"
+	BoardsWrite  Scope = "boards:write"
+	TasksRead    Scope = "tasks:read"
+	TasksWrite   Scope = "tasks:write"
+	TagsRead     Scope = "tags:read"
+	TagsWrite    Scope = "tags:write"
+	StatesRead   Scope = "states:read"
+	StatesWrite  Scope = "states:write"
+	MembersRead  Scope = "members:read"
+	MembersWrite Scope = "members:write"
+	AuditRead    Scope = "audit:read"
+
+	WorkspacesRead  Scope = "workspaces:read"
+	WorkspacesWrite Scope = "workspaces:write"
+)
+
+var scopes = map[Scope]bool{
+	BoardsRead: true, BoardsWrite: true,
+	TasksRead: true, TasksWrite: true,
+	TagsRead: true, TagsWrite: true,
+	StatesRead: true, StatesWrite: true,
+	MembersRead: true, MembersWrite: true,
+	WorkspacesRead: true, WorkspacesWrite: true,
+	AuditRead: true,
+}
+
+func (scope Scope) IsValid() bool {
+	return scopes[scope]
+}
+
+// Returns whether the scope grants the required scope, where a write scope also grants reading
+func (scope Scope) Includes(required Scope) bool {
+	if scope == required {
+		return true
+	}
+	resource := strings.TrimSuffix(string(scope), ":write")
+	return resource != string(scope) && Scope(resource+":read") == required
+}
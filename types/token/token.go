@@ -0,0 +1,9 @@
+package types
+
+type Purpose string
+
+const (
+	PasswordReset     Purpose = "PasswordReset"
+	EmailVerification Purpose = "EmailVerification"
+	EmailChange       Purpose = "EmailChange"
+)
@@ -0,0 +1,10 @@
+package types
+
+// Kind of item that can be restored from the trash of a board
+type Item string
+
+const (
+	Task  Item = "task"
+	Tag   Item = "tag"
+	State Item = "state"
+)
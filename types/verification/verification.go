@@ -0,0 +1,18 @@
+package types
+
+// Determines what an unverified email address is prevented from doing
+type Policy string
+
+const (
+	None   Policy = "none"
+	Invite Policy = "invite" // Unverified users cannot be invited to boards
+	Login  Policy = "login"  // Unverified users cannot log in, nor be invited to boards
+)
+
+func (policy Policy) RequiresForInvite() bool {
+	return policy == Invite || policy == Login
+}
+
+func (policy Policy) RequiresForLogin() bool {
+	return policy == Login
+}
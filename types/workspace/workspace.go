@@ -0,0 +1,24 @@
+package types
+
+// Role of a member within a workspace
+type Role string
+
+const (
+	Admin  Role = "Admin"  // Manages the workspace, and holds the owner role on every board in the workspace
+	Member Role = "Member" // Creates boards in the workspace, and accesses the boards shared with them
+)
+
+var ranks = map[Role]int{
+	Member: 1,
+	Admin:  2,
+}
+
+func (role Role) IsValid() bool {
+	_, ok := ranks[role]
+	return ok
+}
+
+// Returns whether the role grants at least the privileges of the required role
+func (role Role) Includes(required Role) bool {
+	return role.IsValid() && ranks[role] >= ranks[required]
+}
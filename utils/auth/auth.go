@@ -1,11 +1,19 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"log"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/EmilyOng/tusk-manager/backend/db"
 	"github.com/EmilyOng/tusk-manager/backend/models"
+	keyUtils "github.com/EmilyOng/tusk-manager/backend/utils/keys"
 
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
@@ -16,35 +24,79 @@ type Claim struct {
 	UserID    string
 	UserName  string
 	UserEmail string
+	SessionID string
+}
+
+// Issued after the password step of a login with two-factor authentication
+type ChallengeClaim struct {
+	jwt.StandardClaims
+	UserID  string
+	Purpose string
 }
 
 const (
-	UserKey string = "user"
+	UserKey                string = "user"
+	SessionKey             string = "session"
+	PersonalAccessTokenKey string = "personalAccessToken"
+	BoardPermissionsKey    string = "boardPermissions"
+
+	PersonalAccessTokenPrefix = "tusk_pat_"
+
+	AccessTokenDuration  = 15 * time.Minute
+	RefreshTokenDuration = 30 * 24 * time.Hour
+
+	ChallengeTokenDuration = 5 * time.Minute
+	challengePurpose       = "two-factor"
+
+	// Distinguish access tokens from other tokens, for this and other services verifying them through the JWKS
+	AccessTokenType     = "at+jwt"
+	AccessTokenAudience = "tusk-manager"
+
+	challengeTokenType     = "two-factor-challenge+jwt"
+	challengeTokenAudience = "tusk-manager:two-factor"
+)
+
+var (
+	challengeSecretOnce sync.Once
+	challengeSecret     []byte
 )
 
-func GenerateToken(user models.User) (signedToken string, err error) {
-	// Token expires in 24 hours
+// Returns the secret that signs challenge tokens, which is never published, from 'AUTH_CHALLENGE_SECRET'.
+// Without it, each instance generates its own secret, so that challenges are only accepted by the instance
+// that issued them.
+func getChallengeSecret() []byte {
+	challengeSecretOnce.Do(func() {
+		challengeSecret = []byte(os.Getenv("AUTH_CHALLENGE_SECRET"))
+		if len(challengeSecret) > 0 {
+			return
+		}
+		challengeSecret = make([]byte, 32)
+		_, err := rand.Read(challengeSecret)
+		if err != nil {
+			log.Fatalln("Unable to generate the challenge secret", err)
+		}
+	})
+	return challengeSecret
+}
+
+func GenerateToken(user models.User, sessionID string) (signedToken string, err error) {
 	claims := &Claim{
 		UserID:    user.ID,
 		UserName:  user.Name,
 		UserEmail: user.Email,
+		SessionID: sessionID,
 		StandardClaims: jwt.StandardClaims{
-			// Express in unix milliseconds
-			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
+			Audience: AccessTokenAudience,
+			// Express in unix seconds
+			ExpiresAt: time.Now().Add(AccessTokenDuration).Unix(),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	secretKey := os.Getenv("AUTH_SECRET_KEY")
-	signedToken, err = token.SignedString([]byte(secretKey))
+	signedToken, err = keyUtils.Default.Sign(claims, AccessTokenType)
 	return
 }
 
 func ValidateToken(signedToken string) (claims *Claim, err error) {
-	secretKey := os.Getenv("AUTH_SECRET_KEY")
-	token, err := jwt.ParseWithClaims(signedToken, &Claim{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(signedToken, &Claim{}, keyUtils.Default.Keyfunc)
 	if err != nil {
 		return
 	}
@@ -54,13 +106,88 @@ func ValidateToken(signedToken string) (claims *Claim, err error) {
 		err = errors.New("Cannot parse JWT claims")
 		return
 	}
+	if tokenType, _ := token.Header["typ"].(string); tokenType != AccessTokenType || !claims.VerifyAudience(AccessTokenAudience, true) {
+		err = errors.New("JWT token is not an access token")
+		return
+	}
 	if claims.ExpiresAt < time.Now().Local().Unix() {
 		err = errors.New("JWT token has expired")
 		return
 	}
+
+	// Rejects tokens belonging to revoked sessions
+	var activeSessions int64
+	err = db.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", claims.SessionID, claims.UserID).
+		Count(&activeSessions).
+		Error
+	if err != nil {
+		return
+	}
+	if activeSessions == 0 {
+		err = errors.New("JWT token belongs to a revoked session")
+		return
+	}
 	return
 }
 
+// Signs the challenge with its own secret rather than the published keys, so that it is never mistaken for
+// an access token
+func GenerateChallengeToken(user models.User) (signedToken string, err error) {
+	claims := &ChallengeClaim{
+		UserID:  user.ID,
+		Purpose: challengePurpose,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  challengeTokenAudience,
+			ExpiresAt: time.Now().Add(ChallengeTokenDuration).Unix(),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["typ"] = challengeTokenType
+	signedToken, err = token.SignedString(getChallengeSecret())
+	return
+}
+
+// Returns the user that passed the password step of the login
+func ValidateChallengeToken(signedToken string) (userID string, err error) {
+	token, err := jwt.ParseWithClaims(signedToken, &ChallengeClaim{}, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return getChallengeSecret(), nil
+	})
+	if err != nil {
+		return
+	}
+
+	claims, valid := token.Claims.(*ChallengeClaim)
+	tokenType, _ := token.Header["typ"].(string)
+	if !valid || claims.Purpose != challengePurpose || tokenType != challengeTokenType ||
+		!claims.VerifyAudience(challengeTokenAudience, true) {
+		err = errors.New("Cannot parse challenge claims")
+		return
+	}
+	userID = claims.UserID
+	return
+}
+
+// Generates a random opaque token, together with its hash for storage
+func GenerateOpaqueToken() (token string, hash string, err error) {
+	bytes := make([]byte, 32)
+	_, err = rand.Read(bytes)
+	if err != nil {
+		return
+	}
+	token = base64.RawURLEncoding.EncodeToString(bytes)
+	hash = HashOpaqueToken(token)
+	return
+}
+
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func HashPassword(password string) (hashed string, err error) {
 	// Uses a hashing cost of 10
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 10)
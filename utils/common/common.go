@@ -1,5 +1,58 @@
 package utils
 
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/constants"
+)
+
+// Retrieves the frontend URL used in links sent by email
+func GetFrontendUrl() string {
+	return GetEnv("FRONTEND_URL", constants.FrontendProductionUrl)
+}
+
 func GetDefaultStates() []string {
 	return []string{"To Do", "In Progress", "Completed"}
 }
+
+// Retrieves the environment variable, or the fallback when it is not set
+func GetEnv(key string, fallback string) string {
+	value, ok := os.LookupEnv(key)
+	if !ok || len(value) == 0 {
+		return fallback
+	}
+	return value
+}
+
+// Retrieves the environment variable as an integer, or the fallback when it is not set or invalid
+func GetEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Retrieves the environment variable as a duration (e.g. '30s'), or the fallback when it is not set or invalid
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Retrieves the environment variable as a comma-separated list, which is empty when it is not set
+func GetEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if len(value) > 0 {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	colorTypes "github.com/EmilyOng/tusk-manager/backend/types/color"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Held while migrating, as the packages under test run at the same time against the same database
+const migrationLockKey = 727_000_001
+
+var setupOnce sync.Once
+var setupErr error
+
+// Connects the services to the disposable database at 'TEST_DATABASE_URL', skipping the test when it is not set.
+// Every fixture is created with fresh IDs and emails, so that tests do not depend on each other.
+func Setup(t *testing.T) {
+	t.Helper()
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if len(databaseURL) == 0 {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	t.Setenv("DATABASE_URL", databaseURL)
+	setupOnce.Do(func() {
+		setupErr = migrate(databaseURL)
+	})
+	if setupErr != nil {
+		t.Fatalf("unable to setup the database: %v", setupErr)
+	}
+}
+
+func migrate(databaseURL string) error {
+	lockDB, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	sqlDB, err := lockDB.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	// Session locks belong to a connection, so the lock is taken and released on the same one
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey)
+	if err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	return db.Setup()
+}
+
+func create(t *testing.T, value interface{}) {
+	t.Helper()
+	if err := db.DB.Create(value).Error; err != nil {
+		t.Fatalf("unable to create %T: %v", value, err)
+	}
+}
+
+// Creates a user with a verified email
+func CreateUser(t *testing.T) models.User {
+	t.Helper()
+	user := models.User{Name: "User", Email: uuid.NewString() + "@example.com", Password: "hash", EmailVerified: true}
+	create(t, &user)
+	return user
+}
+
+// Creates a shared workspace that the user administers
+func CreateWorkspace(t *testing.T, admin models.User) models.Workspace {
+	t.Helper()
+	workspace := models.Workspace{
+		Name:    "Workspace",
+		Members: []*models.WorkspaceMember{{Role: workspaceTypes.Admin, UserID: admin.ID}},
+	}
+	create(t, &workspace)
+	return workspace
+}
+
+// Adds the user to the workspace
+func AddWorkspaceMember(t *testing.T, workspaceID string, user models.User, role workspaceTypes.Role) models.WorkspaceMember {
+	t.Helper()
+	workspaceMember := models.WorkspaceMember{Role: role, UserID: user.ID, WorkspaceID: workspaceID}
+	create(t, &workspaceMember)
+	return workspaceMember
+}
+
+// Creates a board in the personal workspace of the owner, which does not grant anyone else access to it
+func CreateBoard(t *testing.T, owner models.User) (models.Board, models.Member) {
+	t.Helper()
+	var workspace models.Workspace
+	err := db.DB.Where("user_id = ?", owner.ID).Limit(1).Find(&workspace).Error
+	if err != nil {
+		t.Fatalf("unable to find the personal workspace: %v", err)
+	}
+	if len(workspace.ID) == 0 {
+		workspace = models.NewPersonalWorkspace(owner.ID)
+		create(t, &workspace)
+	}
+	return CreateWorkspaceBoard(t, workspace.ID, owner)
+}
+
+// Creates a board in the workspace, owned by the user
+func CreateWorkspaceBoard(t *testing.T, workspaceID string, owner models.User) (models.Board, models.Member) {
+	t.Helper()
+	board := models.Board{Name: "Board", Color: colorTypes.Cyan, WorkspaceID: workspaceID}
+	create(t, &board)
+	return board, AddMember(t, board.ID, owner, roleTypes.Owner)
+}
+
+// Shares the board with the user
+func AddMember(t *testing.T, boardID string, user models.User, role roleTypes.Role) models.Member {
+	t.Helper()
+	member := models.Member{Role: role, UserID: user.ID, BoardID: boardID}
+	create(t, &member)
+	return member
+}
+
+// Adds a state at the end of the board
+func CreateState(t *testing.T, boardID string) models.State {
+	t.Helper()
+	var states int64
+	if err := db.DB.Model(&models.State{}).Where("board_id = ?", boardID).Count(&states).Error; err != nil {
+		t.Fatalf("unable to count the states: %v", err)
+	}
+	state := models.State{Name: "State", CurrentPosition: int(states), BoardID: boardID}
+	create(t, &state)
+	return state
+}
+
+// Adds a tag to the board
+func CreateTag(t *testing.T, boardID string) models.Tag {
+	t.Helper()
+	tag := models.Tag{Name: "Tag", Color: colorTypes.Cyan, BoardID: boardID}
+	create(t, &tag)
+	return tag
+}
+
+// Reads the member again, which is empty once the member has been removed
+func FindMember(t *testing.T, memberID string) models.Member {
+	t.Helper()
+	var member models.Member
+	if err := db.DB.Where("id = ?", memberID).Limit(1).Find(&member).Error; err != nil {
+		t.Fatalf("unable to find the member: %v", err)
+	}
+	return member
+}
@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+
+	// Identifies the key of tokens issued before keys were identified by 'kid'
+	legacyKeyID = "default"
+)
+
+// Configuration of a key, as listed in the file at 'AUTH_KEYS_FILE'
+type KeyConfig struct {
+	ID        string `json:"id"`
+	Algorithm string `json:"algorithm"`
+	// PEM-encoded private key (or public key, for keys that only verify tokens) for RS256 and EdDSA
+	File string `json:"file"`
+	// Environment variable holding the secret for HS256
+	SecretEnv string `json:"secretEnv"`
+}
+
+type Config struct {
+	ActiveKeyID string      `json:"activeKeyId"` // Signs new tokens
+	Keys        []KeyConfig `json:"keys"`        // Verify tokens, until they are removed (retired)
+}
+
+type Key struct {
+	ID              string
+	Algorithm       string
+	signingKey      interface{} // nil when the key only verifies tokens
+	verificationKey interface{}
+}
+
+type KeySet struct {
+	mutex  sync.RWMutex
+	active *Key
+	keys   map[string]*Key
+}
+
+// Published at the JWKS endpoint, so that other services can verify tokens
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// Key set used by the application, configured by Setup
+var Default = &KeySet{keys: map[string]*Key{}}
+
+// Configures the key set from the file at 'AUTH_KEYS_FILE', falling back to
+// 'AUTH_SECRET_KEY' as the only (HS256) key
+func Setup() error {
+	config := Config{
+		ActiveKeyID: legacyKeyID,
+		Keys:        []KeyConfig{{ID: legacyKeyID, Algorithm: HS256, SecretEnv: "AUTH_SECRET_KEY"}},
+	}
+	if path := os.Getenv("AUTH_KEYS_FILE"); len(path) > 0 {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return err
+		}
+	}
+	return Default.Load(config)
+}
+
+func parseKey(config KeyConfig) (*Key, error) {
+	key := &Key{ID: config.ID, Algorithm: config.Algorithm}
+	if len(key.ID) == 0 {
+		return nil, errors.New("key is missing an id")
+	}
+
+	if key.Algorithm == HS256 {
+		secret := os.Getenv(config.SecretEnv)
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("key %s is missing a secret", key.ID)
+		}
+		key.signingKey = []byte(secret)
+		key.verificationKey = key.signingKey
+		return key, nil
+	}
+
+	data, err := os.ReadFile(config.File)
+	if err != nil {
+		return nil, err
+	}
+	switch key.Algorithm {
+	case RS256:
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.signingKey = privateKey
+			key.verificationKey = &privateKey.PublicKey
+		} else {
+			key.verificationKey, err = jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case EdDSA:
+		if privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			key.signingKey = privateKey
+			key.verificationKey = privateKey.(ed25519.PrivateKey).Public()
+		} else {
+			key.verificationKey, err = jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("key %s has an unsupported algorithm %s", key.ID, key.Algorithm)
+	}
+	return key, nil
+}
+
+// Replaces the keys, which requires the active key to be able to sign tokens
+func (keySet *KeySet) Load(config Config) error {
+	keys := map[string]*Key{}
+	for _, keyConfig := range config.Keys {
+		key, err := parseKey(keyConfig)
+		if err != nil {
+			return err
+		}
+		if _, ok := keys[key.ID]; ok {
+			return fmt.Errorf("key %s is listed more than once", key.ID)
+		}
+		keys[key.ID] = key
+	}
+
+	active, ok := keys[config.ActiveKeyID]
+	if !ok || active.signingKey == nil {
+		return fmt.Errorf("active key %s cannot sign tokens", config.ActiveKeyID)
+	}
+
+	keySet.mutex.Lock()
+	defer keySet.mutex.Unlock()
+	keySet.active = active
+	keySet.keys = keys
+	return nil
+}
+
+// Signs the claims with the active key, identifying it by 'kid' and the type of token by 'typ' in the header
+func (keySet *KeySet) Sign(claims jwt.Claims, tokenType string) (string, error) {
+	keySet.mutex.RLock()
+	active := keySet.active
+	keySet.mutex.RUnlock()
+	if active == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(active.Algorithm), claims)
+	token.Header["kid"] = active.ID
+	token.Header["typ"] = tokenType
+	return token.SignedString(active.signingKey)
+}
+
+// Selects the key that verifies the token, to be used with 'jwt.Parse'
+func (keySet *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		kid = legacyKeyID
+	}
+
+	keySet.mutex.RLock()
+	key, ok := keySet.keys[kid]
+	keySet.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+	// Prevents tokens from choosing how they are verified (e.g. HS256 with a public key)
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.verificationKey, nil
+}
+
+// Lists the public keys, leaving out the symmetric (HS256) keys that must remain secret
+func (keySet *KeySet) JWKS() JSONWebKeySet {
+	keySet.mutex.RLock()
+	defer keySet.mutex.RUnlock()
+
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range keySet.keys {
+		jwk := JSONWebKey{Kid: key.ID, Alg: key.Algorithm, Use: "sig"}
+		switch verificationKey := key.verificationKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(verificationKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(verificationKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(verificationKey)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(message Message) error
+}
+
+// Mailer used by the application, configured by Setup
+var DefaultMailer Mailer = &MemoryMailer{}
+
+var (
+	errMailerNotConfigured = errors.New("MAILER must be one of smtp, file or memory")
+	errSMTPNotConfigured   = errors.New("the smtp mailer requires SMTP_HOST, SMTP_PORT and MAIL_FROM")
+)
+
+// Strips line breaks from header values, so that they cannot add headers of their own
+var headerSanitizer = strings.NewReplacer("\r", "", "\n", "")
+
+// Configures the mailer from the 'MAILER' environment variable (smtp, file or memory). The mailer must be
+// chosen explicitly, so that emails are never silently kept in memory in production.
+func Setup() (err error) {
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		mailer := &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+		if len(mailer.Host) == 0 || len(mailer.Port) == 0 || len(mailer.From) == 0 {
+			return errSMTPNotConfigured
+		}
+		DefaultMailer = mailer
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if len(dir) == 0 {
+			dir = filepath.Join("tmp", "mail")
+		}
+		err = os.MkdirAll(dir, 0o755)
+		DefaultMailer = &FileMailer{Dir: dir}
+	case "memory":
+		log.Println("Emails are kept in memory and will not be delivered (MAILER=memory)")
+		DefaultMailer = &MemoryMailer{}
+	default:
+		err = errMailerNotConfigured
+	}
+	return
+}
+
+func Send(message Message) error {
+	return DefaultMailer.Send(message)
+}
+
+// Delivers messages through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (mailer *SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+	if len(mailer.Username) > 0 {
+		auth = smtp.PlainAuth("", mailer.Username, mailer.Password, mailer.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + headerSanitizer.Replace(mailer.From),
+		"To: " + headerSanitizer.Replace(message.To),
+		"Subject: " + headerSanitizer.Replace(message.Subject),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		message.Body,
+	}, "\r\n")
+	return smtp.SendMail(mailer.Host+":"+mailer.Port, auth, mailer.From, []string{message.To}, []byte(body))
+}
+
+// Writes each message to a file in the directory, for local development
+type FileMailer struct {
+	Dir string
+}
+
+func (mailer *FileMailer) Send(message Message) error {
+	name := fmt.Sprintf("%d-%s.txt", time.Now().UnixNano(), strings.ReplaceAll(message.To, "/", "_"))
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", message.To, message.Subject, message.Body)
+	return os.WriteFile(filepath.Join(mailer.Dir, name), []byte(content), 0o644)
+}
+
+// Keeps messages in memory, for tests
+type MemoryMailer struct {
+	mutex    sync.Mutex
+	messages []Message
+}
+
+func (mailer *MemoryMailer) Send(message Message) error {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+	mailer.messages = append(mailer.messages, message)
+	return nil
+}
+
+// Returns the messages sent so far
+func (mailer *MemoryMailer) Messages() []Message {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+	return append([]Message(nil), mailer.messages...)
+}
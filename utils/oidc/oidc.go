@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// OpenID Connect provider configuration, as published at '/.well-known/openid-configuration'
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// The audience of an ID token may either be a single string or a list of strings
+type audience []string
+
+func (aud *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*aud = audience{single}
+		return nil
+	}
+	var multiple []string
+	err := json.Unmarshal(data, &multiple)
+	*aud = multiple
+	return err
+}
+
+type IDTokenClaims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	ExpiresAt     int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+func (claims *IDTokenClaims) Valid() error {
+	if claims.ExpiresAt < time.Now().Unix() {
+		return errors.New("ID token has expired")
+	}
+	return nil
+}
+
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mutex     sync.Mutex
+	discovery *Discovery
+	keys      map[string]interface{}
+}
+
+// Provider used by the application, configured by Setup. It is nil when OpenID Connect is not configured.
+var Default *Provider
+
+// Configures the provider from the 'OIDC_*' environment variables
+func Setup() {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if len(issuer) == 0 {
+		Default = nil
+		return
+	}
+
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	Default = &Provider{
+		Issuer:       strings.TrimSuffix(issuer, "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       scopes,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (provider *Provider) getJSON(endpoint string, target interface{}) error {
+	response, err := provider.HTTPClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", response.StatusCode, endpoint)
+	}
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+// Retrieves (and caches) the provider configuration
+func (provider *Provider) Discover() (Discovery, error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	if provider.discovery != nil {
+		return *provider.discovery, nil
+	}
+
+	var discovery Discovery
+	err := provider.getJSON(provider.Issuer+"/.well-known/openid-configuration", &discovery)
+	if err != nil {
+		return discovery, err
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != provider.Issuer {
+		return discovery, errors.New("discovered issuer does not match the configured issuer")
+	}
+	provider.discovery = &discovery
+	return discovery, nil
+}
+
+// Generates a random value for the state, nonce or PKCE code verifier
+func GenerateRandomString() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	return base64.RawURLEncoding.EncodeToString(bytes), err
+}
+
+// Derives the S256 PKCE code challenge of the code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Builds the URL to redirect the user to for the authorization code flow with PKCE
+func (provider *Provider) AuthCodeURL(state string, nonce string, codeVerifier string) (string, error) {
+	discovery, err := provider.Discover()
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.ClientID},
+		"redirect_uri":          {provider.RedirectURL},
+		"scope":                 {strings.Join(provider.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchanges the authorization code for the raw ID token
+func (provider *Provider) Exchange(code string, codeVerifier string) (string, error) {
+	discovery, err := provider.Discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {provider.RedirectURL},
+		"client_id":     {provider.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if len(provider.ClientSecret) > 0 {
+		form.Set("client_secret", provider.ClientSecret)
+	}
+
+	response, err := provider.HTTPClient.PostForm(discovery.TokenEndpoint, form)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&tokenResponse)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK || len(tokenResponse.IDToken) == 0 {
+		return "", fmt.Errorf("unable to exchange the authorization code: %s", tokenResponse.Error)
+	}
+	return tokenResponse.IDToken, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+func parseJSONWebKey(key jsonWebKey) (interface{}, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if key.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", key.Crv)
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", key.Kty)
+}
+
+// Retrieves the verification key, refreshing the JWKS when the key is unknown (e.g. after the provider rotates keys)
+func (provider *Provider) getKey(kid string) (interface{}, error) {
+	discovery, err := provider.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	if key, ok := provider.keys[kid]; ok {
+		return key, nil
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err = provider.getJSON(discovery.JWKSURI, &jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.keys = map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			continue
+		}
+		provider.keys[jwk.Kid] = key
+	}
+
+	key, ok := provider.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %s", kid)
+	}
+	return key, nil
+}
+
+// Validates the signature of the ID token against the JWKS, together with its issuer, audience, expiry and nonce
+func (provider *Provider) VerifyIDToken(rawIDToken string, nonce string) (claims IDTokenClaims, err error) {
+	_, err = jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		kid, _ := token.Header["kid"].(string)
+		return provider.getKey(kid)
+	})
+	if err != nil {
+		return
+	}
+
+	if strings.TrimSuffix(claims.Issuer, "/") != provider.Issuer {
+		err = errors.New("ID token has an unexpected issuer")
+		return
+	}
+	validAudience := false
+	for _, aud := range claims.Audience {
+		validAudience = validAudience || aud == provider.ClientID
+	}
+	if !validAudience {
+		err = errors.New("ID token has an unexpected audience")
+		return
+	}
+	if len(claims.Subject) == 0 || claims.Nonce != nonce {
+		err = errors.New("ID token has an unexpected subject or nonce")
+		return
+	}
+	return
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	oidcTestUtils "github.com/EmilyOng/tusk-manager/backend/utils/oidctest"
+	"github.com/golang-jwt/jwt"
+)
+
+const testClientID = "tusk-manager"
+
+func newTestProvider(t *testing.T) (*Provider, *oidcTestUtils.Server) {
+	server := oidcTestUtils.NewServer(testClientID)
+	t.Cleanup(server.Close)
+	provider := &Provider{
+		Issuer:      server.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost:3000/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+		HTTPClient:  &http.Client{Timeout: 5 * time.Second},
+	}
+	return provider, server
+}
+
+func TestAuthCodeURLUsesPKCE(t *testing.T) {
+	provider, server := newTestProvider(t)
+
+	authorizationURL, err := provider.AuthCodeURL("state", "nonce", "verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() returned an error: %v", err)
+	}
+	parsedURL, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() returned an invalid URL: %v", err)
+	}
+
+	if endpoint := server.URL + "/authorize"; parsedURL.Scheme+"://"+parsedURL.Host+parsedURL.Path != endpoint {
+		t.Errorf("AuthCodeURL() = %s, want the authorization endpoint %s", authorizationURL, endpoint)
+	}
+	query := parsedURL.Query()
+	tests := []struct {
+		parameter string
+		value     string
+	}{
+		{parameter: "response_type", value: "code"},
+		{parameter: "client_id", value: testClientID},
+		{parameter: "redirect_uri", value: provider.RedirectURL},
+		{parameter: "scope", value: "openid email profile"},
+		{parameter: "state", value: "state"},
+		{parameter: "nonce", value: "nonce"},
+		{parameter: "code_challenge", value: CodeChallenge("verifier")},
+		{parameter: "code_challenge_method", value: "S256"},
+	}
+	for _, test := range tests {
+		if value := query.Get(test.parameter); value != test.value {
+			t.Errorf("%s = %q, want %q", test.parameter, value, test.value)
+		}
+	}
+	if query.Get("code_challenge") == "verifier" {
+		t.Error("the code verifier must not be sent in the authorization URL")
+	}
+}
+
+func TestExchangeRequiresTheCodeVerifier(t *testing.T) {
+	tests := []struct {
+		name         string
+		codeVerifier string
+		reuse        bool
+		valid        bool
+	}{
+		{name: "matching verifier", codeVerifier: "verifier", valid: true},
+		{name: "other verifier", codeVerifier: "other-verifier", valid: false},
+		{name: "missing verifier", codeVerifier: "", valid: false},
+		{name: "code used twice", codeVerifier: "verifier", reuse: true, valid: false},
+	}
+
+	for _, test := range tests {
+		provider, server := newTestProvider(t)
+		authorizationURL, err := provider.AuthCodeURL("state", "nonce", "verifier")
+		if err != nil {
+			t.Fatalf("AuthCodeURL() returned an error: %v", err)
+		}
+		code, _, err := server.Authorize(authorizationURL, "subject", nil)
+		if err != nil {
+			t.Fatalf("Authorize() returned an error: %v", err)
+		}
+
+		if test.reuse {
+			if _, err = provider.Exchange(code, test.codeVerifier); err != nil {
+				t.Fatalf("%s: first Exchange() returned an error: %v", test.name, err)
+			}
+		}
+		rawIDToken, err := provider.Exchange(code, test.codeVerifier)
+		if test.valid && (err != nil || len(rawIDToken) == 0) {
+			t.Errorf("%s: Exchange() = %q, %v, want an ID token", test.name, rawIDToken, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: Exchange() succeeded, want an error", test.name)
+		}
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims // Replaces the claims of a valid ID token
+		nonce  string
+		valid  bool
+	}{
+		{name: "valid", nonce: "nonce", valid: true},
+		{name: "audience list", claims: jwt.MapClaims{"aud": []string{"other", testClientID}}, nonce: "nonce", valid: true},
+		{name: "other issuer", claims: jwt.MapClaims{"iss": "https://attacker.example.com"}, nonce: "nonce", valid: false},
+		{name: "other audience", claims: jwt.MapClaims{"aud": "other"}, nonce: "nonce", valid: false},
+		{name: "other nonce", nonce: "other-nonce", valid: false},
+		{name: "expired", claims: jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}, nonce: "nonce", valid: false},
+		{name: "missing subject", claims: jwt.MapClaims{"sub": ""}, nonce: "nonce", valid: false},
+	}
+
+	provider, server := newTestProvider(t)
+	for _, test := range tests {
+		claims := server.Claims("subject", "nonce")
+		for name, value := range test.claims {
+			claims[name] = value
+		}
+
+		_, err := provider.VerifyIDToken(server.SignIDToken(claims), test.nonce)
+		if test.valid && err != nil {
+			t.Errorf("%s: VerifyIDToken() returned an error: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: VerifyIDToken() succeeded, want an error", test.name)
+		}
+	}
+}
+
+func TestVerifyIDTokenRejectsUnexpectedSignatures(t *testing.T) {
+	provider, server := newTestProvider(t)
+	claims := server.Claims("subject", "nonce")
+
+	// Signed with a shared secret, which must not be accepted in place of the published keys
+	symmetric := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	symmetric.Header["kid"] = "key-1"
+	hmacToken, err := symmetric.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("unable to sign the token: %v", err)
+	}
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	noneToken, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("unable to sign the token: %v", err)
+	}
+	signedToken := server.SignIDToken(claims)
+	tamperedToken := signedToken[:len(signedToken)-4] + "AAAA"
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "HS256", token: hmacToken},
+		{name: "none", token: noneToken},
+		{name: "tampered signature", token: tamperedToken},
+	}
+	for _, test := range tests {
+		_, err := provider.VerifyIDToken(test.token, "nonce")
+		if err == nil {
+			t.Errorf("%s: VerifyIDToken() succeeded, want an error", test.name)
+		}
+	}
+}
+
+func TestVerifyIDTokenAfterKeyRotation(t *testing.T) {
+	provider, server := newTestProvider(t)
+
+	_, err := provider.VerifyIDToken(server.SignIDToken(server.Claims("subject", "nonce")), "nonce")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() returned an error: %v", err)
+	}
+
+	// The provider fetches the JWKS again for a key that it has not seen
+	server.RotateKey()
+	_, err = provider.VerifyIDToken(server.SignIDToken(server.Claims("subject", "nonce")), "nonce")
+	if err != nil {
+		t.Errorf("VerifyIDToken() after rotating keys returned an error: %v", err)
+	}
+}
+
+func TestDiscoverRejectsAnotherIssuer(t *testing.T) {
+	provider, _ := newTestProvider(t)
+	provider.Issuer = provider.Issuer + "/other"
+
+	_, err := provider.Discover()
+	if err == nil {
+		t.Error("Discover() succeeded for a provider that publishes another issuer")
+	}
+}
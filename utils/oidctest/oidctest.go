@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// In-process OpenID Connect provider for tests. It publishes its discovery document and JWKS, and issues
+// ID tokens through the authorization code flow with PKCE.
+type Server struct {
+	*httptest.Server
+	ClientID string
+
+	mutex  sync.Mutex
+	keyID  string
+	key    *rsa.PrivateKey
+	keys   map[string]*rsa.PrivateKey // Published keys, by ID
+	codes  map[string]authorization
+	serial int
+}
+
+// Authorization granted at the authorization endpoint, waiting to be exchanged for an ID token
+type authorization struct {
+	claims        jwt.MapClaims
+	codeChallenge string
+	redirectURI   string
+}
+
+var errInvalidAuthorizationURL = errors.New("the authorization URL is not a valid PKCE request")
+
+func NewServer(clientID string) *Server {
+	server := &Server{
+		ClientID: clientID,
+		keys:     map[string]*rsa.PrivateKey{},
+		codes:    map[string]authorization{},
+	}
+	server.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", server.handleDiscovery)
+	mux.HandleFunc("/jwks", server.handleJWKS)
+	mux.HandleFunc("/token", server.handleToken)
+	server.Server = httptest.NewServer(mux)
+	return server
+}
+
+// Signs new ID tokens with a new key, which is published together with the previous keys
+func (server *Server) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.serial++
+	server.keyID = fmt.Sprintf("key-%d", server.serial)
+	server.key = key
+	server.keys[server.keyID] = key
+}
+
+// Returns the claims of a valid ID token for the subject, to be adjusted by tests
+func (server *Server) Claims(subject string, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   server.URL,
+		"sub":   subject,
+		"aud":   server.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": nonce,
+	}
+}
+
+// Signs the claims with the current key of the provider
+func (server *Server) SignIDToken(claims jwt.MapClaims) string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = server.keyID
+	signedToken, err := token.SignedString(server.key)
+	if err != nil {
+		panic(err)
+	}
+	return signedToken
+}
+
+// Signs the user in at the authorization URL, returning the code and state that the provider redirects back with.
+// The claims are added to those of a valid ID token, which also carries the nonce of the request.
+func (server *Server) Authorize(authorizationURL string, subject string, claims jwt.MapClaims) (code string, state string, err error) {
+	parsedURL, err := url.Parse(authorizationURL)
+	if err != nil {
+		return
+	}
+	query := parsedURL.Query()
+	if query.Get("response_type") != "code" ||
+		query.Get("client_id") != server.ClientID ||
+		query.Get("code_challenge_method") != "S256" ||
+		len(query.Get("code_challenge")) == 0 {
+		err = errInvalidAuthorizationURL
+		return
+	}
+
+	idTokenClaims := server.Claims(subject, query.Get("nonce"))
+	for name, value := range claims {
+		idTokenClaims[name] = value
+	}
+
+	code = fmt.Sprintf("code-%d-%s", time.Now().UnixNano(), subject)
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	server.codes[code] = authorization{
+		claims:        idTokenClaims,
+		codeChallenge: query.Get("code_challenge"),
+		redirectURI:   query.Get("redirect_uri"),
+	}
+	return code, query.Get("state"), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func (server *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 server.URL,
+		"authorization_endpoint": server.URL + "/authorize",
+		"token_endpoint":         server.URL + "/token",
+		"jwks_uri":               server.URL + "/jwks",
+	})
+}
+
+func (server *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	keys := []map[string]string{}
+	for keyID, key := range server.keys {
+		keys = append(keys, map[string]string{
+			"kid": keyID,
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+// Exchanges a code once, for the client that presents the code verifier of its code challenge
+func (server *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	server.mutex.Lock()
+	grant, ok := server.codes[r.PostForm.Get("code")]
+	delete(server.codes, r.PostForm.Get("code"))
+	server.mutex.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok ||
+		r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != server.ClientID ||
+		r.PostForm.Get("redirect_uri") != grant.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     server.SignIDToken(grant.claims),
+	})
+}
@@ -0,0 +1,62 @@
+package utils
+
+import "strings"
+
+// Ranks are base-36 fractions written without the leading '0.', which sort lexicographically
+// in the same order as their values. A rank never ends with the lowest digit, so there is always
+// room for another rank before it.
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// Ranks longer than this should be spread out again, before they keep growing
+const MaxLength = 12
+
+// Returns a rank strictly between the ranks, where an empty rank is unbounded.
+// The ranks must be in order.
+func Between(before string, after string) string {
+	var rank strings.Builder
+	bounded := len(after) > 0
+	for i := 0; ; i++ {
+		low := 0
+		if i < len(before) {
+			low = strings.IndexByte(digits, before[i])
+		}
+		high := base
+		if bounded && i < len(after) {
+			high = strings.IndexByte(digits, after[i])
+		}
+
+		if high-low > 1 {
+			rank.WriteByte(digits[(low+high)/2])
+			return rank.String()
+		}
+		rank.WriteByte(digits[low])
+		if high > low {
+			// The rank is already below the upper bound, whatever the next digits are
+			bounded = false
+		}
+	}
+}
+
+// Returns the number of ranks evenly spread out in order, which are as short as possible
+func Spread(count int) []string {
+	width, capacity := 1, base
+	for capacity < 2*(count+1) {
+		width++
+		capacity *= base
+	}
+
+	step := capacity / (count + 1)
+	ranks := make([]string, count)
+	for i := range ranks {
+		value := (i + 1) * step
+		rank := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			rank[j] = digits[value%base]
+			value /= base
+		}
+		ranks[i] = strings.TrimRight(string(rank), digits[:1])
+	}
+	return ranks
+}
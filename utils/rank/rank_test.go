@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Checks that the ranks are strictly increasing, which also rules out duplicates, and that none of them
+// ends with the lowest digit
+func checkRanks(t *testing.T, ranks []string) {
+	t.Helper()
+	for i, rank := range ranks {
+		if len(rank) == 0 || strings.HasSuffix(rank, digits[:1]) {
+			t.Fatalf("rank %d is %q, which leaves no room before it", i, rank)
+		}
+		if i > 0 && ranks[i-1] >= rank {
+			t.Fatalf("rank %d is %q, which is not after %q", i, rank, ranks[i-1])
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		before string
+		after  string
+		rank   string
+	}{
+		{before: "", after: "", rank: "i"},
+		{before: "i", after: "", rank: "r"},
+		{before: "", after: "i", rank: "9"},
+		{before: "", after: "1", rank: "0i"},
+		{before: "z", after: "", rank: "zi"},
+		{before: "a", after: "b", rank: "ai"},
+		{before: "a", after: "c", rank: "b"},
+		{before: "az", after: "b", rank: "azi"},
+		{before: "ab", after: "ac", rank: "abi"},
+		{before: "a", after: "a1", rank: "a0i"},
+		{before: "0i", after: "1", rank: "0r"},
+	}
+
+	for _, test := range tests {
+		rank := Between(test.before, test.after)
+		if rank != test.rank {
+			t.Errorf("Between(%q, %q) = %q, want %q", test.before, test.after, rank, test.rank)
+		}
+		ranks := []string{rank}
+		if len(test.before) > 0 {
+			ranks = append([]string{test.before}, ranks...)
+		}
+		if len(test.after) > 0 {
+			ranks = append(ranks, test.after)
+		}
+		checkRanks(t, ranks)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	tests := []struct {
+		count    int
+		maxWidth int
+	}{
+		{count: 0, maxWidth: 0},
+		{count: 1, maxWidth: 1},
+		{count: 17, maxWidth: 1},
+		{count: 18, maxWidth: 2},
+		{count: 100, maxWidth: 2},
+		{count: 647, maxWidth: 2},
+		{count: 648, maxWidth: 3},
+		{count: 5000, maxWidth: 3},
+	}
+
+	for _, test := range tests {
+		ranks := Spread(test.count)
+		if len(ranks) != test.count {
+			t.Fatalf("Spread(%d) returned %d ranks", test.count, len(ranks))
+		}
+		checkRanks(t, ranks)
+		for _, rank := range ranks {
+			if len(rank) > test.maxWidth {
+				t.Errorf("Spread(%d) returned %q, longer than %d", test.count, rank, test.maxWidth)
+			}
+		}
+		// There is room before the first rank and after the last one
+		if test.count > 0 {
+			checkRanks(t, []string{Between("", ranks[0]), ranks[0]})
+			checkRanks(t, []string{ranks[test.count-1], Between(ranks[test.count-1], "")})
+		}
+	}
+}
+
+// Inserts ranks as tasks are moved in a state, spreading the ranks out again once a rank grows longer
+// than the maximum length, as when moving tasks
+func TestInsertions(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   func(random *rand.Rand, count int) int
+		spreads bool // Whether the ranks grow long enough to be spread out again
+	}{
+		{name: "at the start", index: func(random *rand.Rand, count int) int { return 0 }, spreads: true},
+		{name: "at the end", index: func(random *rand.Rand, count int) int { return count }, spreads: true},
+		{name: "after the first", index: func(random *rand.Rand, count int) int {
+			if count == 0 {
+				return 0
+			}
+			return 1
+		}, spreads: true},
+		{name: "before the last", index: func(random *rand.Rand, count int) int {
+			if count == 0 {
+				return 0
+			}
+			return count - 1
+		}, spreads: true},
+		{name: "anywhere", index: func(random *rand.Rand, count int) int { return random.Intn(count + 1) }},
+	}
+
+	for _, test := range tests {
+		random := rand.New(rand.NewSource(1))
+		ranks := []string{}
+		spreads := 0
+		for i := 0; i < 500; i++ {
+			index := test.index(random, len(ranks))
+			before, after := "", ""
+			if index > 0 {
+				before = ranks[index-1]
+			}
+			if index < len(ranks) {
+				after = ranks[index]
+			}
+
+			rank := Between(before, after)
+			ranks = append(ranks[:index], append([]string{rank}, ranks[index:]...)...)
+			if len(rank) > MaxLength {
+				spreads++
+				ranks = Spread(len(ranks))
+			}
+			checkRanks(t, ranks)
+			for _, rank := range ranks {
+				if len(rank) > MaxLength {
+					t.Fatalf("%s: rank %q is longer than %d after %d insertions", test.name, rank, MaxLength, i+1)
+				}
+			}
+		}
+		if test.spreads && spreads == 0 {
+			t.Errorf("%s: the ranks were never spread out again", test.name)
+		}
+	}
+}
@@ -11,17 +11,32 @@ import (
 
 // Generates sample seed data
 func SeedData(user *models.User) (err error) {
+	// Create the personal workspace, unless the user already has one
+	var workspace models.Workspace
+	result := db.DB.Where("user_id = ?", user.ID).Limit(1).Find(&workspace)
+	if result.Error != nil {
+		return
+	}
+	if result.RowsAffected == 0 {
+		workspace = models.NewPersonalWorkspace(user.ID)
+		result = db.DB.Create(&workspace)
+		if result.Error != nil {
+			return
+		}
+	}
+
 	// Create the board
 	board := models.Board{
-		Name:  "My first board",
-		Color: colorTypes.Cyan,
+		Name:        "My first board",
+		Color:       colorTypes.Cyan,
+		WorkspaceID: workspace.ID,
 		Members: []*models.Member{{
 			Role:   roleTypes.Owner,
 			UserID: user.ID,
 		}},
 	}
 
-	result := db.DB.Create(&board)
+	result = db.DB.Create(&board)
 	if result.Error != nil {
 		return
 	}
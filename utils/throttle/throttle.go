@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/EmilyOng/tusk-manager/backend/db"
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	commonUtils "github.com/EmilyOng/tusk-manager/backend/utils/common"
+	"gorm.io/gorm"
+)
+
+type Config struct {
+	MaxAttempts int           // Failures allowed before the key is locked out
+	Window      time.Duration // Failures older than the window are forgotten
+	BaseLockout time.Duration // Lockout after reaching the maximum attempts, doubled on every further failure
+	MaxLockout  time.Duration
+}
+
+// Persists the attempts of each throttled key
+type Store interface {
+	// Returns the attempts of the key, or a zero-valued record when there are none
+	Get(key string) (models.LoginAttempt, error)
+	// Atomically records a failure at the time, after forgetting the failures when the last one is older
+	// than the window, and returns the number of failures
+	Increment(key string, now time.Time, window time.Duration) (int, error)
+	// Locks the key out until the time, unless it is already locked out for longer
+	Lock(key string, until time.Time) error
+	Delete(key string) error
+}
+
+type Throttle struct {
+	Store  Store
+	Config Config
+	Now    func() time.Time // Replaceable clock
+}
+
+// Throttle used by the application, configured by Setup
+var Default = &Throttle{Store: NewMemoryStore(), Config: loadConfig(), Now: time.Now}
+
+func loadConfig() Config {
+	return Config{
+		MaxAttempts: commonUtils.GetEnvInt("THROTTLE_MAX_ATTEMPTS", 5),
+		Window:      commonUtils.GetEnvDuration("THROTTLE_WINDOW", 15*time.Minute),
+		BaseLockout: commonUtils.GetEnvDuration("THROTTLE_BASE_LOCKOUT", 30*time.Second),
+		MaxLockout:  commonUtils.GetEnvDuration("THROTTLE_MAX_LOCKOUT", time.Hour),
+	}
+}
+
+// Configures the throttle from the environment, storing attempts in the database
+// unless 'THROTTLE_STORE' is set to 'memory'
+func Setup() {
+	var store Store = &DatabaseStore{}
+	if commonUtils.GetEnv("THROTTLE_STORE", "database") == "memory" {
+		store = NewMemoryStore()
+	}
+	Default = &Throttle{Store: store, Config: loadConfig(), Now: time.Now}
+}
+
+// Returns how long the caller must wait before trying again, which is zero when none of the keys are locked out
+func (throttle *Throttle) RetryAfter(keys ...string) (retryAfter time.Duration, err error) {
+	now := throttle.Now()
+	for _, key := range keys {
+		attempt, err := throttle.Store.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			if wait := attempt.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	return
+}
+
+// Records a failed attempt against each key, locking out keys with exponential backoff.
+// Failures are counted atomically by the store, so that concurrent attempts are all counted.
+func (throttle *Throttle) Fail(keys ...string) error {
+	now := throttle.Now()
+	for _, key := range keys {
+		failures, err := throttle.Store.Increment(key, now, throttle.Config.Window)
+		if err != nil {
+			return err
+		}
+
+		excess := failures - throttle.Config.MaxAttempts
+		if excess < 0 {
+			continue
+		}
+		lockout := throttle.Config.BaseLockout
+		for i := 0; i < excess && lockout < throttle.Config.MaxLockout; i++ {
+			lockout *= 2
+		}
+		if lockout > throttle.Config.MaxLockout {
+			lockout = throttle.Config.MaxLockout
+		}
+		err = throttle.Store.Lock(key, now.Add(lockout))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clears the attempts of each key
+func (throttle *Throttle) Reset(keys ...string) error {
+	for _, key := range keys {
+		err := throttle.Store.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stores attempts in the database, so that they are shared across instances
+type DatabaseStore struct{}
+
+func (store *DatabaseStore) Get(key string) (attempt models.LoginAttempt, err error) {
+	err = db.DB.Where("key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+func (store *DatabaseStore) Increment(key string, now time.Time, window time.Duration) (failures int, err error) {
+	err = db.DB.Raw(`
+		INSERT INTO login_attempts (key, failures, last_failed_at) VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failed_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING failures`,
+		key, now, now.Add(-window),
+	).Scan(&failures).Error
+	return
+}
+
+func (store *DatabaseStore) Lock(key string, until time.Time) error {
+	return db.DB.Model(&models.LoginAttempt{}).
+		Where("key = ? AND (locked_until IS NULL OR locked_until < ?)", key, until).
+		Update("locked_until", until).
+		Error
+}
+
+func (store *DatabaseStore) Delete(key string) error {
+	return db.DB.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// Stores attempts in memory, for a single instance or for tests
+type MemoryStore struct {
+	mutex    sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: map[string]models.LoginAttempt{}}
+}
+
+func (store *MemoryStore) Get(key string) (models.LoginAttempt, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.attempts[key], nil
+}
+
+func (store *MemoryStore) Increment(key string, now time.Time, window time.Duration) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	attempt := store.attempts[key]
+	if now.Sub(attempt.LastFailedAt) > window {
+		attempt.Failures = 0
+	}
+	attempt.Key = key
+	attempt.Failures++
+	attempt.LastFailedAt = now
+	store.attempts[key] = attempt
+	return attempt.Failures, nil
+}
+
+func (store *MemoryStore) Lock(key string, until time.Time) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	attempt := store.attempts[key]
+	if attempt.LockedUntil == nil || attempt.LockedUntil.Before(until) {
+		attempt.LockedUntil = &until
+	}
+	store.attempts[key] = attempt
+	return nil
+}
+
+func (store *MemoryStore) Delete(key string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.attempts, key)
+	return nil
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// Clock that only moves when advanced
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) Advance(duration time.Duration) {
+	clock.now = clock.now.Add(duration)
+}
+
+func newTestThrottle() (*Throttle, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	throttle := &Throttle{
+		Store: NewMemoryStore(),
+		Config: Config{
+			MaxAttempts: 3,
+			Window:      15 * time.Minute,
+			BaseLockout: 30 * time.Second,
+			MaxLockout:  2 * time.Minute,
+		},
+		Now: clock.Now,
+	}
+	return throttle, clock
+}
+
+func TestFailBacksOffExponentially(t *testing.T) {
+	tests := []struct {
+		failures   int
+		retryAfter time.Duration
+	}{
+		{failures: 1, retryAfter: 0},
+		{failures: 2, retryAfter: 0},
+		{failures: 3, retryAfter: 30 * time.Second},
+		{failures: 4, retryAfter: time.Minute},
+		{failures: 5, retryAfter: 2 * time.Minute},
+		{failures: 6, retryAfter: 2 * time.Minute}, // Capped at the maximum lockout
+		{failures: 10, retryAfter: 2 * time.Minute},
+	}
+
+	for _, test := range tests {
+		throttle, _ := newTestThrottle()
+		for i := 0; i < test.failures; i++ {
+			if err := throttle.Fail("key"); err != nil {
+				t.Fatalf("Fail() returned an error: %v", err)
+			}
+		}
+
+		retryAfter, err := throttle.RetryAfter("key")
+		if err != nil {
+			t.Fatalf("RetryAfter() returned an error: %v", err)
+		}
+		if retryAfter != test.retryAfter {
+			t.Errorf("after %d failures, RetryAfter() = %v, want %v", test.failures, retryAfter, test.retryAfter)
+		}
+	}
+}
+
+func TestLockoutExpires(t *testing.T) {
+	tests := []struct {
+		elapsed    time.Duration
+		retryAfter time.Duration
+	}{
+		{elapsed: 0, retryAfter: 30 * time.Second},
+		{elapsed: 10 * time.Second, retryAfter: 20 * time.Second},
+		{elapsed: 29 * time.Second, retryAfter: time.Second},
+		{elapsed: 30 * time.Second, retryAfter: 0},
+		{elapsed: time.Minute, retryAfter: 0},
+	}
+
+	for _, test := range tests {
+		throttle, clock := newTestThrottle()
+		for i := 0; i < throttle.Config.MaxAttempts; i++ {
+			if err := throttle.Fail("key"); err != nil {
+				t.Fatalf("Fail() returned an error: %v", err)
+			}
+		}
+
+		clock.Advance(test.elapsed)
+		retryAfter, err := throttle.RetryAfter("key")
+		if err != nil {
+			t.Fatalf("RetryAfter() returned an error: %v", err)
+		}
+		if retryAfter != test.retryAfter {
+			t.Errorf("after %v, RetryAfter() = %v, want %v", test.elapsed, retryAfter, test.retryAfter)
+		}
+	}
+}
+
+func TestFailuresOutsideTheWindowAreForgotten(t *testing.T) {
+	tests := []struct {
+		name       string
+		gap        time.Duration // Between the first failures and the last failure
+		retryAfter time.Duration
+	}{
+		{name: "within the window", gap: 15 * time.Minute, retryAfter: 30 * time.Second},
+		{name: "after the window", gap: 15*time.Minute + time.Second, retryAfter: 0},
+	}
+
+	for _, test := range tests {
+		throttle, clock := newTestThrottle()
+		for i := 0; i < throttle.Config.MaxAttempts-1; i++ {
+			if err := throttle.Fail("key"); err != nil {
+				t.Fatalf("Fail() returned an error: %v", err)
+			}
+		}
+		clock.Advance(test.gap)
+		if err := throttle.Fail("key"); err != nil {
+			t.Fatalf("Fail() returned an error: %v", err)
+		}
+
+		retryAfter, err := throttle.RetryAfter("key")
+		if err != nil {
+			t.Fatalf("RetryAfter() returned an error: %v", err)
+		}
+		if retryAfter != test.retryAfter {
+			t.Errorf("%s: RetryAfter() = %v, want %v", test.name, retryAfter, test.retryAfter)
+		}
+	}
+}
+
+func TestRetryAfterIsTheLongestLockout(t *testing.T) {
+	throttle, _ := newTestThrottle()
+	for i := 0; i < 4; i++ {
+		if err := throttle.Fail("account"); err != nil {
+			t.Fatalf("Fail() returned an error: %v", err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := throttle.Fail("client"); err != nil {
+			t.Fatalf("Fail() returned an error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		keys       []string
+		retryAfter time.Duration
+	}{
+		{keys: []string{"account", "client"}, retryAfter: time.Minute},
+		{keys: []string{"client"}, retryAfter: 30 * time.Second},
+		{keys: []string{"other"}, retryAfter: 0},
+		{keys: []string{"other", "client"}, retryAfter: 30 * time.Second},
+	}
+	for _, test := range tests {
+		retryAfter, err := throttle.RetryAfter(test.keys...)
+		if err != nil {
+			t.Fatalf("RetryAfter() returned an error: %v", err)
+		}
+		if retryAfter != test.retryAfter {
+			t.Errorf("RetryAfter(%v) = %v, want %v", test.keys, retryAfter, test.retryAfter)
+		}
+	}
+}
+
+func TestResetClearsTheLockout(t *testing.T) {
+	throttle, _ := newTestThrottle()
+	for i := 0; i < throttle.Config.MaxAttempts; i++ {
+		if err := throttle.Fail("key"); err != nil {
+			t.Fatalf("Fail() returned an error: %v", err)
+		}
+	}
+	if err := throttle.Reset("key"); err != nil {
+		t.Fatalf("Reset() returned an error: %v", err)
+	}
+
+	retryAfter, err := throttle.RetryAfter("key")
+	if err != nil {
+		t.Fatalf("RetryAfter() returned an error: %v", err)
+	}
+	if retryAfter != 0 {
+		t.Errorf("after Reset(), RetryAfter() = %v, want 0", retryAfter)
+	}
+}
+
+func TestConcurrentFailuresAreAllCounted(t *testing.T) {
+	throttle, _ := newTestThrottle()
+	const failures = 50
+
+	var wait sync.WaitGroup
+	for i := 0; i < failures; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if err := throttle.Fail("key"); err != nil {
+				t.Errorf("Fail() returned an error: %v", err)
+			}
+		}()
+	}
+	wait.Wait()
+
+	attempt, err := throttle.Store.Get("key")
+	if err != nil {
+		t.Fatalf("Get() returned an error: %v", err)
+	}
+	if attempt.Failures != failures {
+		t.Errorf("Failures = %d, want %d", attempt.Failures, failures)
+	}
+}
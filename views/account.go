@@ -0,0 +1,113 @@
+package views
+
+import (
+	"time"
+
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+)
+
+type AccountProfileView struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	EmailVerified   bool       `json:"emailVerified"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	PendingEmail    string     `json:"pendingEmail"`
+	TOTPEnabled     bool       `json:"totpEnabled"`
+}
+
+type AccountMembershipView struct {
+	ID    string           `json:"id"`
+	Role  roleTypes.Role   `json:"role" ts_type:"Role"`
+	Board BoardMinimalView `json:"board"`
+}
+
+type AccountBoardView struct {
+	BoardMinimalView
+	States []StateMinimalView `json:"states"`
+	Tags   []TagMinimalView   `json:"tags"`
+}
+
+type AccountTaskView struct {
+	TaskMinimalView
+	BoardID string   `json:"boardId"`
+	StateID string   `json:"stateId"`
+	TagIDs  []string `json:"tagIds"`
+}
+
+type AccountIdentityView struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+}
+
+// Personal data of the user, without any credentials
+type AccountExportView struct {
+	ExportedAt  time.Time                 `json:"exportedAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	Profile     AccountProfileView        `json:"profile"`
+	Memberships []AccountMembershipView   `json:"memberships"`
+	Workspaces  []UserWorkspaceView       `json:"workspaces"`
+	Boards      []AccountBoardView        `json:"boards"` // Boards that the user owns
+	Tasks       []AccountTaskView         `json:"tasks"`  // Tasks that the user created
+	Tokens      []PersonalAccessTokenView `json:"tokens"`
+	Identities  []AccountIdentityView     `json:"identities"`
+}
+
+// Export Account
+type ExportAccountResponse struct {
+	Response
+	Export AccountExportView `json:"data"`
+}
+
+// Delete Account
+type DeleteAccountPayload struct {
+	Email             string `json:"email"`             // Confirms the account to delete
+	Password          string `json:"password"`          // Not required from users without a password, who must have logged in recently
+	DeleteOwnedBoards bool   `json:"deleteOwnedBoards"` // Deletes the boards that the user solely owns, instead of requiring a transfer first
+}
+
+type DeleteAccountResponse struct {
+	Response
+	OwnedBoards []BoardMinimalView `json:"data"` // Boards that the user solely owns, when they prevent the deletion
+}
+
+// Update Profile, which returns a new access token with the updated name
+type UpdateProfilePayload struct {
+	Name string `json:"name"`
+}
+
+type UpdateProfileResponse struct {
+	Response
+	User AuthUserView `json:"data"`
+}
+
+// Change Password, which logs out every other session
+type ChangePasswordPayload struct {
+	CurrentPassword string `json:"currentPassword"` // Not required from users without a password, who must have logged in recently
+	NewPassword     string `json:"newPassword"`
+}
+
+type ChangePasswordResponse struct {
+	Response
+	User AuthUserView `json:"data"` // Tokens of the new session
+}
+
+// Change Email, which takes effect once the new email is verified
+type ChangeEmailPayload struct {
+	Email    string `json:"email"`
+	Password string `json:"password"` // Not required from users without a password, who must have logged in recently
+}
+
+type ChangeEmailResponse struct {
+	Response
+}
+
+// Confirm Email Change, which logs out every session
+type ConfirmEmailChangePayload struct {
+	Token string `json:"token"`
+}
+
+type ConfirmEmailChangeResponse struct {
+	Response
+}
@@ -0,0 +1,45 @@
+package views
+
+import (
+	"github.com/EmilyOng/tusk-manager/backend/models"
+	auditTypes "github.com/EmilyOng/tusk-manager/backend/types/audit"
+)
+
+type AuditEventView = models.AuditEvent
+
+type AuditEventPageView struct {
+	Events   []AuditEventView `json:"events"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"pageSize"`
+	Total    int64            `json:"total"`
+}
+
+// Get Audit Events
+type GetAuditEventsPayload struct {
+	Action   auditTypes.Action `form:"action" json:"action"`
+	ActorID  string            `form:"actorId" json:"actorId"`
+	TargetID string            `form:"targetId" json:"targetId"`
+	BoardID  string            `form:"boardId" json:"boardId"`
+	From     string            `form:"from" json:"from"` // Inclusive, in the datetime layout
+	To       string            `form:"to" json:"to"`     // Exclusive, in the datetime layout
+	Page     int               `form:"page" json:"page"` // Starting from 1
+	PageSize int               `form:"pageSize" json:"pageSize"`
+}
+
+type GetAuditEventsResponse struct {
+	Response
+	Page AuditEventPageView `json:"data"`
+}
+
+// Verify Audit Log
+type AuditVerificationView struct {
+	Valid    bool    `json:"valid"`
+	Checked  int64   `json:"checked"`  // Number of entries checked
+	BrokenAt *int64  `json:"brokenAt"` // Sequence of the first entry that does not match its chain
+	Chain    *string `json:"chain"`    // Chain of that entry
+}
+
+type VerifyAuditLogResponse struct {
+	Response
+	Verification AuditVerificationView `json:"data"`
+}
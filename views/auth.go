@@ -1,10 +1,11 @@
 package views
 
 type AuthUserView struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Token string `json:"token"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
 }
 
 // Authentication
@@ -22,6 +23,16 @@ type LoginPayload struct {
 type LoginResponse struct {
 	Response
 	User AuthUserView `json:"data"`
+
+	// Set when two-factor authentication is enabled, and the challenge token must be exchanged for the user
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+}
+
+// Login with Two-Factor Authentication
+type LoginTwoFactorPayload struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"` // Either a TOTP code or a recovery code
 }
 
 // Sign Up
@@ -35,3 +46,104 @@ type SignUpResponse struct {
 	Response
 	User AuthUserView `json:"data"`
 }
+
+// Refresh
+type RefreshPayload struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshResponse struct {
+	Response
+	User AuthUserView `json:"data"`
+}
+
+// Forgot Password
+type ForgotPasswordPayload struct {
+	Email string `json:"email"`
+}
+
+type ForgotPasswordResponse struct {
+	Response
+}
+
+// Reset Password
+type ResetPasswordPayload struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ResetPasswordResponse struct {
+	Response
+}
+
+// Verify Email
+type VerifyEmailPayload struct {
+	Token string `json:"token"`
+}
+
+type VerifyEmailResponse struct {
+	Response
+}
+
+// Resend Verification
+type ResendVerificationPayload struct {
+	Email string `json:"email"`
+}
+
+type ResendVerificationResponse struct {
+	Response
+}
+
+// OpenID Connect Login
+type OIDCLoginResponse struct {
+	Response
+	AuthorizationURL string `json:"data"`
+	State            string `json:"-"` // Kept in a cookie, so that the login can only be completed by the same browser
+}
+
+// OpenID Connect Callback
+type OIDCCallbackPayload struct {
+	Code        string `json:"code"`
+	State       string `json:"state"`
+	CookieState string `json:"-"` // State kept in the browser when the login started
+}
+
+type OIDCCallbackResponse struct {
+	Response
+	User AuthUserView `json:"data"`
+
+	// Set when two-factor authentication is enabled, as for logins with a password
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+}
+
+// Enroll Two-Factor Authentication
+type TwoFactorEnrollmentView struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth URI, typically shown as a QR code
+}
+
+type EnrollTwoFactorResponse struct {
+	Response
+	Enrollment TwoFactorEnrollmentView `json:"data"`
+}
+
+// Confirm Two-Factor Authentication
+type ConfirmTwoFactorPayload struct {
+	Code string `json:"code"`
+}
+
+type ConfirmTwoFactorResponse struct {
+	Response
+	RecoveryCodes []string `json:"data"` // Only returned once
+}
+
+// Disable Two-Factor Authentication
+type DisableTwoFactorPayload struct {
+	Password string `json:"password"`
+	Code     string `json:"code"` // Either a TOTP code or a recovery code
+}
+
+type DisableTwoFactorResponse struct {
+	Response
+}
@@ -10,6 +10,8 @@ type BoardMinimalView struct {
 	ID    string           `json:"id"`
 	Name  string           `json:"name"`
 	Color colorTypes.Color `json:"color" ts_type:"Color"`
+
+	WorkspaceID string `json:"workspaceId"` // Workspace that the board belongs to
 }
 
 type BoardFullView = models.Board
@@ -26,9 +28,10 @@ type GetBoardResponse struct {
 
 // Create Board
 type CreateBoardPayload struct {
-	Name   string           `json:"name"`
-	Color  colorTypes.Color `json:"color" ts_type:"Color"`
-	UserID string           `json:"userId"`
+	Name        string           `json:"name"`
+	Color       colorTypes.Color `json:"color" ts_type:"Color"`
+	UserID      string           `json:"userId"`      // Optional, must match the authenticated user
+	WorkspaceID string           `json:"workspaceId"` // Optional, defaults to the personal workspace of the user
 }
 
 type CreateBoardResponse struct {
@@ -41,7 +44,7 @@ type UpdateBoardPayload struct {
 	ID     string           `json:"id"`
 	Name   string           `json:"name"`
 	Color  colorTypes.Color `json:"color" ts_type:"Color"`
-	UserID string           `json:"userId"`
+	UserID string           `json:"userId"` // Optional, must match the authenticated user
 }
 
 type UpdateBoardResponse struct {
@@ -76,7 +79,7 @@ type GetBoardMemberProfilesPayload struct {
 
 type GetBoardMemberProfilesResponse struct {
 	Response
-	Members []MemberFullView `json:"data"`
+	Members []MemberProfileView `json:"data"`
 }
 
 // Get Board States
@@ -0,0 +1,86 @@
+package views
+
+import (
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+)
+
+type CustomRoleView struct {
+	ID          string                       `json:"id"`
+	Name        string                       `json:"name"`
+	Permissions []permissionTypes.Permission `json:"permissions" ts_type:"Permission[]"`
+	WorkspaceID *string                      `json:"workspaceId"` // Workspace whose boards can assign the role, if any
+	BoardID     *string                      `json:"boardId"`     // Board that can assign the role, if any
+}
+
+// Permissions of a built-in role
+type PermissionPresetView struct {
+	Role        roleTypes.Role               `json:"role" ts_type:"Role"`
+	Permissions []permissionTypes.Permission `json:"permissions" ts_type:"Permission[]"`
+}
+
+type PermissionCatalogueView struct {
+	Permissions []permissionTypes.Permission `json:"permissions" ts_type:"Permission[]"`
+	Presets     []PermissionPresetView       `json:"presets"`
+}
+
+// Get Permission Catalogue
+type GetPermissionCatalogueResponse struct {
+	Response
+	Catalogue PermissionCatalogueView `json:"data"`
+}
+
+// Get Board Permissions, which the authenticated user holds on the board
+type GetBoardPermissionsResponse struct {
+	Response
+	Permissions []permissionTypes.Permission `json:"data" ts_type:"Permission[]"`
+}
+
+// Get Custom Roles, of a workspace or of a board together with the roles of its workspace
+type GetCustomRolesPayload struct {
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+}
+
+type GetCustomRolesResponse struct {
+	Response
+	Roles []CustomRoleView `json:"data"`
+}
+
+// Create Custom Role, for either a workspace or a board
+type CreateCustomRolePayload struct {
+	Name        string                       `json:"name"`
+	Permissions []permissionTypes.Permission `json:"permissions" ts_type:"Permission[]"`
+	WorkspaceID string                       `json:"workspaceId"`
+	BoardID     string                       `json:"boardId"`
+}
+
+type CreateCustomRoleResponse struct {
+	Response
+	Role CustomRoleView `json:"data"`
+}
+
+// Update Custom Role, which changes the permissions of everyone holding it
+type UpdateCustomRolePayload struct {
+	ID          string                       `json:"id"`
+	Name        string                       `json:"name"`
+	Permissions []permissionTypes.Permission `json:"permissions" ts_type:"Permission[]"`
+	WorkspaceID string                       `json:"workspaceId"`
+	BoardID     string                       `json:"boardId"`
+}
+
+type UpdateCustomRoleResponse struct {
+	Response
+	Role CustomRoleView `json:"data"`
+}
+
+// Delete Custom Role, which is removed from everyone holding it
+type DeleteCustomRolePayload struct {
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+}
+
+type DeleteCustomRoleResponse struct {
+	Response
+}
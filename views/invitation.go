@@ -0,0 +1,34 @@
+package views
+
+import "github.com/EmilyOng/tusk-manager/backend/models"
+
+type InvitationView = models.Invitation
+
+// Get Board Invitations
+type GetBoardInvitationsPayload struct {
+	BoardID string `json:"boardId"`
+}
+
+type GetBoardInvitationsResponse struct {
+	Response
+	Invitations []InvitationView `json:"data"`
+}
+
+// Accept Invitation
+type AcceptInvitationPayload struct {
+	Token string `json:"token"` // Sent to the invitee by email
+}
+
+type AcceptInvitationResponse struct {
+	Response
+	Member MemberMinimalView `json:"data"`
+}
+
+// Decline Invitation
+type DeclineInvitationPayload struct {
+	Token string `json:"token"`
+}
+
+type DeclineInvitationResponse struct {
+	Response
+}
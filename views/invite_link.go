@@ -0,0 +1,62 @@
+package views
+
+import (
+	"time"
+
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+)
+
+type InviteLinkView struct {
+	ID        string         `json:"id"`
+	Role      roleTypes.Role `json:"role" ts_type:"Role"`
+	ExpiresAt time.Time      `json:"expiresAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	MaxUses   *int           `json:"maxUses"`
+	Uses      int            `json:"uses"`
+	RevokedAt *time.Time     `json:"revokedAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	CreatedAt time.Time      `json:"createdAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	BoardID   string         `json:"boardId"`
+	URL       string         `json:"url,omitempty"` // Only returned once, when the link is created
+}
+
+// Get Invite Links
+type GetInviteLinksPayload struct {
+	BoardID string `json:"boardId"`
+}
+
+type GetInviteLinksResponse struct {
+	Response
+	InviteLinks []InviteLinkView `json:"data"`
+}
+
+// Create Invite Link
+type CreateInviteLinkPayload struct {
+	BoardID   string         `json:"boardId"`
+	Role      roleTypes.Role `json:"role" ts_type:"Role"`
+	ExpiresAt string         `json:"expiresAt,omitempty" ts_type:"Date" ts_transform:"new Date(__VALUE__)"` // Defaults to 7 days
+	MaxUses   *int           `json:"maxUses"`                                                               // Unlimited when empty
+}
+
+type CreateInviteLinkResponse struct {
+	Response
+	InviteLink InviteLinkView `json:"data"`
+}
+
+// Revoke Invite Link
+type RevokeInviteLinkPayload struct {
+	ID      string `json:"id"`
+	BoardID string `json:"boardId"`
+}
+
+type RevokeInviteLinkResponse struct {
+	Response
+}
+
+// Redeem Invite Link
+type RedeemInviteLinkPayload struct {
+	Token string `json:"token"`
+}
+
+type RedeemInviteLinkResponse struct {
+	Response
+	Member MemberMinimalView `json:"data"`
+}
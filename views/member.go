@@ -1,6 +1,7 @@
 package views
 
 import (
+	permissionTypes "github.com/EmilyOng/tusk-manager/backend/types/permission"
 	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
 )
 
@@ -8,32 +9,51 @@ type MemberMinimalView struct {
 	ID   string         `json:"id"`
 	Role roleTypes.Role `json:"role" ts_type:"Role"`
 
-	UserID  string `json:"userId"`  // User ID of the board member
-	BoardID string `json:"boardId"` // Board that the member belongs to
+	UserID       string  `json:"userId"`       // User ID of the board member
+	BoardID      string  `json:"boardId"`      // Board that the member belongs to
+	CustomRoleID *string `json:"customRoleId"` // Grants additional permissions, if any
 }
 
 type MemberFullView struct {
-	ID   string          `json:"id"`
-	Role roleTypes.Role  `json:"role" ts_type:"Role"`
-	User UserMinimalView `json:"user"`
+	ID           string          `json:"id"`
+	Role         roleTypes.Role  `json:"role" ts_type:"Role"`
+	CustomRoleID *string         `json:"customRoleId"` // Grants additional permissions, if any
+	User         UserMinimalView `json:"user"`
+}
+
+// Access that the user holds on the board, directly or through a team
+type MemberAccessView struct {
+	Role         roleTypes.Role   `json:"role" ts_type:"Role"`
+	CustomRoleID *string          `json:"customRoleId"`
+	Team         *TeamMinimalView `json:"team"` // Team that grants the role, empty for direct access
+}
+
+// User with access to the board, where the role is the highest of the direct and team roles
+type MemberProfileView struct {
+	MemberFullView                              // Identified by the direct membership, empty when the access is only through teams
+	Access         []MemberAccessView           `json:"access"`
+	Permissions    []permissionTypes.Permission `json:"permissions" ts_type:"Permission[]"` // Permissions that the user holds through every access
 }
 
 // Create Member
 type CreateMemberPayload struct {
-	Role    roleTypes.Role `json:"role" ts_type:"Role"`
-	Email   string         `json:"email"` // Invitation is by email
-	BoardID string         `json:"boardId"`
+	Role         roleTypes.Role `json:"role" ts_type:"Role"`
+	CustomRoleID *string        `json:"customRoleId,omitempty"` // Role of the board or its workspace, only for registered users
+	Email        string         `json:"email"`                  // Invitation is by email
+	BoardID      string         `json:"boardId"`
 }
 
 type CreateMemberResponse struct {
 	Response
-	Member MemberFullView `json:"data"`
+	Member     MemberFullView  `json:"data"`
+	Invitation *InvitationView `json:"invitation,omitempty"` // Sent instead, when the invitee does not have an account yet
 }
 
 // Update Member
 type UpdateMemberPayload struct {
-	ID   string         `json:"id"`
-	Role roleTypes.Role `json:"role" ts_type:"Role"`
+	ID           string         `json:"id"`
+	Role         roleTypes.Role `json:"role" ts_type:"Role"`
+	CustomRoleID *string        `json:"customRoleId"` // Role of the board or its workspace, removed if empty
 }
 
 type UpdateMemberResponse struct {
@@ -49,3 +69,26 @@ type DeleteMemberPayload struct {
 type DeleteMemberResponse struct {
 	Response
 }
+
+// Transfer Ownership
+type TransferOwnershipPayload struct {
+	BoardID  string         `json:"boardId"`
+	MemberID string         `json:"memberId"`                      // Member that becomes an owner
+	Role     roleTypes.Role `json:"role,omitempty" ts_type:"Role"` // Role that the current owner keeps, defaults to Editor
+}
+
+type TransferOwnershipResponse struct {
+	Response
+	Member MemberFullView `json:"data"` // New owner
+}
+
+// Leave Board
+type LeaveBoardPayload struct {
+	BoardID     string `json:"boardId"`
+	SuccessorID string `json:"successorId,omitempty"` // Member that becomes an owner, required to leave as the last owner
+	DeleteBoard bool   `json:"deleteBoard"`           // Deletes the board instead, when leaving as the last owner
+}
+
+type LeaveBoardResponse struct {
+	Response
+}
@@ -0,0 +1,82 @@
+package views
+
+import (
+	"time"
+
+	colorTypes "github.com/EmilyOng/tusk-manager/backend/types/color"
+)
+
+type BoardPublicationView struct {
+	BoardID       string    `json:"boardId"`
+	HiddenTagIDs  []string  `json:"hiddenTagIds"`
+	HiddenTaskIDs []string  `json:"hiddenTaskIds"`
+	PublishedAt   time.Time `json:"publishedAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	URL           string    `json:"url,omitempty"` // Only returned once, when the board is published
+}
+
+// Get Board Publication
+type GetBoardPublicationPayload struct {
+	BoardID string `json:"boardId"`
+}
+
+type GetBoardPublicationResponse struct {
+	Response
+	Publication *BoardPublicationView `json:"data"` // Empty when the board is not published
+}
+
+// Publish Board, which replaces the token of a published board
+type PublishBoardPayload struct {
+	BoardID       string   `json:"boardId"`
+	HiddenTagIDs  []string `json:"hiddenTagIds"`
+	HiddenTaskIDs []string `json:"hiddenTaskIds"`
+}
+
+type PublishBoardResponse struct {
+	Response
+	Publication BoardPublicationView `json:"data"`
+}
+
+// Update Board Publication, which keeps the token
+type UpdateBoardPublicationPayload struct {
+	BoardID       string   `json:"boardId"`
+	HiddenTagIDs  []string `json:"hiddenTagIds"`
+	HiddenTaskIDs []string `json:"hiddenTaskIds"`
+}
+
+type UpdateBoardPublicationResponse struct {
+	Response
+	Publication BoardPublicationView `json:"data"`
+}
+
+// Unpublish Board
+type UnpublishBoardPayload struct {
+	BoardID string `json:"boardId"`
+}
+
+type UnpublishBoardResponse struct {
+	Response
+}
+
+// Get Public Board, which leaves out members and other private fields
+type PublicTaskView struct {
+	TaskMinimalView
+	StateID string   `json:"stateId"`
+	TagIDs  []string `json:"tagIds"`
+}
+
+type PublicBoardView struct {
+	Name   string             `json:"name"`
+	Color  colorTypes.Color   `json:"color" ts_type:"Color"`
+	States []StateMinimalView `json:"states"`
+	Tasks  []PublicTaskView   `json:"tasks"`
+	Tags   []TagMinimalView   `json:"tags"`
+}
+
+type GetPublicBoardPayload struct {
+	Token string `json:"token"`
+}
+
+type GetPublicBoardResponse struct {
+	Response
+	Board PublicBoardView `json:"data"`
+}
@@ -39,9 +39,28 @@ type UpdateStateResponse struct {
 
 // Delete State
 type DeleteStatePayload struct {
-	ID string `json:"id"`
+	ID            string `form:"-" json:"id"`
+	TargetStateID string `form:"targetStateId" json:"targetStateId"` // Optional, defaults to the first remaining state of the board
+}
+
+// Where the tasks of the deleted state were moved to
+type DeleteStateView struct {
+	TargetStateID string `json:"targetStateId"`
+	MovedTasks    int    `json:"movedTasks"`
 }
 
 type DeleteStateResponse struct {
 	Response
+	Result DeleteStateView `json:"data"`
+}
+
+// Reorder States
+type ReorderStatesPayload struct {
+	BoardID  string   `json:"boardId"`
+	StateIDs []string `json:"stateIds"` // Every state of the board, in the new order
+}
+
+type ReorderStatesResponse struct {
+	Response
+	States []StateMinimalView `json:"data"`
 }
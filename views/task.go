@@ -24,7 +24,7 @@ type CreateTaskPayload struct {
 	StateID string           `json:"stateId"`
 	Tags    []TagMinimalView `json:"tags"`
 	BoardID string           `json:"boardId"`
-	UserID  string           `json:"userId"`
+	UserID  string           `json:"userId"` // Optional, must match the authenticated user
 }
 
 type CreateTaskResponse struct {
@@ -40,9 +40,9 @@ type UpdateTaskPayload struct {
 	DueAt       string `json:"dueAt,omitempty" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
 
 	StateID string           `json:"stateId"`
-	Tags    []TagMinimalView `json:"tags"`
-	BoardID string           `json:"boardId"`
-	UserID  string           `json:"userId"`
+	Tags    []TagMinimalView `json:"tags"`    // Existing tags of the board, or new tags without an ID if the actor can manage tags
+	BoardID string           `json:"boardId"` // Optional, must match the board of the task
+	UserID  string           `json:"userId"`  // Optional, must match the creator of the task
 }
 
 type UpdateTaskResponse struct {
@@ -58,3 +58,37 @@ type DeleteTaskPayload struct {
 type DeleteTaskResponse struct {
 	Response
 }
+
+// Move Task
+type MoveTaskPayload struct {
+	ID          string `json:"id"`
+	StateID     string `json:"stateId"`     // State to move the task into, which can be its current state
+	AfterTaskID string `json:"afterTaskId"` // Task in the state to place it after, or empty to place it first
+}
+
+type MoveTaskResponse struct {
+	Response
+	Task TaskFullView `json:"data"`
+}
+
+// Assign Task
+type AssignTaskPayload struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"` // Member of the board of the task
+}
+
+type AssignTaskResponse struct {
+	Response
+	Task TaskFullView `json:"data"`
+}
+
+// Unassign Task
+type UnassignTaskPayload struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"`
+}
+
+type UnassignTaskResponse struct {
+	Response
+	Task TaskFullView `json:"data"`
+}
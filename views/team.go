@@ -0,0 +1,136 @@
+package views
+
+import (
+	roleTypes "github.com/EmilyOng/tusk-manager/backend/types/role"
+)
+
+type TeamMinimalView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type TeamView struct {
+	TeamMinimalView
+	Members []UserMinimalView `json:"members"`
+}
+
+// Access of a team to a board
+type BoardTeamView struct {
+	ID           string          `json:"id"`
+	Role         roleTypes.Role  `json:"role" ts_type:"Role"`
+	CustomRoleID *string         `json:"customRoleId"` // Grants additional permissions, if any
+	Team         TeamMinimalView `json:"team"`
+}
+
+// Get Workspace Teams
+type GetWorkspaceTeamsPayload struct {
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type GetWorkspaceTeamsResponse struct {
+	Response
+	Teams []TeamView `json:"data"`
+}
+
+// Create Team
+type CreateTeamPayload struct {
+	WorkspaceID string `json:"workspaceId"`
+	Name        string `json:"name"`
+}
+
+type CreateTeamResponse struct {
+	Response
+	Team TeamView `json:"data"`
+}
+
+// Update Team
+type UpdateTeamPayload struct {
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspaceId"`
+	Name        string `json:"name"`
+}
+
+type UpdateTeamResponse struct {
+	Response
+	Team TeamView `json:"data"`
+}
+
+// Delete Team, which revokes the access of the team to every board
+type DeleteTeamPayload struct {
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type DeleteTeamResponse struct {
+	Response
+}
+
+// Create Team Member, who must be a member of the workspace
+type CreateTeamMemberPayload struct {
+	TeamID      string `json:"teamId"`
+	WorkspaceID string `json:"workspaceId"`
+	Email       string `json:"email"`
+}
+
+type CreateTeamMemberResponse struct {
+	Response
+	Team TeamView `json:"data"`
+}
+
+// Delete Team Member, who immediately loses the access granted through the team
+type DeleteTeamMemberPayload struct {
+	TeamID      string `json:"teamId"`
+	WorkspaceID string `json:"workspaceId"`
+	UserID      string `json:"userId"`
+}
+
+type DeleteTeamMemberResponse struct {
+	Response
+	Team TeamView `json:"data"`
+}
+
+// Get Board Teams
+type GetBoardTeamsPayload struct {
+	BoardID string `json:"boardId"`
+}
+
+type GetBoardTeamsResponse struct {
+	Response
+	Teams []BoardTeamView `json:"data"`
+}
+
+// Create Board Team, from the teams of the board's workspace
+type CreateBoardTeamPayload struct {
+	BoardID      string         `json:"boardId"`
+	TeamID       string         `json:"teamId"`
+	Role         roleTypes.Role `json:"role" ts_type:"Role"`
+	CustomRoleID *string        `json:"customRoleId,omitempty"` // Role of the board or its workspace
+}
+
+type CreateBoardTeamResponse struct {
+	Response
+	Team BoardTeamView `json:"data"`
+}
+
+// Update Board Team
+type UpdateBoardTeamPayload struct {
+	ID           string         `json:"id"`
+	BoardID      string         `json:"boardId"`
+	Role         roleTypes.Role `json:"role" ts_type:"Role"`
+	CustomRoleID *string        `json:"customRoleId"` // Role of the board or its workspace, removed if empty
+}
+
+type UpdateBoardTeamResponse struct {
+	Response
+	Team BoardTeamView `json:"data"`
+}
+
+// Delete Board Team
+type DeleteBoardTeamPayload struct {
+	ID      string `json:"id"`
+	BoardID string `json:"boardId"`
+}
+
+type DeleteBoardTeamResponse struct {
+	Response
+}
@@ -0,0 +1,46 @@
+package views
+
+import (
+	"time"
+
+	scopeTypes "github.com/EmilyOng/tusk-manager/backend/types/scope"
+)
+
+type PersonalAccessTokenView struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Scopes     []scopeTypes.Scope `json:"scopes" ts_type:"Scope[]"`
+	BoardID    *string            `json:"boardId"`
+	ExpiresAt  *time.Time         `json:"expiresAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	LastUsedAt *time.Time         `json:"lastUsedAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	CreatedAt  time.Time          `json:"createdAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+	Token      string             `json:"token,omitempty"` // Only returned once, when the token is created
+}
+
+// Get Personal Access Tokens
+type GetPersonalAccessTokensResponse struct {
+	Response
+	Tokens []PersonalAccessTokenView `json:"data"`
+}
+
+// Create Personal Access Token
+type CreatePersonalAccessTokenPayload struct {
+	Name      string             `json:"name"`
+	Scopes    []scopeTypes.Scope `json:"scopes" ts_type:"Scope[]"`
+	BoardID   *string            `json:"boardId"`
+	ExpiresAt string             `json:"expiresAt,omitempty" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+}
+
+type CreatePersonalAccessTokenResponse struct {
+	Response
+	Token PersonalAccessTokenView `json:"data"`
+}
+
+// Revoke Personal Access Token
+type RevokePersonalAccessTokenPayload struct {
+	ID string `json:"id"`
+}
+
+type RevokePersonalAccessTokenResponse struct {
+	Response
+}
@@ -0,0 +1,70 @@
+package views
+
+import (
+	"time"
+
+	trashTypes "github.com/EmilyOng/tusk-manager/backend/types/trash"
+)
+
+// Task, tag or state in the trash of a board
+type TrashItemView struct {
+	ID        string          `json:"id"`
+	Type      trashTypes.Item `json:"type" ts_type:"TrashItem"`
+	Name      string          `json:"name"`
+	DeletedAt time.Time       `json:"deletedAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+}
+
+type TrashedBoardView struct {
+	BoardMinimalView
+	DeletedAt time.Time `json:"deletedAt" ts_type:"Date" ts_transform:"new Date(__VALUE__)"`
+}
+
+// Get Board Trash
+type GetBoardTrashPayload struct {
+	BoardID string `json:"boardId"`
+}
+
+type GetBoardTrashResponse struct {
+	Response
+	Items []TrashItemView `json:"data"` // Most recently deleted first
+}
+
+// Restore Trash Item
+type RestoreTrashItemPayload struct {
+	BoardID string          `json:"boardId"`
+	Type    trashTypes.Item `json:"type" ts_type:"TrashItem"`
+	ID      string          `json:"id"`
+}
+
+type RestoreTrashItemResponse struct {
+	Response
+}
+
+// Get Trashed Boards, which the user can restore
+type GetTrashedBoardsPayload struct {
+	UserID string `json:"userId"`
+}
+
+type GetTrashedBoardsResponse struct {
+	Response
+	Boards []TrashedBoardView `json:"data"` // Most recently deleted first
+}
+
+// Restore Board
+type RestoreBoardPayload struct {
+	ID string `json:"id"`
+}
+
+type RestoreBoardResponse struct {
+	Response
+	Board BoardMinimalView `json:"data"`
+}
+
+// Purge Board, which permanently deletes a board in the trash
+type PurgeBoardPayload struct {
+	ID string `json:"id"`
+}
+
+type PurgeBoardResponse struct {
+	Response
+}
@@ -3,12 +3,15 @@ package views
 import "github.com/EmilyOng/tusk-manager/backend/models"
 
 type GetUserBoardsPayload struct {
-	UserID string `json:"userId"`
+	UserID           string `form:"-" json:"userId"`
+	WorkspaceID      string `form:"workspaceId" json:"workspaceId"`           // Only lists the boards of the workspace
+	GroupByWorkspace bool   `form:"groupByWorkspace" json:"groupByWorkspace"` // Also lists the boards by workspace
 }
 
 type GetUserBoardsResponse struct {
 	Response
-	Boards []BoardMinimalView `json:"data"`
+	Boards     []BoardMinimalView    `json:"data"`
+	Workspaces []WorkspaceBoardsView `json:"workspaces,omitempty"`
 }
 
 type UserMinimalView struct {
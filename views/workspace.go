@@ -0,0 +1,132 @@
+package views
+
+import (
+	workspaceTypes "github.com/EmilyOng/tusk-manager/backend/types/workspace"
+)
+
+type WorkspaceMinimalView struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Personal bool   `json:"personal"` // Personal workspaces hold the boards of a single user
+}
+
+// Workspace of the user, with the role that the user holds in it
+type UserWorkspaceView struct {
+	WorkspaceMinimalView
+	Role workspaceTypes.Role `json:"role" ts_type:"WorkspaceRole"`
+}
+
+type WorkspaceBoardsView struct {
+	WorkspaceMinimalView
+	Boards []BoardMinimalView `json:"boards"`
+}
+
+type WorkspaceMemberView struct {
+	ID   string              `json:"id"`
+	Role workspaceTypes.Role `json:"role" ts_type:"WorkspaceRole"`
+	User UserMinimalView     `json:"user"`
+}
+
+// Get User Workspaces
+type GetUserWorkspacesPayload struct {
+	UserID string `json:"userId"`
+}
+
+type GetUserWorkspacesResponse struct {
+	Response
+	Workspaces []UserWorkspaceView `json:"data"`
+}
+
+// Create Workspace
+type CreateWorkspacePayload struct {
+	Name string `json:"name"`
+}
+
+type CreateWorkspaceResponse struct {
+	Response
+	Workspace WorkspaceMinimalView `json:"data"`
+}
+
+// Update Workspace
+type UpdateWorkspacePayload struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UpdateWorkspaceResponse struct {
+	Response
+	Workspace WorkspaceMinimalView `json:"data"`
+}
+
+// Delete Workspace, which must not have any boards
+type DeleteWorkspacePayload struct {
+	ID string `json:"id"`
+}
+
+type DeleteWorkspaceResponse struct {
+	Response
+}
+
+// Get Workspace Members
+type GetWorkspaceMembersPayload struct {
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type GetWorkspaceMembersResponse struct {
+	Response
+	Members []WorkspaceMemberView `json:"data"`
+}
+
+// Create Workspace Member
+type CreateWorkspaceMemberPayload struct {
+	WorkspaceID string              `json:"workspaceId"`
+	Email       string              `json:"email"`
+	Role        workspaceTypes.Role `json:"role" ts_type:"WorkspaceRole"`
+}
+
+type CreateWorkspaceMemberResponse struct {
+	Response
+	Member WorkspaceMemberView `json:"data"`
+}
+
+// Update Workspace Member
+type UpdateWorkspaceMemberPayload struct {
+	ID          string              `json:"id"`
+	WorkspaceID string              `json:"workspaceId"`
+	Role        workspaceTypes.Role `json:"role" ts_type:"WorkspaceRole"`
+}
+
+type UpdateWorkspaceMemberResponse struct {
+	Response
+	Member WorkspaceMemberView `json:"data"`
+}
+
+// Delete Workspace Member
+type DeleteWorkspaceMemberPayload struct {
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type DeleteWorkspaceMemberResponse struct {
+	Response
+}
+
+// Leave Workspace
+type LeaveWorkspacePayload struct {
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type LeaveWorkspaceResponse struct {
+	Response
+}
+
+// Move Board, into another workspace that the user is a member of
+type MoveBoardPayload struct {
+	BoardID     string `json:"boardId"`
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type MoveBoardResponse struct {
+	Response
+	Board BoardMinimalView `json:"data"`
+}